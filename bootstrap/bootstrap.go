@@ -27,7 +27,7 @@ func Initialize() error {
 		return fmt.Errorf("bootstrap: failed to initialize logger: %w", err)
 	}
 
-	if err := database.InitMySQL(); err != nil {
+	if err := database.Init(); err != nil {
 		fmt.Fprintf(os.Stderr, "ERROR: bootstrap: database initialization failed, error=%v\n", err)
 		return fmt.Errorf("bootstrap: failed to initialize database: %w", err)
 	}
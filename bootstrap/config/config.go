@@ -5,17 +5,25 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/bytedance/sonic"
 )
 
 type (
+	// DatabaseConfig selects the database. Driver is "mysql" (the default), "postgres" or "sqlite";
+	// SQLite only reads Path, a file path or ":memory:", and only PostgreSQL reads SSLMode.
+	// AutoMigrate applies pending schema migrations at startup.
 	DatabaseConfig struct {
-		User     string `json:"user"`
-		Password string `json:"password"`
-		Host     string `json:"host"`
-		Port     string `json:"port"`
-		Database string `json:"database"`
+		Driver      string `json:"driver"`
+		Path        string `json:"path"`
+		User        string `json:"user"`
+		Password    string `json:"password"`
+		Host        string `json:"host"`
+		Port        string `json:"port"`
+		Database    string `json:"database"`
+		SSLMode     string `json:"ssl_mode"`
+		AutoMigrate bool   `json:"auto_migrate"`
 	}
 
 	LogConfig struct {
@@ -23,9 +31,63 @@ type (
 		Directory string `json:"directory"`
 	}
 
+	// PurgeConfig controls the background purge of long-deleted entities and expired policies.
+	// Durations use Go syntax such as "24h" or "720h".
+	PurgeConfig struct {
+		Enabled         bool   `json:"enabled"`
+		Interval        string `json:"interval"`
+		Retention       string `json:"retention"`
+		PolicyRetention string `json:"policy_retention"`
+		DryRun          bool   `json:"dry_run"`
+	}
+
+	// WatcherConfig keeps the policies of several replicas in sync. Driver is "" to run a
+	// single replica, or "database" to poll a policy version row every Interval.
+	WatcherConfig struct {
+		Driver   string `json:"driver"`
+		Interval string `json:"interval"`
+	}
+
+	// PolicyConfig controls how the in-memory policies are loaded and refreshed from the database.
+	// ReloadInterval is "0s" to never reload on a schedule. LoadMode is "full" to load every rule
+	// at startup, or "tenant" to load the rules of a tenant on first use and keep at most
+	// MaxTenants tenants in memory, 0 meaning no limit. Tenants in use by a call are never
+	// evicted, so the limit may be exceeded while they are.
+	PolicyConfig struct {
+		ReloadInterval string `json:"reload_interval"`
+		LoadMode       string `json:"load_mode"`
+		MaxTenants     int    `json:"max_tenants"`
+	}
+
+	// DecisionCacheConfig caches Enforce results until the next policy change. Size caps the
+	// number of cached decisions and is 0 to disable the cache; requests within the same
+	// Bucket, such as "1m", share a decision unless a policy window opens or closes within it.
+	DecisionCacheConfig struct {
+		Size   int    `json:"size"`
+		Bucket string `json:"bucket"`
+	}
+
+	// DecisionLogConfig records authorization decisions for compliance. Destination is "" to
+	// record none, "file" to append JSON lines to decision.log in the log directory, "database"
+	// to insert rows into tbl_decision_log, or "both". SampleRate is the fraction of decisions
+	// recorded, from 0 to 1, and ObjectSampleRates overrides it for the object codes it lists.
+	// AlwaysLogDenies records every denial whatever the sample rate.
+	DecisionLogConfig struct {
+		Destination       string             `json:"destination"`
+		SampleRate        float64            `json:"sample_rate"`
+		ObjectSampleRates map[string]float64 `json:"object_sample_rates"`
+		AlwaysLogDenies   bool               `json:"always_log_denies"`
+	}
+
 	AppConfig struct {
 		Database DatabaseConfig `json:"database"`
 		Log      LogConfig      `json:"log"`
+		Purge    PurgeConfig    `json:"purge"`
+		Watcher  WatcherConfig  `json:"watcher"`
+		Policy   PolicyConfig   `json:"policy"`
+
+		DecisionCache DecisionCacheConfig `json:"decision_cache"`
+		DecisionLog   DecisionLogConfig   `json:"decision_log"`
 	}
 )
 
@@ -97,12 +159,44 @@ func InitConfigWithPath(configPath string) error {
 		return fmt.Errorf("unmarshal config failed: %w", err)
 	}
 
+	if newCfg.Database.Driver == "" {
+		newCfg.Database.Driver = "mysql"
+	}
+	if newCfg.Database.Driver == "postgres" && newCfg.Database.SSLMode == "" {
+		newCfg.Database.SSLMode = "disable"
+	}
+	if newCfg.Database.Driver == "sqlite" && newCfg.Database.Path == ":memory:" {
+		// An in-memory database starts empty on every run
+		newCfg.Database.AutoMigrate = true
+	}
 	if newCfg.Log.Level == "" {
 		newCfg.Log.Level = "info"
 	}
 	if newCfg.Log.Directory == "" {
 		newCfg.Log.Directory = "log"
 	}
+	if newCfg.Purge.Interval == "" {
+		newCfg.Purge.Interval = "24h"
+	}
+	if newCfg.Purge.Retention == "" {
+		newCfg.Purge.Retention = "720h"
+	}
+	if newCfg.Purge.PolicyRetention == "" {
+		newCfg.Purge.PolicyRetention = "0s"
+	}
+
+	if newCfg.Watcher.Interval == "" {
+		newCfg.Watcher.Interval = "5s"
+	}
+	if newCfg.Policy.ReloadInterval == "" {
+		newCfg.Policy.ReloadInterval = "0s"
+	}
+	if newCfg.Policy.LoadMode == "" {
+		newCfg.Policy.LoadMode = "full"
+	}
+	if newCfg.DecisionCache.Bucket == "" {
+		newCfg.DecisionCache.Bucket = "1m"
+	}
 
 	if err := newCfg.Validate(); err != nil {
 		return err
@@ -113,11 +207,20 @@ func InitConfigWithPath(configPath string) error {
 }
 
 func (c AppConfig) Validate() error {
-	if c.Database.User == "" || c.Database.Host == "" || c.Database.Port == "" || c.Database.Database == "" {
-		return fmt.Errorf("invalid database config")
-	}
-	if _, err := strconv.Atoi(c.Database.Port); err != nil {
-		return fmt.Errorf("invalid database port")
+	switch c.Database.Driver {
+	case "mysql", "postgres":
+		if c.Database.User == "" || c.Database.Host == "" || c.Database.Port == "" || c.Database.Database == "" {
+			return fmt.Errorf("invalid database config")
+		}
+		if _, err := strconv.Atoi(c.Database.Port); err != nil {
+			return fmt.Errorf("invalid database port")
+		}
+	case "sqlite":
+		if c.Database.Path == "" {
+			return fmt.Errorf("invalid database path")
+		}
+	default:
+		return fmt.Errorf("invalid database driver")
 	}
 	switch c.Log.Level {
 	case "debug", "info", "warn", "error":
@@ -127,5 +230,52 @@ func (c AppConfig) Validate() error {
 	if c.Log.Directory == "" {
 		return fmt.Errorf("invalid log directory")
 	}
+	if d, err := time.ParseDuration(c.Purge.Interval); err != nil || d <= 0 {
+		return fmt.Errorf("invalid purge interval")
+	}
+	if d, err := time.ParseDuration(c.Purge.Retention); err != nil || d < 0 {
+		return fmt.Errorf("invalid purge retention")
+	}
+	if d, err := time.ParseDuration(c.Purge.PolicyRetention); err != nil || d < 0 {
+		return fmt.Errorf("invalid purge policy retention")
+	}
+	switch c.Watcher.Driver {
+	case "", "database":
+	default:
+		return fmt.Errorf("invalid watcher driver")
+	}
+	if d, err := time.ParseDuration(c.Watcher.Interval); err != nil || d <= 0 {
+		return fmt.Errorf("invalid watcher interval")
+	}
+	if d, err := time.ParseDuration(c.Policy.ReloadInterval); err != nil || d < 0 {
+		return fmt.Errorf("invalid policy reload interval")
+	}
+	switch c.Policy.LoadMode {
+	case "full", "tenant":
+	default:
+		return fmt.Errorf("invalid policy load mode")
+	}
+	if c.Policy.MaxTenants < 0 {
+		return fmt.Errorf("invalid policy max tenants")
+	}
+	if c.DecisionCache.Size < 0 {
+		return fmt.Errorf("invalid decision cache size")
+	}
+	if d, err := time.ParseDuration(c.DecisionCache.Bucket); err != nil || d <= 0 {
+		return fmt.Errorf("invalid decision cache bucket")
+	}
+	switch c.DecisionLog.Destination {
+	case "", "file", "database", "both":
+	default:
+		return fmt.Errorf("invalid decision log destination")
+	}
+	if c.DecisionLog.SampleRate < 0 || c.DecisionLog.SampleRate > 1 {
+		return fmt.Errorf("invalid decision log sample rate")
+	}
+	for objectCode, rate := range c.DecisionLog.ObjectSampleRates {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("invalid decision log sample rate for object %s", objectCode)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"ac/bootstrap/config"
+	"ac/bootstrap/logger"
+
+	//"gorm.io/gen"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// Supported database drivers
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+var (
+	DB      *gorm.DB
+	once    sync.Once
+	initErr error
+)
+
+// Init initializes a global Gorm connection for the configured driver and verifies connectivity.
+func Init() error {
+	once.Do(func() {
+		cfg := config.Config().Database
+		fmt.Fprintf(os.Stdout, "INFO: database: init: started, driver=%s\n", cfg.Driver)
+
+		var dialector gorm.Dialector
+		switch cfg.Driver {
+		case DriverPostgres:
+			dialector = postgresDialector(cfg)
+		case DriverSQLite:
+			dialector = sqliteDialector(cfg)
+		default:
+			dialector = mysqlDialector(cfg)
+		}
+
+		db, err := gorm.Open(dialector, &gorm.Config{
+			Logger: logger.NewGormLogger(gormlogger.Info),
+		})
+		if err != nil {
+			initErr = fmt.Errorf("failed to connect to %s database: %w", cfg.Driver, err)
+			fmt.Fprintf(os.Stderr, "ERROR: database: connect: failed, reason=connect, error=%v\n", err)
+			return
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			initErr = fmt.Errorf("failed to get sql.DB from gorm.DB: %w", err)
+			fmt.Fprintf(os.Stderr, "ERROR: database: get db: failed, reason=get db, error=%v\n", err)
+			return
+		}
+
+		var pingErr error
+		for i := 0; i < 3; i++ {
+			pingErr = sqlDB.Ping()
+			if pingErr == nil {
+				break
+			}
+			fmt.Fprintf(os.Stderr, "WARN: database: ping: retry=%d, error=%v\n", i+1, pingErr)
+			time.Sleep([]time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second}[i])
+		}
+		if pingErr != nil {
+			initErr = fmt.Errorf("ping to %s failed: %w", cfg.Driver, pingErr)
+			fmt.Fprintf(os.Stderr, "ERROR: database: ping: failed, reason=ping, error=%v\n", pingErr)
+			return
+		}
+
+		DB = db
+		if cfg.Driver == DriverSQLite {
+			fmt.Fprintf(os.Stdout, "INFO: database: init: succeeded, driver=%s, path=%s\n", cfg.Driver, cfg.Path)
+			return
+		}
+		fmt.Fprintf(
+			os.Stdout,
+			"INFO: database: init: succeeded, driver=%s, host=%s, port=%s, db=%s\n",
+			cfg.Driver,
+			cfg.Host,
+			cfg.Port,
+			cfg.Database,
+		)
+	})
+
+	//g := gen.NewGenerator(gen.Config{
+	//	ModelPkgPath:      "./model",
+	//	FieldWithIndexTag: true,
+	//	FieldWithTypeTag:  true,
+	//})
+	//dataMap := map[string]func(gorm.ColumnType) (dataType string){
+	//	"int": func(columnType gorm.ColumnType) (dataType string) {
+	//		return "int64"
+	//	},
+	//	"tinyint": func(columnType gorm.ColumnType) (dataType string) {
+	//		return "int64"
+	//	},
+	//}
+	//g.WithDataTypeMap(dataMap)
+	//g.UseDB(DB)
+	//g.GenerateAllTable()
+	//g.Execute()
+
+	return initErr
+}
+
+// Concat returns a SQL expression concatenating exprs, since SQLite has no CONCAT function.
+// Pass constants through Literal rather than as parameters: PostgreSQL cannot infer the type
+// of a parameter passed to CONCAT.
+func Concat(db *gorm.DB, exprs ...string) string {
+	if db.Dialector.Name() == DriverSQLite {
+		return "(" + strings.Join(exprs, " || ") + ")"
+	}
+	return "CONCAT(" + strings.Join(exprs, ", ") + ")"
+}
+
+// Literal quotes s as a SQL string literal understood by every supported driver.
+func Literal(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
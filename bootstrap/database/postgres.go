@@ -0,0 +1,24 @@
+package database
+
+import (
+	"fmt"
+
+	"ac/bootstrap/config"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// postgresDialector opens the configured PostgreSQL server.
+func postgresDialector(cfg config.DatabaseConfig) gorm.Dialector {
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=UTC",
+		cfg.Host,
+		cfg.Port,
+		cfg.User,
+		cfg.Password,
+		cfg.Database,
+		cfg.SSLMode,
+	)
+	return postgres.Open(dsn)
+}
@@ -0,0 +1,20 @@
+package database
+
+import (
+	"ac/bootstrap/config"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// SQLiteMemory is the SQLite path that keeps the database in memory for the life of the process.
+const SQLiteMemory = ":memory:"
+
+// sqliteDialector opens the configured SQLite file, or an in-memory database shared by
+// every pooled connection. File databases wait for locks instead of failing right away.
+func sqliteDialector(cfg config.DatabaseConfig) gorm.Dialector {
+	if cfg.Path == SQLiteMemory {
+		return sqlite.Open("file::memory:?cache=shared")
+	}
+	return sqlite.Open(cfg.Path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+}
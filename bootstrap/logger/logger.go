@@ -20,6 +20,7 @@ type Config struct {
 
 var (
 	sugaredLogger *zap.SugaredLogger
+	logDirectory  string
 	once          sync.Once
 	initErr       error
 )
@@ -36,7 +37,7 @@ func InitLogger(config Config) error {
 			return
 		}
 
-		logDirectory := filepath.Join(workingDir, config.Directory)
+		logDirectory = filepath.Join(workingDir, config.Directory)
 		if err := os.MkdirAll(logDirectory, 0o755); err != nil {
 			initErr = fmt.Errorf("failed to create log directory '%s': %w", logDirectory, err)
 			fmt.Fprintf(os.Stderr, "ERROR: logger: init: failed, reason=create log dir, path=%s, error=%v\n", logDirectory, initErr)
@@ -133,6 +134,20 @@ func InitLogger(config Config) error {
 	return initErr
 }
 
+// OpenFile opens a dedicated log file in the log directory for appending, creating it if needed.
+// Call after InitLogger.
+func OpenFile(name string) (*os.File, error) {
+	if logDirectory == "" {
+		return nil, fmt.Errorf("logger not initialized")
+	}
+	path := filepath.Join(logDirectory, name)
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file '%s': %w", path, err)
+	}
+	return file, nil
+}
+
 // LogWith writes a log entry with the given level, message, and key-values.
 func LogWith(ctx context.Context, level zapcore.Level, msg string, kv map[string]any) {
 	baseKV := getBaseKV(ctx)
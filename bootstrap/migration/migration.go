@@ -0,0 +1,253 @@
+// Package migration versions the database schema with SQL scripts embedded in the binary.
+// Each driver has its own directory of scripts named <version>_<name>.up.sql and
+// <version>_<name>.down.sql; applied versions are recorded in the schema_migrations table.
+package migration
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed mysql postgres sqlite
+var scripts embed.FS
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version int64
+	Name    string
+
+	up   string
+	down string
+}
+
+// Status reports whether a migration has been applied.
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time // nil while pending
+}
+
+// schemaMigration is a row of the schema_migrations table.
+type schemaMigration struct {
+	Version   int64     `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"column:name;type:varchar(100);not null"`
+	AppliedAt time.Time `gorm:"column:applied_at;not null"`
+}
+
+func (*schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// adoptionMarkers names a table each early version creates. Databases set up by hand from the
+// scripts that predate migrations have these tables but no schema_migrations rows.
+var adoptionMarkers = []string{"tbl_subject", "tbl_tenant", "tbl_casbin_rule_archive"}
+
+// Up applies every pending migration in version order and returns the ones applied.
+// Each migration runs in a transaction, though MySQL commits DDL statements implicitly.
+func Up(db *gorm.DB) ([]Migration, error) {
+	migrations, applied, err := prepare(db)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make([]Migration, 0)
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := execute(tx, migration.up); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: migration: up: failed, version=%d, name=%s, error=%v\n", migration.Version, migration.Name, err)
+			return done, fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		fmt.Fprintf(os.Stdout, "INFO: migration: up: applied, version=%d, name=%s\n", migration.Version, migration.Name)
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down reverts the latest steps applied migrations, newest first, and returns the ones reverted.
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be at least 1")
+	}
+
+	migrations, applied, err := prepare(db)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make([]Migration, 0, steps)
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := execute(tx, migration.down); err != nil {
+				return err
+			}
+			return tx.Where("version = ?", migration.Version).Delete(&schemaMigration{}).Error
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: migration: down: failed, version=%d, name=%s, error=%v\n", migration.Version, migration.Name, err)
+			return done, fmt.Errorf("failed to revert migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		fmt.Fprintf(os.Stdout, "INFO: migration: down: reverted, version=%d, name=%s\n", migration.Version, migration.Name)
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// List reports every known migration in version order along with when it was applied.
+func List(db *gorm.DB) ([]Status, error) {
+	migrations, applied, err := prepare(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(migrations))
+	for i, migration := range migrations {
+		statuses[i] = Status{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			statuses[i].AppliedAt = &appliedAt
+		}
+	}
+	return statuses, nil
+}
+
+// prepare loads the migrations of the driver behind db, creates schema_migrations if needed
+// and returns when each applied version was applied.
+func prepare(db *gorm.DB) ([]Migration, map[int64]time.Time, error) {
+	migrations, err := load(db.Dialector.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		if err := db.Migrator().CreateTable(&schemaMigration{}); err != nil {
+			return nil, nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+	}
+
+	var rows []schemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+	if len(rows) == 0 {
+		if rows, err = adopt(db, migrations); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	applied := make(map[int64]time.Time, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row.AppliedAt
+	}
+	return migrations, applied, nil
+}
+
+// adopt records as applied the leading migrations whose tables already exist, so a database
+// set up before migrations existed is upgraded rather than created again.
+func adopt(db *gorm.DB, migrations []Migration) ([]schemaMigration, error) {
+	rows := make([]schemaMigration, 0)
+	for i, marker := range adoptionMarkers {
+		if i >= len(migrations) || !db.Migrator().HasTable(marker) {
+			break
+		}
+		rows = append(rows, schemaMigration{Version: migrations[i].Version, Name: migrations[i].Name, AppliedAt: time.Now()})
+	}
+	if len(rows) == 0 {
+		return rows, nil
+	}
+
+	if err := db.Create(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to adopt existing schema: %w", err)
+	}
+	fmt.Fprintf(os.Stdout, "INFO: migration: adopted existing schema, version=%d\n", rows[len(rows)-1].Version)
+	return rows, nil
+}
+
+// load reads the embedded migrations of a driver in version order.
+func load(driver string) ([]Migration, error) {
+	entries, err := fs.ReadDir(scripts, driver)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for driver %s: %w", driver, err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		base, direction, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+		version, err := strconv.ParseInt(versionStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+
+		content, err := scripts.ReadFile(path.Join(driver, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if direction == "up" {
+			migration.up = string(content)
+		} else {
+			migration.down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.up == "" || migration.down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// execute runs a script one statement at a time, since not every driver accepts several
+// statements in one call. Statements end with a semicolon at the end of a line.
+func execute(tx *gorm.DB, script string) error {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(script, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			lines = append(lines, line)
+		}
+	}
+
+	for _, statement := range strings.Split(strings.Join(lines, "\n"), ";\n") {
+		statement = strings.TrimSuffix(strings.TrimSpace(statement), ";")
+		if statement == "" {
+			continue
+		}
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+DROP TABLE `tbl_casbin_rule`;
+DROP TABLE `tbl_object`;
+DROP TABLE `tbl_subject`;
@@ -1,4 +1,5 @@
-DROP TABLE IF EXISTS `tbl_subject`;
+-- Users, roles, objects and casbin rules, as shipped in v1.0.0.
+
 CREATE TABLE `tbl_subject`
 (
     `id`          INT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'id',
@@ -18,7 +19,6 @@ CREATE TABLE `tbl_subject`
     KEY           `idx_subject_type_deleted_status` (`type`, `deleted`, `status`)
 ) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
 
-DROP TABLE IF EXISTS `tbl_object`;
 CREATE TABLE `tbl_object`
 (
     `id`          INT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'id',
@@ -38,7 +38,6 @@ CREATE TABLE `tbl_object`
     KEY           `idx_object_type_deleted_status` (`type`, `deleted`, `status`)
 ) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT 'tbl_object';
 
-DROP TABLE IF EXISTS `tbl_casbin_rule`;
 CREATE TABLE `tbl_casbin_rule`
 (
     `id`    INT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'id',
@@ -53,4 +52,4 @@ CREATE TABLE `tbl_casbin_rule`
     UNIQUE KEY `uk_casbin_policy` (`ptype`, `v0`, `v1`, `v2`, `v3`, `v4`, `v5`),
     KEY     `idx_casbin_ptype_v0` (`ptype`, `v0`),
     KEY     `idx_casbin_ptype_v1` (`ptype`, `v1`)
-) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT 'tbl_casbin_rule';
\ No newline at end of file
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT 'tbl_casbin_rule';
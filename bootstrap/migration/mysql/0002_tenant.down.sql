@@ -0,0 +1,9 @@
+ALTER TABLE `tbl_object`
+    DROP KEY `idx_object_tenant_deleted`,
+    DROP COLUMN `tenant_code`;
+
+ALTER TABLE `tbl_subject`
+    DROP KEY `idx_subject_tenant_deleted`,
+    DROP COLUMN `tenant_code`;
+
+DROP TABLE `tbl_tenant`;
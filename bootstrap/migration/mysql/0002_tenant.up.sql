@@ -0,0 +1,27 @@
+-- Multi-tenant domains, as shipped in v1.1.0.
+-- Existing users, roles and objects are moved into the default tenant.
+
+CREATE TABLE `tbl_tenant`
+(
+    `id`         INT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'id',
+    `code`       VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'code',
+    `name`       VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'name',
+    `status`     INT          NOT NULL DEFAULT 0 COMMENT 'status',
+    `deleted`    INT          NOT NULL DEFAULT 0 COMMENT 'deleted',
+    `created_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created_at',
+    `updated_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated_at',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_tenant_code` (`code`),
+    KEY          `idx_tenant_deleted_status` (`deleted`, `status`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT 'tbl_tenant';
+
+INSERT INTO `tbl_tenant` (`code`, `name`, `status`, `deleted`)
+VALUES ('00000000-0000-0000-0000-000000000000', 'default', 1, 0);
+
+ALTER TABLE `tbl_subject`
+    ADD COLUMN `tenant_code` VARCHAR(100) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' COMMENT 'tenant_code' AFTER `code`,
+    ADD KEY `idx_subject_tenant_deleted` (`tenant_code`, `deleted`);
+
+ALTER TABLE `tbl_object`
+    ADD COLUMN `tenant_code` VARCHAR(100) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000' COMMENT 'tenant_code' AFTER `code`,
+    ADD KEY `idx_object_tenant_deleted` (`tenant_code`, `deleted`);
@@ -0,0 +1,10 @@
+DROP TABLE `tbl_casbin_rule_archive`;
+
+ALTER TABLE `tbl_casbin_rule`
+    MODIFY COLUMN `v4` VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'v4';
+
+ALTER TABLE `tbl_object`
+    DROP COLUMN `attributes`;
+
+ALTER TABLE `tbl_subject`
+    DROP COLUMN `attributes`;
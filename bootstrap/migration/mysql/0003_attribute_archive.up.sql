@@ -0,0 +1,29 @@
+-- Attribute-based conditions on permissions and restorable deletes, as shipped in v1.2.0.
+-- Users and objects carry JSON attributes that permission conditions read,
+-- and v4 of p rules holds the condition expression.
+
+ALTER TABLE `tbl_subject`
+    ADD COLUMN `attributes` VARCHAR(2000) NOT NULL DEFAULT '{}' COMMENT 'attributes' AFTER `sort`;
+
+ALTER TABLE `tbl_object`
+    ADD COLUMN `attributes` VARCHAR(2000) NOT NULL DEFAULT '{}' COMMENT 'attributes' AFTER `sort`;
+
+ALTER TABLE `tbl_casbin_rule`
+    MODIFY COLUMN `v4` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'v4';
+
+-- Rules removed along with a deleted user, role or object, kept so a restore can re-create them.
+CREATE TABLE `tbl_casbin_rule_archive`
+(
+    `id`         INT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'id',
+    `entity`     VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'entity',
+    `ptype`      VARCHAR(10)  NOT NULL DEFAULT '' COMMENT 'ptype',
+    `v0`         VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'v0',
+    `v1`         VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'v1',
+    `v2`         VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'v2',
+    `v3`         VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'v3',
+    `v4`         VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'v4',
+    `v5`         VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'v5',
+    `created_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created_at',
+    PRIMARY KEY (`id`),
+    KEY          `idx_casbin_rule_archive_entity` (`entity`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT 'tbl_casbin_rule_archive';
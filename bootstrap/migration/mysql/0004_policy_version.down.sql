@@ -0,0 +1 @@
+DROP TABLE `tbl_policy_version`;
@@ -0,0 +1,12 @@
+-- Version of the stored policy, bumped on every change so replicas know to reload.
+
+CREATE TABLE `tbl_policy_version`
+(
+    `id`         INT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'id',
+    `version`    BIGINT       NOT NULL DEFAULT 0 COMMENT 'version',
+    `updated_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'updated_at',
+    PRIMARY KEY (`id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT 'tbl_policy_version';
+
+INSERT INTO `tbl_policy_version` (`id`, `version`)
+VALUES (1, 0);
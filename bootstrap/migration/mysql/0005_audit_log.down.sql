@@ -0,0 +1 @@
+DROP TABLE `tbl_audit_log`;
@@ -0,0 +1,19 @@
+-- Append-only record of every administrative change, written in the transaction of the change.
+
+CREATE TABLE `tbl_audit_log`
+(
+    `id`            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'id',
+    `tenant_code`   VARCHAR(36)  NOT NULL DEFAULT '' COMMENT 'tenant_code',
+    `actor`         VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'actor',
+    `action`        VARCHAR(50)  NOT NULL DEFAULT '' COMMENT 'action',
+    `resource_type` VARCHAR(20)  NOT NULL DEFAULT '' COMMENT 'resource_type',
+    `resource_code` VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'resource_code',
+    `old_value`     TEXT         NOT NULL COMMENT 'old_value',
+    `new_value`     TEXT         NOT NULL COMMENT 'new_value',
+    `request_id`    VARCHAR(64)  NOT NULL DEFAULT '' COMMENT 'request_id',
+    `created_at`    DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created_at',
+    PRIMARY KEY (`id`),
+    KEY             `idx_audit_log_tenant_created` (`tenant_code`, `created_at`),
+    KEY             `idx_audit_log_resource` (`resource_type`, `resource_code`),
+    KEY             `idx_audit_log_actor` (`actor`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT 'tbl_audit_log';
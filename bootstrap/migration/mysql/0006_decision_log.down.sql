@@ -0,0 +1 @@
+DROP TABLE `tbl_decision_log`;
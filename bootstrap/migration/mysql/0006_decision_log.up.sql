@@ -0,0 +1,19 @@
+-- Sampled authorization decisions, kept to show who was allowed or denied access and when.
+
+CREATE TABLE `tbl_decision_log`
+(
+    `id`           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'id',
+    `request_id`   VARCHAR(64)   NOT NULL DEFAULT '' COMMENT 'request_id',
+    `subject`      VARCHAR(100)  NOT NULL DEFAULT '' COMMENT 'subject',
+    `domain`       VARCHAR(100)  NOT NULL DEFAULT '' COMMENT 'domain',
+    `object`       VARCHAR(100)  NOT NULL DEFAULT '' COMMENT 'object',
+    `action`       VARCHAR(50)   NOT NULL DEFAULT '' COMMENT 'action',
+    `allowed`      TINYINT       NOT NULL DEFAULT 0 COMMENT 'allowed',
+    `matched_rule` VARCHAR(1000) NOT NULL DEFAULT '' COMMENT 'matched_rule',
+    `check_time`   DATETIME      NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'check_time',
+    `created_at`   DATETIME      NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created_at',
+    PRIMARY KEY (`id`),
+    KEY            `idx_decision_log_object_created` (`object`, `created_at`),
+    KEY            `idx_decision_log_subject_created` (`subject`, `created_at`),
+    KEY            `idx_decision_log_request` (`request_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT 'tbl_decision_log';
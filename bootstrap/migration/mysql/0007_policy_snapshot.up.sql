@@ -0,0 +1,27 @@
+-- Labelled copies of tbl_casbin_rule that policies can be compared with and rolled back to.
+
+CREATE TABLE `tbl_policy_snapshot`
+(
+    `id`         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'id',
+    `label`      VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'label',
+    `rule_count` INT          NOT NULL DEFAULT 0 COMMENT 'rule_count',
+    `created_by` VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'created_by',
+    `created_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'created_at',
+    PRIMARY KEY (`id`),
+    KEY          `idx_policy_snapshot_created` (`created_at`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT 'tbl_policy_snapshot';
+
+CREATE TABLE `tbl_policy_snapshot_rule`
+(
+    `id`          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'id',
+    `snapshot_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'snapshot_id',
+    `ptype`       VARCHAR(10)  NOT NULL DEFAULT '' COMMENT 'ptype',
+    `v0`          VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'v0',
+    `v1`          VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'v1',
+    `v2`          VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'v2',
+    `v3`          VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'v3',
+    `v4`          VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'v4',
+    `v5`          VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'v5',
+    PRIMARY KEY (`id`),
+    KEY           `idx_policy_snapshot_rule_snapshot` (`snapshot_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci COMMENT 'tbl_policy_snapshot_rule';
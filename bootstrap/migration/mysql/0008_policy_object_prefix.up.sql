@@ -0,0 +1,21 @@
+-- Policies granted before objects were prefixed store the bare object code in v1,
+-- which the matcher no longer resolves. Prefix them the way new policies are stored.
+-- Prefixed values all contain a colon, while bare codes never do.
+
+UPDATE `tbl_casbin_rule`
+SET `v1` = CONCAT('o:', `v1`)
+WHERE `ptype` = 'p'
+  AND `v1` <> ''
+  AND `v1` NOT LIKE '%:%';
+
+UPDATE `tbl_casbin_rule_archive`
+SET `v1` = CONCAT('o:', `v1`)
+WHERE `ptype` = 'p'
+  AND `v1` <> ''
+  AND `v1` NOT LIKE '%:%';
+
+UPDATE `tbl_policy_snapshot_rule`
+SET `v1` = CONCAT('o:', `v1`)
+WHERE `ptype` = 'p'
+  AND `v1` <> ''
+  AND `v1` NOT LIKE '%:%';
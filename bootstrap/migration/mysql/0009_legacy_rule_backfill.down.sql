@@ -0,0 +1 @@
+-- Backfilled rules cannot be told apart from rules written since, so they are kept.
@@ -0,0 +1,43 @@
+-- Rules written before effects and tenants existed, moved into the current model.
+-- Archived and snapshot rules are restored as they are stored, so they move too.
+-- Policies without an effect were all allow policies.
+
+UPDATE `tbl_casbin_rule`
+SET `v5` = 'allow'
+WHERE `ptype` = 'p'
+  AND `v5` = '';
+
+UPDATE `tbl_casbin_rule_archive`
+SET `v5` = 'allow'
+WHERE `ptype` = 'p'
+  AND `v5` = '';
+
+UPDATE `tbl_policy_snapshot_rule`
+SET `v5` = 'allow'
+WHERE `ptype` = 'p'
+  AND `v5` = '';
+
+-- Groupings without a domain join the default tenant.
+
+UPDATE `tbl_casbin_rule`
+SET `v2` = 't:00000000-0000-0000-0000-000000000000'
+WHERE `ptype` = 'g'
+  AND `v2` = '';
+
+UPDATE `tbl_casbin_rule_archive`
+SET `v2` = 't:00000000-0000-0000-0000-000000000000'
+WHERE `ptype` = 'g'
+  AND `v2` = '';
+
+UPDATE `tbl_policy_snapshot_rule`
+SET `v2` = 't:00000000-0000-0000-0000-000000000000'
+WHERE `ptype` = 'g'
+  AND `v2` = '';
+
+-- Root objects without an object group are linked to the root of their tenant so they keep matching.
+INSERT INTO `tbl_casbin_rule` (`ptype`, `v0`, `v1`)
+SELECT 'g2', CONCAT('o:', o.`code`), CONCAT('t:', COALESCE(NULLIF(o.`tenant_code`, ''), '00000000-0000-0000-0000-000000000000'))
+FROM `tbl_object` o
+WHERE o.`parent_code` = ''
+  AND o.`deleted` = 0
+  AND NOT EXISTS (SELECT 1 FROM `tbl_casbin_rule` r WHERE r.`ptype` = 'g2' AND r.`v0` = CONCAT('o:', o.`code`));
@@ -0,0 +1 @@
+-- Backfilled windows are the layout the service reads and cannot be told apart from windows written since, so they are kept.
@@ -0,0 +1,55 @@
+-- Policy windows moved to the layout that frees v4 for conditions.
+-- Archived and snapshot rules are restored as they are stored, so they move too.
+-- Policies written before recurrences existed kept the begin time in v3 and the end time in v4.
+-- MySQL assigns left to right, so v3 is built before v4 is cleared.
+
+UPDATE `tbl_casbin_rule`
+SET `v3` = CONCAT(`v3`, '/', `v4`),
+    `v4` = ''
+WHERE `ptype` = 'p'
+  AND `v3` <> ''
+  AND `v3` NOT LIKE '%/%';
+
+UPDATE `tbl_casbin_rule_archive`
+SET `v3` = CONCAT(`v3`, '/', `v4`),
+    `v4` = ''
+WHERE `ptype` = 'p'
+  AND `v3` <> ''
+  AND `v3` NOT LIKE '%/%';
+
+UPDATE `tbl_policy_snapshot_rule`
+SET `v3` = CONCAT(`v3`, '/', `v4`),
+    `v4` = ''
+WHERE `ptype` = 'p'
+  AND `v3` <> ''
+  AND `v3` NOT LIKE '%/%';
+
+-- Policies written before conditions existed kept the recurrence, such as MON-FRI 09:00-18:00, in v4.
+-- Conditions always read an attribute through a dot, which recurrences never contain.
+
+UPDATE `tbl_casbin_rule`
+SET `v3` = CONCAT(`v3`, ' ', TRIM(`v4`)),
+    `v4` = ''
+WHERE `ptype` = 'p'
+  AND `v3` LIKE '%/%'
+  AND `v3` NOT LIKE '% %'
+  AND `v4` LIKE '%:%-%:%'
+  AND `v4` NOT LIKE '%.%';
+
+UPDATE `tbl_casbin_rule_archive`
+SET `v3` = CONCAT(`v3`, ' ', TRIM(`v4`)),
+    `v4` = ''
+WHERE `ptype` = 'p'
+  AND `v3` LIKE '%/%'
+  AND `v3` NOT LIKE '% %'
+  AND `v4` LIKE '%:%-%:%'
+  AND `v4` NOT LIKE '%.%';
+
+UPDATE `tbl_policy_snapshot_rule`
+SET `v3` = CONCAT(`v3`, ' ', TRIM(`v4`)),
+    `v4` = ''
+WHERE `ptype` = 'p'
+  AND `v3` LIKE '%/%'
+  AND `v3` NOT LIKE '% %'
+  AND `v4` LIKE '%:%-%:%'
+  AND `v4` NOT LIKE '%.%';
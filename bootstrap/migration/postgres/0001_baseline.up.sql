@@ -0,0 +1,55 @@
+-- Users, roles, objects and casbin rules, as shipped in v1.0.0.
+
+CREATE TABLE tbl_subject
+(
+    id          BIGSERIAL    NOT NULL,
+    type        INT          NOT NULL DEFAULT 0,
+    code        VARCHAR(100) NOT NULL DEFAULT '',
+    name        VARCHAR(100) NOT NULL DEFAULT '',
+    parent_code VARCHAR(100) NOT NULL DEFAULT '',
+    sort        INT          NOT NULL DEFAULT 0,
+    status      INT          NOT NULL DEFAULT 0,
+    deleted     INT          NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT uk_subject_code UNIQUE (code)
+);
+CREATE INDEX idx_subject_name ON tbl_subject (name);
+CREATE INDEX idx_subject_parent_deleted_status ON tbl_subject (parent_code, deleted, status);
+CREATE INDEX idx_subject_type_deleted_status ON tbl_subject (type, deleted, status);
+
+CREATE TABLE tbl_object
+(
+    id          BIGSERIAL    NOT NULL,
+    type        INT          NOT NULL DEFAULT 0,
+    code        VARCHAR(100) NOT NULL DEFAULT '',
+    name        VARCHAR(100) NOT NULL DEFAULT '',
+    parent_code VARCHAR(100) NOT NULL DEFAULT '',
+    sort        INT          NOT NULL DEFAULT 0,
+    status      INT          NOT NULL DEFAULT 0,
+    deleted     INT          NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT uk_object_code UNIQUE (code)
+);
+CREATE INDEX idx_object_name ON tbl_object (name);
+CREATE INDEX idx_object_parent_deleted_status ON tbl_object (parent_code, deleted, status);
+CREATE INDEX idx_object_type_deleted_status ON tbl_object (type, deleted, status);
+
+CREATE TABLE tbl_casbin_rule
+(
+    id    BIGSERIAL    NOT NULL,
+    ptype VARCHAR(10)  NOT NULL DEFAULT '',
+    v0    VARCHAR(100) NOT NULL DEFAULT '',
+    v1    VARCHAR(100) NOT NULL DEFAULT '',
+    v2    VARCHAR(100) NOT NULL DEFAULT '',
+    v3    VARCHAR(100) NOT NULL DEFAULT '',
+    v4    VARCHAR(100) NOT NULL DEFAULT '',
+    v5    VARCHAR(100) NOT NULL DEFAULT '',
+    PRIMARY KEY (id),
+    CONSTRAINT uk_casbin_policy UNIQUE (ptype, v0, v1, v2, v3, v4, v5)
+);
+CREATE INDEX idx_casbin_ptype_v0 ON tbl_casbin_rule (ptype, v0);
+CREATE INDEX idx_casbin_ptype_v1 ON tbl_casbin_rule (ptype, v1);
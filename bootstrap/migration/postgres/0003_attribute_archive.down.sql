@@ -0,0 +1,10 @@
+DROP TABLE tbl_casbin_rule_archive;
+
+ALTER TABLE tbl_casbin_rule
+    ALTER COLUMN v4 TYPE VARCHAR(100);
+
+ALTER TABLE tbl_object
+    DROP COLUMN attributes;
+
+ALTER TABLE tbl_subject
+    DROP COLUMN attributes;
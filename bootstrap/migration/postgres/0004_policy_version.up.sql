@@ -0,0 +1,12 @@
+-- Version of the stored policy, bumped on every change so replicas know to reload.
+
+CREATE TABLE tbl_policy_version
+(
+    id         BIGSERIAL   NOT NULL,
+    version    BIGINT      NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+INSERT INTO tbl_policy_version (id, version)
+VALUES (1, 0);
@@ -0,0 +1,19 @@
+-- Append-only record of every administrative change, written in the transaction of the change.
+
+CREATE TABLE tbl_audit_log
+(
+    id            BIGSERIAL    NOT NULL,
+    tenant_code   VARCHAR(36)  NOT NULL DEFAULT '',
+    actor         VARCHAR(100) NOT NULL DEFAULT '',
+    action        VARCHAR(50)  NOT NULL DEFAULT '',
+    resource_type VARCHAR(20)  NOT NULL DEFAULT '',
+    resource_code VARCHAR(100) NOT NULL DEFAULT '',
+    old_value     TEXT         NOT NULL DEFAULT '',
+    new_value     TEXT         NOT NULL DEFAULT '',
+    request_id    VARCHAR(64)  NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_audit_log_tenant_created ON tbl_audit_log (tenant_code, created_at);
+CREATE INDEX idx_audit_log_resource ON tbl_audit_log (resource_type, resource_code);
+CREATE INDEX idx_audit_log_actor ON tbl_audit_log (actor);
@@ -0,0 +1,3 @@
+DROP TABLE tbl_policy_snapshot_rule;
+
+DROP TABLE tbl_policy_snapshot;
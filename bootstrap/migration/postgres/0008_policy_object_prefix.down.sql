@@ -0,0 +1,2 @@
+-- Prefixed object codes are what the service reads, and migrated rows cannot be told apart
+-- from policies granted since, so the prefix is kept.
@@ -0,0 +1,9 @@
+DROP INDEX idx_object_tenant_deleted;
+ALTER TABLE tbl_object
+    DROP COLUMN tenant_code;
+
+DROP INDEX idx_subject_tenant_deleted;
+ALTER TABLE tbl_subject
+    DROP COLUMN tenant_code;
+
+DROP TABLE tbl_tenant;
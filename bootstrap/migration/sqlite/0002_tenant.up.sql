@@ -0,0 +1,27 @@
+-- Multi-tenant domains, as shipped in v1.1.0.
+-- Existing users, roles and objects are moved into the default tenant.
+
+CREATE TABLE tbl_tenant
+(
+    id         INTEGER      NOT NULL,
+    code       VARCHAR(100) NOT NULL DEFAULT '',
+    name       VARCHAR(100) NOT NULL DEFAULT '',
+    status     INT          NOT NULL DEFAULT 0,
+    deleted    INT          NOT NULL DEFAULT 0,
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id AUTOINCREMENT),
+    CONSTRAINT uk_tenant_code UNIQUE (code)
+);
+CREATE INDEX idx_tenant_deleted_status ON tbl_tenant (deleted, status);
+
+INSERT INTO tbl_tenant (code, name, status, deleted)
+VALUES ('00000000-0000-0000-0000-000000000000', 'default', 1, 0);
+
+ALTER TABLE tbl_subject
+    ADD COLUMN tenant_code VARCHAR(100) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+CREATE INDEX idx_subject_tenant_deleted ON tbl_subject (tenant_code, deleted);
+
+ALTER TABLE tbl_object
+    ADD COLUMN tenant_code VARCHAR(100) NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+CREATE INDEX idx_object_tenant_deleted ON tbl_object (tenant_code, deleted);
@@ -0,0 +1,7 @@
+DROP TABLE tbl_casbin_rule_archive;
+
+ALTER TABLE tbl_object
+    DROP COLUMN attributes;
+
+ALTER TABLE tbl_subject
+    DROP COLUMN attributes;
@@ -0,0 +1,28 @@
+-- Attribute-based conditions on permissions and restorable deletes, as shipped in v1.2.0.
+-- Users and objects carry JSON attributes that permission conditions read,
+-- and v4 of p rules holds the condition expression.
+
+ALTER TABLE tbl_subject
+    ADD COLUMN attributes VARCHAR(2000) NOT NULL DEFAULT '{}';
+
+ALTER TABLE tbl_object
+    ADD COLUMN attributes VARCHAR(2000) NOT NULL DEFAULT '{}';
+
+-- SQLite does not enforce VARCHAR lengths, so v4 needs no change to hold conditions.
+
+-- Rules removed along with a deleted user, role or object, kept so a restore can re-create them.
+CREATE TABLE tbl_casbin_rule_archive
+(
+    id         INTEGER      NOT NULL,
+    entity     VARCHAR(100) NOT NULL DEFAULT '',
+    ptype      VARCHAR(10)  NOT NULL DEFAULT '',
+    v0         VARCHAR(100) NOT NULL DEFAULT '',
+    v1         VARCHAR(100) NOT NULL DEFAULT '',
+    v2         VARCHAR(100) NOT NULL DEFAULT '',
+    v3         VARCHAR(100) NOT NULL DEFAULT '',
+    v4         VARCHAR(255) NOT NULL DEFAULT '',
+    v5         VARCHAR(100) NOT NULL DEFAULT '',
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_casbin_rule_archive_entity ON tbl_casbin_rule_archive (entity);
@@ -0,0 +1,12 @@
+-- Version of the stored policy, bumped on every change so replicas know to reload.
+
+CREATE TABLE tbl_policy_version
+(
+    id         INTEGER  NOT NULL,
+    version    BIGINT   NOT NULL DEFAULT 0,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id AUTOINCREMENT)
+);
+
+INSERT INTO tbl_policy_version (id, version)
+VALUES (1, 0);
@@ -0,0 +1,19 @@
+-- Sampled authorization decisions, kept to show who was allowed or denied access and when.
+
+CREATE TABLE tbl_decision_log
+(
+    id           INTEGER       NOT NULL,
+    request_id   VARCHAR(64)   NOT NULL DEFAULT '',
+    subject      VARCHAR(100)  NOT NULL DEFAULT '',
+    domain       VARCHAR(100)  NOT NULL DEFAULT '',
+    object       VARCHAR(100)  NOT NULL DEFAULT '',
+    action       VARCHAR(50)   NOT NULL DEFAULT '',
+    allowed      INTEGER       NOT NULL DEFAULT 0,
+    matched_rule VARCHAR(1000) NOT NULL DEFAULT '',
+    check_time   DATETIME      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at   DATETIME      NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_decision_log_object_created ON tbl_decision_log (object, created_at);
+CREATE INDEX idx_decision_log_subject_created ON tbl_decision_log (subject, created_at);
+CREATE INDEX idx_decision_log_request ON tbl_decision_log (request_id);
@@ -0,0 +1,27 @@
+-- Labelled copies of tbl_casbin_rule that policies can be compared with and rolled back to.
+
+CREATE TABLE tbl_policy_snapshot
+(
+    id         INTEGER      NOT NULL,
+    label      VARCHAR(100) NOT NULL DEFAULT '',
+    rule_count INT          NOT NULL DEFAULT 0,
+    created_by VARCHAR(100) NOT NULL DEFAULT '',
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_policy_snapshot_created ON tbl_policy_snapshot (created_at);
+
+CREATE TABLE tbl_policy_snapshot_rule
+(
+    id          INTEGER      NOT NULL,
+    snapshot_id BIGINT       NOT NULL DEFAULT 0,
+    ptype       VARCHAR(10)  NOT NULL DEFAULT '',
+    v0          VARCHAR(100) NOT NULL DEFAULT '',
+    v1          VARCHAR(100) NOT NULL DEFAULT '',
+    v2          VARCHAR(100) NOT NULL DEFAULT '',
+    v3          VARCHAR(100) NOT NULL DEFAULT '',
+    v4          VARCHAR(255) NOT NULL DEFAULT '',
+    v5          VARCHAR(100) NOT NULL DEFAULT '',
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_policy_snapshot_rule_snapshot ON tbl_policy_snapshot_rule (snapshot_id);
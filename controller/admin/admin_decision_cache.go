@@ -0,0 +1,29 @@
+package admin
+
+import (
+	"ac/controller"
+	"ac/service/casbin"
+
+	"github.com/gin-gonic/gin"
+)
+
+type adminDecisionCacheOutput struct {
+	Enabled bool  `json:"enabled"`
+	Entries int   `json:"entries"` // decisions currently cached
+	Hits    int64 `json:"hits"`    // checks answered from the cache since startup
+	Misses  int64 `json:"misses"`  // checks evaluated by the enforcer since startup
+}
+
+// @Summary Get decision cache statistics
+// @Tags admin
+// @Success 200 {object} controller.Response{data=adminDecisionCacheOutput} "output"
+// @Router /api/admin/decision-cache [get]
+func adminDecisionCache(ctx *gin.Context) {
+	stats := casbin.GetDecisionCacheStats()
+	controller.Success(ctx, adminDecisionCacheOutput{
+		Enabled: stats.Enabled,
+		Entries: stats.Entries,
+		Hits:    stats.Hits,
+		Misses:  stats.Misses,
+	})
+}
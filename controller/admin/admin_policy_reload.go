@@ -0,0 +1,30 @@
+package admin
+
+import (
+	"ac/controller"
+	"ac/service/casbin"
+
+	"github.com/gin-gonic/gin"
+)
+
+type adminPolicyReloadOutput struct {
+	RulesBefore int `json:"rules_before"` // rules held before the reload
+	RulesAfter  int `json:"rules_after"`  // rules held after the reload
+}
+
+// @Summary Reload policies from the database
+// @Tags admin
+// @Success 200 {object} controller.Response{data=adminPolicyReloadOutput} "output"
+// @Router /api/admin/policy/reload [post]
+func adminPolicyReload(ctx *gin.Context) {
+	before, after, err := casbin.ReloadPolicy(ctx)
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	controller.Success(ctx, adminPolicyReloadOutput{
+		RulesBefore: before,
+		RulesAfter:  after,
+	})
+}
@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"errors"
+
+	"ac/controller"
+	"ac/service/purge"
+
+	"github.com/gin-gonic/gin"
+)
+
+type adminPurgeInput struct {
+	DryRun bool `json:"dry_run"` // only count what would be purged
+}
+
+type adminPurgeOutput struct {
+	DryRun          bool  `json:"dry_run"`
+	Users           int64 `json:"users"`
+	Roles           int64 `json:"roles"`
+	Objects         int64 `json:"objects"`
+	Rules           int64 `json:"rules"`
+	ArchivedRules   int64 `json:"archived_rules"`
+	ExpiredPolicies int64 `json:"expired_policies"`
+}
+
+// @Summary Purge long-deleted entities and expired policies
+// @Tags admin
+// @Param input body adminPurgeInput true "input"
+// @Success 200 {object} controller.Response{data=adminPurgeOutput} "output"
+// @Router /api/admin/purge [post]
+func adminPurge(ctx *gin.Context) {
+	var input adminPurgeInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	result, err := purge.Run(ctx, input.DryRun)
+	if errors.Is(err, purge.ErrRunning) {
+		controller.Failure(ctx, controller.ErrSystemError.WithHint("purge already running"))
+		return
+	}
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	controller.Success(ctx, adminPurgeOutput{
+		DryRun:          result.DryRun,
+		Users:           result.Users,
+		Roles:           result.Roles,
+		Objects:         result.Objects,
+		Rules:           result.Rules,
+		ArchivedRules:   result.ArchivedRules,
+		ExpiredPolicies: result.ExpiredPolicies,
+	})
+}
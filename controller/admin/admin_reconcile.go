@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"ac/controller"
+	"ac/service/casbin"
+
+	"github.com/gin-gonic/gin"
+)
+
+type adminReconcileInput struct {
+	Repair bool `json:"repair"` // fix, archive or remove the broken rules instead of only reporting them
+}
+
+type adminReconcileOutput struct {
+	Repair   bool                 `json:"repair"`
+	Scanned  int64                `json:"scanned"`
+	Fixed    int64                `json:"fixed"`
+	Archived int64                `json:"archived"`
+	Deleted  int64                `json:"deleted"`
+	Issues   []adminReconcileItem `json:"issues"`
+}
+
+type adminReconcileItem struct {
+	RuleId int64    `json:"rule_id"`
+	Ptype  string   `json:"ptype"`
+	Rule   []string `json:"rule"`
+	Field  string   `json:"field"`
+	Kind   string   `json:"kind"` // missing_entity, deleted_entity, malformed_prefix or invalid_window
+	Detail string   `json:"detail"`
+	Repair string   `json:"repair"` // fixed, archived or deleted; empty when only reporting
+}
+
+// @Summary Check casbin rules against users, roles, objects and tenants, optionally repairing them
+// @Tags admin
+// @Param input body adminReconcileInput true "input"
+// @Success 200 {object} controller.Response{data=adminReconcileOutput} "output"
+// @Router /api/admin/reconcile [post]
+func adminReconcile(ctx *gin.Context) {
+	var input adminReconcileInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	report, err := casbin.Reconcile(ctx, input.Repair)
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	issues := make([]adminReconcileItem, len(report.Issues))
+	for i, issue := range report.Issues {
+		issues[i] = adminReconcileItem{
+			RuleId: issue.RuleId,
+			Ptype:  issue.Ptype,
+			Rule:   issue.Rule,
+			Field:  issue.Field,
+			Kind:   issue.Kind,
+			Detail: issue.Detail,
+			Repair: issue.Repair,
+		}
+	}
+
+	controller.Success(ctx, adminReconcileOutput{
+		Repair:   report.Repair,
+		Scanned:  report.Scanned,
+		Fixed:    report.Fixed,
+		Archived: report.Archived,
+		Deleted:  report.Deleted,
+		Issues:   issues,
+	})
+}
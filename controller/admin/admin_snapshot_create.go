@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"time"
+
+	"ac/controller"
+	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
+
+	"github.com/gin-gonic/gin"
+)
+
+type adminSnapshotCreateInput struct {
+	Label string `json:"label" binding:"required,min=1,max=100"`
+}
+
+type adminSnapshotItem struct {
+	Id        int64     `json:"id"`
+	Label     string    `json:"label"`
+	RuleCount int64     `json:"rule_count"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// @Summary Snapshot every casbin rule under a label
+// @Tags admin
+// @Param input body adminSnapshotCreateInput true "input"
+// @Success 200 {object} controller.Response{data=adminSnapshotItem} "output"
+// @Router /api/admin/policy/snapshot/create [post]
+func adminSnapshotCreate(ctx *gin.Context) {
+	var input adminSnapshotCreateInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	snapshot, err := casbin.CreateSnapshot(ctx, input.Label, audit.Actor(ctx))
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	controller.Success(ctx, snapshotItem(snapshot))
+}
+
+// snapshotItem converts a snapshot into its response form.
+func snapshotItem(snapshot *model.TblPolicySnapshot) adminSnapshotItem {
+	return adminSnapshotItem{
+		Id:        snapshot.Id,
+		Label:     snapshot.Label,
+		RuleCount: snapshot.RuleCount,
+		CreatedBy: snapshot.CreatedBy,
+		CreatedAt: snapshot.CreatedAt,
+	}
+}
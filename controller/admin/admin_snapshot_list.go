@@ -0,0 +1,54 @@
+package admin
+
+import (
+	"ac/bootstrap/database"
+	"ac/controller"
+	"ac/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/onnttf/kit/dal"
+)
+
+type adminSnapshotListInput struct {
+	Page     int `form:"page" binding:"required,min=1" default:"1"`
+	PageSize int `form:"page_size" binding:"required,min=1,max=100" default:"10"`
+}
+
+type adminSnapshotListOutput struct {
+	Total int64               `json:"total"`
+	List  []adminSnapshotItem `json:"list"`
+}
+
+// @Summary List policy snapshots with pagination, newest first
+// @Tags admin
+// @Param input query adminSnapshotListInput true "input"
+// @Success 200 {object} controller.Response{data=adminSnapshotListOutput} "output"
+// @Router /api/admin/policy/snapshot/list [get]
+func adminSnapshotList(ctx *gin.Context) {
+	var input adminSnapshotListInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	snapshotRepo := dal.NewRepo[model.TblPolicySnapshot]()
+
+	total, err := snapshotRepo.Count(ctx, database.DB)
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	snapshots, err := snapshotRepo.Query(ctx, database.DB, dal.Paginate(input.Page, input.PageSize), dal.OrderBy("id", "DESC"))
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	list := make([]adminSnapshotItem, len(snapshots))
+	for i := range snapshots {
+		list[i] = snapshotItem(&snapshots[i])
+	}
+
+	controller.Success(ctx, adminSnapshotListOutput{Total: total, List: list})
+}
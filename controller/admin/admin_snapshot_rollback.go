@@ -0,0 +1,54 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"ac/controller"
+	"ac/service/audit"
+	"ac/service/casbin"
+
+	"github.com/gin-gonic/gin"
+)
+
+type adminSnapshotRollbackInput struct {
+	Id int64 `json:"id" binding:"required,min=1"`
+}
+
+type adminSnapshotRollbackOutput struct {
+	Diff    adminSnapshotDiffOutput `json:"diff"`    // changes applied, from the live rules to the snapshot
+	Skipped []adminSnapshotRule     `json:"skipped"` // snapshot rules left out because a user, role or object they reference is gone
+}
+
+// @Summary Roll the casbin rules back to a snapshot in one transaction
+// @Tags admin
+// @Param input body adminSnapshotRollbackInput true "input"
+// @Success 200 {object} controller.Response{data=adminSnapshotRollbackOutput} "output"
+// @Router /api/admin/policy/snapshot/rollback [post]
+func adminSnapshotRollback(ctx *gin.Context) {
+	var input adminSnapshotRollbackInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	audit.RecordOnCommit(ctx, audit.Entry{
+		Action:       audit.ActionRollback,
+		ResourceType: audit.ResourceSnapshot,
+		ResourceCode: strconv.FormatInt(input.Id, 10),
+	})
+	rollback, err := casbin.RollbackToSnapshot(ctx, input.Id)
+	if errors.Is(err, casbin.ErrSnapshotNotFound) {
+		controller.Failure(ctx, controller.ErrNotFound.WithHint("snapshot not found"))
+		return
+	}
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	controller.Success(ctx, adminSnapshotRollbackOutput{
+		Diff:    snapshotDiff(rollback.Diff),
+		Skipped: snapshotRules(rollback.Skipped),
+	})
+}
@@ -0,0 +1,19 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes registers administrative maintenance routes.
+func RegisterRoutes(api *gin.RouterGroup) {
+	router := api.Group("/admin")
+
+	router.POST("/purge", adminPurge)
+	router.POST("/reconcile", adminReconcile)
+	router.POST("/policy/reload", adminPolicyReload)
+	router.POST("/policy/snapshot/create", adminSnapshotCreate)
+	router.GET("/policy/snapshot/list", adminSnapshotList)
+	router.GET("/policy/snapshot/diff", adminSnapshotDiff)
+	router.POST("/policy/snapshot/rollback", adminSnapshotRollback)
+	router.GET("/decision-cache", adminDecisionCache)
+}
@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/controller"
+	"ac/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/onnttf/kit/dal"
+	"gorm.io/gorm"
+)
+
+type auditListInput struct {
+	TenantCode   string    `form:"tenant_code" binding:"omitempty,len=36"`
+	Actor        string    `form:"actor" binding:"omitempty,max=100"`
+	Action       string    `form:"action" binding:"omitempty,oneof=create update delete restore enable disable assign remove rollback"`
+	ResourceType string    `form:"resource_type" binding:"omitempty,oneof=user role object permission snapshot"`
+	ResourceCode string    `form:"resource_code" binding:"omitempty,max=100"`
+	RequestId    string    `form:"request_id" binding:"omitempty,max=64"`
+	BeginTime    time.Time `form:"begin_time" time_format:"2006-01-02T15:04:05Z07:00"` // only entries recorded at or after
+	EndTime      time.Time `form:"end_time" time_format:"2006-01-02T15:04:05Z07:00"`   // only entries recorded before
+	Page         int       `form:"page" binding:"required,min=1" default:"1"`
+	PageSize     int       `form:"page_size" binding:"required,min=1,max=100" default:"10"`
+}
+
+type auditListOutput struct {
+	Total int64           `json:"total"`
+	List  []auditListItem `json:"list"`
+}
+
+type auditListItem struct {
+	Id           int64           `json:"id"`
+	TenantCode   string          `json:"tenant_code"`
+	Actor        string          `json:"actor"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceCode string          `json:"resource_code"`
+	OldValue     json.RawMessage `json:"old_value" swaggertype:"object"` // null when the resource did not exist before
+	NewValue     json.RawMessage `json:"new_value" swaggertype:"object"` // null when the resource no longer exists after
+	RequestId    string          `json:"request_id"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// @Summary List audit log entries with pagination, newest first
+// @Tags audit
+// @Param input query auditListInput true "input"
+// @Success 200 {object} controller.Response{data=auditListOutput} "output"
+// @Router /api/audit/list [get]
+func auditList(ctx *gin.Context) {
+	var input auditListInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	if !input.BeginTime.IsZero() && !input.EndTime.IsZero() && !input.EndTime.After(input.BeginTime) {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithHint("end_time must be after begin_time"))
+		return
+	}
+
+	filterScope := func(db *gorm.DB) *gorm.DB {
+		if input.TenantCode != "" {
+			db = db.Where("tenant_code = ?", input.TenantCode)
+		}
+		if input.Actor != "" {
+			db = db.Where("actor = ?", input.Actor)
+		}
+		if input.Action != "" {
+			db = db.Where("action = ?", input.Action)
+		}
+		if input.ResourceType != "" {
+			db = db.Where("resource_type = ?", input.ResourceType)
+		}
+		if input.ResourceCode != "" {
+			db = db.Where("resource_code = ?", input.ResourceCode)
+		}
+		if input.RequestId != "" {
+			db = db.Where("request_id = ?", input.RequestId)
+		}
+		if !input.BeginTime.IsZero() {
+			db = db.Where("created_at >= ?", input.BeginTime)
+		}
+		if !input.EndTime.IsZero() {
+			db = db.Where("created_at < ?", input.EndTime)
+		}
+		return db
+	}
+
+	auditRepo := dal.NewRepo[model.TblAuditLog]()
+
+	total, err := auditRepo.Count(ctx, database.DB, filterScope)
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	logs, err := auditRepo.Query(ctx, database.DB, filterScope, dal.Paginate(input.Page, input.PageSize), dal.OrderBy("id", "DESC"))
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	list := make([]auditListItem, len(logs))
+	for i, v := range logs {
+		list[i] = auditListItem{
+			Id:           v.Id,
+			TenantCode:   v.TenantCode,
+			Actor:        v.Actor,
+			Action:       v.Action,
+			ResourceType: v.ResourceType,
+			ResourceCode: v.ResourceCode,
+			OldValue:     rawValue(v.OldValue),
+			NewValue:     rawValue(v.NewValue),
+			RequestId:    v.RequestId,
+			CreatedAt:    v.CreatedAt,
+		}
+	}
+
+	controller.Success(ctx, auditListOutput{Total: total, List: list})
+}
+
+// rawValue embeds a stored state as JSON, or as null when there is none.
+func rawValue(value string) json.RawMessage {
+	if value == "" {
+		return nil
+	}
+	return json.RawMessage(value)
+}
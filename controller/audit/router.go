@@ -0,0 +1,12 @@
+package audit
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes registers audit log routes.
+func RegisterRoutes(api *gin.RouterGroup) {
+	router := api.Group("/audit")
+
+	router.GET("/list", auditList)
+}
@@ -0,0 +1,85 @@
+package authz
+
+import (
+	"time"
+
+	"ac/controller"
+	"ac/service/casbin"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+type authzBatchCheckInput struct {
+	TenantCode string                `json:"tenant_code" binding:"required,len=36"`
+	Items      []authzBatchCheckItem `json:"items" binding:"required,min=1,max=100,dive"`
+	Time       *time.Time            `json:"time" binding:"omitempty"`
+}
+
+type authzBatchCheckItem struct {
+	UserCode   string         `json:"user_code" binding:"required,len=36"`
+	ObjectCode string         `json:"object_code" binding:"required,len=36"`
+	Action     string         `json:"action" binding:"required,min=1,max=50"`
+	Attributes map[string]any `json:"attributes" binding:"omitempty"` // request attributes conditions may read as r.env
+}
+
+type authzBatchCheckOutput struct {
+	List      []authzBatchCheckResult `json:"list"`
+	RequestId string                  `json:"request_id"`
+}
+
+type authzBatchCheckResult struct {
+	UserCode   string `json:"user_code"`
+	ObjectCode string `json:"object_code"`
+	Action     string `json:"action"`
+	Allowed    bool   `json:"allowed"`
+}
+
+// @Summary Check multiple user/object/action tuples in one request
+// @Tags authz
+// @Param input body authzBatchCheckInput true "input"
+// @Success 200 {object} controller.Response{data=authzBatchCheckOutput} "output"
+// @Router /api/authz/batch-check [post]
+func authzBatchCheck(ctx *gin.Context) {
+	var input authzBatchCheckInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	checkTime := time.Now()
+	if input.Time != nil {
+		checkTime = *input.Time
+	}
+
+	requests := make([]casbin.PermissionRequest, len(input.Items))
+	for i, item := range input.Items {
+		requests[i] = casbin.PermissionRequest{
+			UserCode:   item.UserCode,
+			ObjectCode: item.ObjectCode,
+			Action:     item.Action,
+			Attributes: item.Attributes,
+		}
+	}
+
+	results, err := casbin.BatchCheckPermission(ctx, input.TenantCode, requests, checkTime)
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	list := make([]authzBatchCheckResult, len(input.Items))
+	for i, item := range input.Items {
+		list[i] = authzBatchCheckResult{
+			UserCode:   item.UserCode,
+			ObjectCode: item.ObjectCode,
+			Action:     item.Action,
+			Allowed:    results[i],
+		}
+	}
+
+	controller.Success(ctx, authzBatchCheckOutput{
+		List:      list,
+		RequestId: requestid.Get(ctx),
+	})
+}
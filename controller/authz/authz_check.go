@@ -0,0 +1,56 @@
+package authz
+
+import (
+	"time"
+
+	"ac/controller"
+	"ac/service/casbin"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+type authzCheckInput struct {
+	TenantCode string         `json:"tenant_code" binding:"required,len=36"`
+	UserCode   string         `json:"user_code" binding:"required,len=36"`
+	ObjectCode string         `json:"object_code" binding:"required,len=36"`
+	Action     string         `json:"action" binding:"required,min=1,max=50"`
+	Time       *time.Time     `json:"time" binding:"omitempty"`
+	Attributes map[string]any `json:"attributes" binding:"omitempty"` // request attributes conditions may read as r.env
+}
+
+type authzCheckOutput struct {
+	Allowed   bool   `json:"allowed"`
+	Reason    string `json:"reason"` // set when a disabled user, role or object caused the denial
+	RequestId string `json:"request_id"`
+}
+
+// @Summary Check whether a user may perform an action on an object
+// @Tags authz
+// @Param input body authzCheckInput true "input"
+// @Success 200 {object} controller.Response{data=authzCheckOutput} "output"
+// @Router /api/authz/check [post]
+func authzCheck(ctx *gin.Context) {
+	var input authzCheckInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	checkTime := time.Now()
+	if input.Time != nil {
+		checkTime = *input.Time
+	}
+
+	allowed, reason, err := casbin.CheckPermission(ctx, input.TenantCode, input.UserCode, input.ObjectCode, input.Action, input.Attributes, checkTime)
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	controller.Success(ctx, authzCheckOutput{
+		Allowed:   allowed,
+		Reason:    reason,
+		RequestId: requestid.Get(ctx),
+	})
+}
@@ -0,0 +1,124 @@
+package authz
+
+import (
+	"time"
+
+	"ac/controller"
+	"ac/service/casbin"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+type authzExplainInput struct {
+	TenantCode string         `json:"tenant_code" binding:"required,len=36"`
+	UserCode   string         `json:"user_code" binding:"required,len=36"`
+	ObjectCode string         `json:"object_code" binding:"required,len=36"`
+	Action     string         `json:"action" binding:"required,min=1,max=50"`
+	Time       *time.Time     `json:"time" binding:"omitempty"`
+	Attributes map[string]any `json:"attributes" binding:"omitempty"` // request attributes conditions may read as r.env
+}
+
+type authzExplainOutput struct {
+	Allowed    bool                 `json:"allowed"`
+	Reason     string               `json:"reason"` // set when a disabled user, role or object caused the denial
+	Matched    *authzExplainPolicy  `json:"matched"`
+	NearMisses []authzExplainPolicy `json:"near_misses"`
+	RequestId  string               `json:"request_id"`
+}
+
+type authzExplainPolicy struct {
+	Id           int64               `json:"id"`
+	SubjectType  string              `json:"subject_type"`
+	SubjectCode  string              `json:"subject_code"`
+	ObjectCode   string              `json:"object_code"`
+	Action       string              `json:"action"`
+	BeginTime    time.Time           `json:"begin_time"`
+	EndTime      time.Time           `json:"end_time"`
+	Recurrence   string              `json:"recurrence"`
+	Condition    string              `json:"condition"`
+	Effect       string              `json:"effect"`
+	SubjectChain []authzExplainChain `json:"subject_chain"`
+	ObjectChain  []authzExplainChain `json:"object_chain"`
+	Reasons      []string            `json:"reasons"`
+}
+
+type authzExplainChain struct {
+	Type string `json:"type"`
+	Code string `json:"code"`
+}
+
+// @Summary Explain which policy granted access, or why access was denied
+// @Tags authz
+// @Param input body authzExplainInput true "input"
+// @Success 200 {object} controller.Response{data=authzExplainOutput} "output"
+// @Router /api/authz/explain [post]
+func authzExplain(ctx *gin.Context) {
+	var input authzExplainInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	checkTime := time.Now()
+	if input.Time != nil {
+		checkTime = *input.Time
+	}
+
+	explanation, err := casbin.ExplainPermission(ctx, input.TenantCode, input.UserCode, input.ObjectCode, input.Action, input.Attributes, checkTime)
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	output := authzExplainOutput{
+		Allowed:    explanation.Allowed,
+		Reason:     explanation.Reason,
+		NearMisses: make([]authzExplainPolicy, 0, len(explanation.NearMisses)),
+		RequestId:  requestid.Get(ctx),
+	}
+	if explanation.Matched != nil {
+		matched := toAuthzExplainPolicy(*explanation.Matched)
+		output.Matched = &matched
+	}
+	for _, nearMiss := range explanation.NearMisses {
+		output.NearMisses = append(output.NearMisses, toAuthzExplainPolicy(nearMiss))
+	}
+
+	controller.Success(ctx, output)
+}
+
+func toAuthzExplainPolicy(match casbin.PolicyMatch) authzExplainPolicy {
+	subjectType := casbin.EntityTypeOf(match.Subject)
+	reasons := match.Reasons
+	if reasons == nil {
+		reasons = []string{}
+	}
+	return authzExplainPolicy{
+		Id:           match.Id,
+		SubjectType:  subjectType,
+		SubjectCode:  casbin.RemovePrefix(match.Subject, subjectType),
+		ObjectCode:   match.Policy.Object,
+		Action:       match.Policy.Action,
+		BeginTime:    match.Policy.BeginTime,
+		EndTime:      match.Policy.EndTime,
+		Recurrence:   match.Policy.Recurrence,
+		Condition:    match.Policy.Condition,
+		Effect:       match.Policy.EffectOrDefault(),
+		SubjectChain: toAuthzExplainChain(match.SubjectChain),
+		ObjectChain:  toAuthzExplainChain(match.ObjectChain),
+		Reasons:      reasons,
+	}
+}
+
+func toAuthzExplainChain(chain []string) []authzExplainChain {
+	items := make([]authzExplainChain, 0, len(chain))
+	for _, node := range chain {
+		entityType := casbin.EntityTypeOf(node)
+		items = append(items, authzExplainChain{
+			Type: entityType,
+			Code: casbin.RemovePrefix(node, entityType),
+		})
+	}
+	return items
+}
@@ -0,0 +1,100 @@
+package authz
+
+import (
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/controller"
+	"ac/model"
+	"ac/service/casbin"
+	"ac/service/user"
+
+	"github.com/gin-gonic/gin"
+	"github.com/onnttf/kit/dal"
+	"github.com/onnttf/kit/tree"
+	"gorm.io/gorm"
+)
+
+// menuTreeAction is the action a user needs on a menu for it to be shown.
+const menuTreeAction = "view"
+
+type authzMenuTreeInput struct {
+	TenantCode string `form:"tenant_code" binding:"required,len=36"`
+	UserCode   string `form:"user_code" binding:"required,len=36"`
+}
+
+type authzMenuTreeOutput struct {
+	List []authzMenuTreeItem `json:"list"`
+}
+
+type authzMenuTreeItem struct {
+	Code     string              `json:"code"`
+	Name     string              `json:"name"`
+	Type     model.ObjectType    `json:"type"`
+	Sort     int64               `json:"sort"`
+	Children []authzMenuTreeItem `json:"children"`
+}
+
+// @Summary Get the menu tree a user may view
+// @Tags authz
+// @Param input query authzMenuTreeInput true "input"
+// @Success 200 {object} controller.Response{data=authzMenuTreeOutput} "output"
+// @Router /api/authz/menu-tree [get]
+func authzMenuTree(ctx *gin.Context) {
+	var input authzMenuTreeInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	if err := user.Verify(ctx, input.TenantCode, input.UserCode); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	menuList, err := dal.NewRepo[model.TblObject]().Query(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+		return db.Where("type = ? AND tenant_code = ? AND status = ? AND deleted = ?", model.ObjectTypeMenu, input.TenantCode, model.StatusEnabled.Int64(), model.NotDeleted)
+	})
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	menuMap := make(map[string]model.TblObject, len(menuList))
+	menuCodes := make([]string, 0, len(menuList))
+	treeBuilder := tree.NewTreeBuilder()
+	for _, v := range menuList {
+		menuMap[v.Code] = v
+		menuCodes = append(menuCodes, v.Code)
+		treeBuilder.AddNode(v.Code, v.ParentCode, int(v.Sort))
+	}
+
+	permitted, err := casbin.FilterPermittedObjects(ctx, input.TenantCode, input.UserCode, menuCodes, menuTreeAction, nil, time.Now())
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	// prune root-down: a menu is only shown when it and all of its ancestors are viewable
+	var buildItems func(nodes []*tree.Node) []authzMenuTreeItem
+	buildItems = func(nodes []*tree.Node) []authzMenuTreeItem {
+		items := make([]authzMenuTreeItem, 0, len(nodes))
+		for _, node := range nodes {
+			if _, ok := permitted[node.NodeKey]; !ok {
+				continue
+			}
+			menu := menuMap[node.NodeKey]
+			items = append(items, authzMenuTreeItem{
+				Code:     menu.Code,
+				Name:     menu.Name,
+				Type:     menu.Type,
+				Sort:     menu.Sort,
+				Children: buildItems(node.Children),
+			})
+		}
+		return items
+	}
+
+	_, rootNodes := treeBuilder.Build()
+	controller.Success(ctx, authzMenuTreeOutput{List: buildItems(rootNodes)})
+}
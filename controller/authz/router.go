@@ -0,0 +1,15 @@
+package authz
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes registers authorization check routes.
+func RegisterRoutes(api *gin.RouterGroup) {
+	router := api.Group("/authz")
+
+	router.POST("/check", authzCheck)
+	router.POST("/batch-check", authzBatchCheck)
+	router.POST("/explain", authzExplain)
+	router.GET("/menu-tree", authzMenuTree)
+}
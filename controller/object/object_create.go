@@ -6,6 +6,9 @@ import (
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
+	"ac/service/tenant"
 	"ac/util"
 
 	"github.com/gin-gonic/gin"
@@ -15,8 +18,10 @@ import (
 )
 
 type objectCreateInput struct {
-	Name       string `json:"name" binding:"required,min=1,max=50"`
-	ParentCode string `json:"parent_code" binding:"omitempty,len=36"`
+	TenantCode string         `json:"tenant_code" binding:"required,len=36"`
+	Name       string         `json:"name" binding:"required,min=1,max=50"`
+	ParentCode string         `json:"parent_code" binding:"omitempty,len=36"`
+	Attributes map[string]any `json:"attributes" binding:"omitempty"` // attributes conditions read as r.object
 }
 
 type objectCreateOutput struct {
@@ -36,13 +41,26 @@ func objectCreate(ctx *gin.Context) {
 		return
 	}
 
+	if err := tenant.Verify(ctx, input.TenantCode); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithHint("tenant not found").WithError(err))
+		return
+	}
+
 	now := time.Now()
+	attributes, err := casbin.FormatAttributes(input.Attributes)
+	if err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithHint("invalid attributes").WithError(err))
+		return
+	}
+
 	newValue := &model.TblObject{
 		Code:       util.GenerateCode(),
+		TenantCode: input.TenantCode,
 		Name:       input.Name,
 		Type:       model.ObjectTypeMenu,
 		ParentCode: input.ParentCode,
 		Sort:       1,
+		Attributes: attributes,
 		Status:     model.StatusEnabled.Int64(),
 		Deleted:    model.NotDeleted,
 		CreatedAt:  now,
@@ -53,7 +71,7 @@ func objectCreate(ctx *gin.Context) {
 	if input.ParentCode != "" {
 
 		parent, err := objectRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-			return db.Where("code = ? AND deleted = ?", input.ParentCode, model.NotDeleted)
+			return db.Where("code = ? AND tenant_code = ? AND deleted = ?", input.ParentCode, input.TenantCode, model.NotDeleted)
 		})
 		if err != nil {
 			controller.Failure(ctx, controller.ErrSystemError.WithHint("parent object not found"))
@@ -76,12 +94,36 @@ func objectCreate(ctx *gin.Context) {
 		}
 	}
 
-	if err := objectRepo.Insert(ctx, database.DB, newValue); err != nil {
-
+	// insert the object and its group link together, root objects link to the tenant
+	var changed *casbin.ChangedRules
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := objectRepo.Insert(ctx, tx, newValue); err != nil {
+			return err
+		}
+		if err := audit.Record(ctx, tx, audit.Entry{
+			TenantCode:   newValue.TenantCode,
+			Action:       audit.ActionCreate,
+			ResourceType: audit.ResourceObject,
+			ResourceCode: newValue.Code,
+			NewValue:     newValue,
+		}); err != nil {
+			return err
+		}
+		var err error
+		changed, err = casbin.SetObjectParent(ctx, tx, input.TenantCode, newValue.Code, input.ParentCode)
+		return err
+	}); err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
 	}
 
+	if changed != nil {
+		if err := casbin.SyncChangedRules(ctx, changed); err != nil {
+			controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+			return
+		}
+	}
+
 	controller.Success(ctx, objectCreateOutput{
 		Code: newValue.Code,
 	})
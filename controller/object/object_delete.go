@@ -6,6 +6,8 @@ import (
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
 
 	"github.com/gin-gonic/gin"
 	"github.com/onnttf/kit/dal"
@@ -14,10 +16,14 @@ import (
 )
 
 type objectDeleteInput struct {
-	Code string `json:"code" binding:"required,len=36"`
+	TenantCode string `json:"tenant_code" binding:"required,len=36"`
+	Code       string `json:"code" binding:"required,len=36"`
 }
 
-type objectDeleteOutput struct{}
+type objectDeleteOutput struct {
+	RemovedObjectLinks int64 `json:"removed_object_links"`
+	RemovedPolicies    int64 `json:"removed_policies"`
+}
 
 // @Summary Delete an existing object
 // @Tags object
@@ -34,7 +40,7 @@ func objectDelete(ctx *gin.Context) {
 	objectRepo := dal.NewRepo[model.TblObject]()
 
 	object, err := objectRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("code = ?", input.Code)
+		return db.Where("code = ? AND tenant_code = ? AND deleted = ?", input.Code, input.TenantCode, model.NotDeleted)
 	})
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -45,15 +51,54 @@ func objectDelete(ctx *gin.Context) {
 		return
 	}
 
+	childCount, err := objectRepo.Count(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+		return db.Where("parent_code = ? AND deleted = ?", object.Code, model.NotDeleted)
+	})
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+	if childCount > 0 {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithHint("object has child objects, move or delete them first"))
+		return
+	}
+
+	oldValue := *object
 	object.Deleted = 1
 	object.UpdatedAt = time.Now()
 
-	if err := objectRepo.Update(ctx, database.DB, object, func(db *gorm.DB) *gorm.DB {
-		return db.Where("code = ?", input.Code)
+	// delete the object together with its parent or tenant link, group links and policies
+	var removed *casbin.RemovedRules
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := objectRepo.Update(ctx, tx, object, func(db *gorm.DB) *gorm.DB {
+			return db.Where("code = ?", input.Code)
+		}); err != nil {
+			return err
+		}
+		if err := audit.Record(ctx, tx, audit.Entry{
+			TenantCode:   object.TenantCode,
+			Action:       audit.ActionDelete,
+			ResourceType: audit.ResourceObject,
+			ResourceCode: object.Code,
+			OldValue:     oldValue,
+		}); err != nil {
+			return err
+		}
+		var err error
+		removed, err = casbin.RemoveEntityRules(ctx, tx, object.Code, casbin.EntityObject)
+		return err
 	}); err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
 	}
 
-	controller.Success(ctx, objectDeleteOutput{})
+	if err := casbin.SyncRemovedRules(ctx, removed); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	controller.Success(ctx, objectDeleteOutput{
+		RemovedObjectLinks: removed.ObjectLinks,
+		RemovedPolicies:    removed.Policies,
+	})
 }
@@ -4,6 +4,7 @@ import (
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/casbin"
 
 	"github.com/gin-gonic/gin"
 	"github.com/onnttf/kit/dal"
@@ -11,13 +12,16 @@ import (
 )
 
 type objectFetchInput struct {
-	Code string `json:"code" binding:"required,len=36"`
+	TenantCode string `json:"tenant_code" binding:"required,len=36"`
+	Code       string `json:"code" binding:"required,len=36"`
 }
 
 type objectFetchOutput struct {
-	Code string `json:"code"`
-	Name string `json:"name"`
-	Type string `json:"type"`
+	Code       string         `json:"code"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Status     int64          `json:"status"`
+	Attributes map[string]any `json:"attributes"`
 }
 
 // @Summary Fetch a object by code
@@ -35,7 +39,7 @@ func objectFetch(ctx *gin.Context) {
 	objectRepo := dal.NewRepo[model.TblObject]()
 
 	object, err := objectRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("code = ?", input.Code)
+		return db.Where("code = ? AND tenant_code = ?", input.Code, input.TenantCode)
 	})
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -46,8 +50,16 @@ func objectFetch(ctx *gin.Context) {
 		return
 	}
 
+	attributes, err := casbin.ParseAttributes(object.Attributes)
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithHint("invalid object attributes").WithError(err))
+		return
+	}
+
 	controller.Success(ctx, objectFetchOutput{
-		Code: object.Code,
-		Name: object.Name,
+		Code:       object.Code,
+		Name:       object.Name,
+		Status:     object.Status,
+		Attributes: attributes,
 	})
 }
@@ -12,8 +12,10 @@ import (
 )
 
 type objectListInput struct {
-	Page     int `form:"page" binding:"required,min=1" default:"1"`
-	PageSize int `form:"page_size" binding:"required,min=1,max=100" default:"10"`
+	TenantCode string `form:"tenant_code" binding:"required,len=36"`
+	Page       int    `form:"page" binding:"required,min=1" default:"1"`
+	PageSize   int    `form:"page_size" binding:"required,min=1,max=100" default:"10"`
+	Deleted    bool   `form:"deleted"` // list deleted objects instead, to find ones to restore
 }
 
 type objectListOutput struct {
@@ -22,10 +24,11 @@ type objectListOutput struct {
 }
 
 type objectListItem struct {
-	Id   int64            `json:"id"`
-	Code string           `json:"code"`
-	Name string           `json:"name"`
-	Type model.ObjectType `json:"type"`
+	Id     int64            `json:"id"`
+	Code   string           `json:"code"`
+	Name   string           `json:"name"`
+	Type   model.ObjectType `json:"type"`
+	Status int64            `json:"status"`
 }
 
 // @Summary List objects with pagination
@@ -42,15 +45,22 @@ func objectList(ctx *gin.Context) {
 
 	objectRepo := dal.NewRepo[model.TblObject]()
 
-	total, err := objectRepo.Count(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db
-	})
+	deleted := model.NotDeleted
+	if input.Deleted {
+		deleted = model.Deleted
+	}
+
+	tenantScope := func(db *gorm.DB) *gorm.DB {
+		return db.Where("tenant_code = ? AND deleted = ?", input.TenantCode, deleted)
+	}
+
+	total, err := objectRepo.Count(ctx, database.DB, tenantScope)
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
 	}
 
-	objectList, err := objectRepo.Query(ctx, database.DB, dal.Paginate(input.Page, input.PageSize), dal.OrderBy("id", "DESC"))
+	objectList, err := objectRepo.Query(ctx, database.DB, tenantScope, dal.Paginate(input.Page, input.PageSize), dal.OrderBy("id", "DESC"))
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
@@ -61,9 +71,10 @@ func objectList(ctx *gin.Context) {
 	for i, v := range objectList {
 		treeBuilder.AddNode(v.Code, v.ParentCode, int(v.Sort))
 		list[i] = objectListItem{
-			Id:   v.Id,
-			Code: v.Code,
-			Name: v.Name,
+			Id:     v.Id,
+			Code:   v.Code,
+			Name:   v.Name,
+			Status: v.Status,
 		}
 	}
 
@@ -11,9 +11,10 @@ import (
 )
 
 type objectQueryInput struct {
-	Page     int    `form:"page" binding:"required,min=1" default:"1"`
-	PageSize int    `form:"page_size" binding:"required,min=1,max=100" default:"10"`
-	Name     string `form:"name" binding:"omitempty,min=1"`
+	TenantCode string `form:"tenant_code" binding:"required,len=36"`
+	Page       int    `form:"page" binding:"required,min=1" default:"1"`
+	PageSize   int    `form:"page_size" binding:"required,min=1,max=100" default:"10"`
+	Name       string `form:"name" binding:"omitempty,min=1"`
 }
 
 type objectQueryOutput struct {
@@ -37,6 +38,7 @@ func objectQuery(ctx *gin.Context) {
 	objectRepo := dal.NewRepo[model.TblObject]()
 
 	object, err := objectRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+		db = db.Where("tenant_code = ?", input.TenantCode)
 		if input.Name != "" {
 			db = db.Where("name LIKE ?", "%"+input.Name+"%")
 		}
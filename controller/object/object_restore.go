@@ -0,0 +1,120 @@
+package object
+
+import (
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/controller"
+	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
+
+	"github.com/gin-gonic/gin"
+	"github.com/onnttf/kit/dal"
+	"gorm.io/gorm"
+)
+
+type objectRestoreInput struct {
+	TenantCode   string `json:"tenant_code" binding:"required,len=36"`
+	Code         string `json:"code" binding:"required,len=36"`
+	RestoreRules bool   `json:"restore_rules"` // also re-create the group links and policies removed on delete
+}
+
+type objectRestoreOutput struct {
+	RestoredObjectLinks int64 `json:"restored_object_links"`
+	RestoredPolicies    int64 `json:"restored_policies"`
+	SkippedRules        int64 `json:"skipped_rules"`
+}
+
+// @Summary Restore a deleted object
+// @Tags object
+// @Param input body objectRestoreInput true "input"
+// @Success 200 {object} controller.Response{data=objectRestoreOutput} "output"
+// @Router /api/object/restore [post]
+func objectRestore(ctx *gin.Context) {
+	var input objectRestoreInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	objectRepo := dal.NewRepo[model.TblObject]()
+
+	object, err := objectRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+		return db.Where("code = ? AND tenant_code = ? AND deleted = ?", input.Code, input.TenantCode, model.Deleted)
+	})
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+	if object == nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithMsg("deleted object not found"))
+		return
+	}
+
+	if object.ParentCode != "" {
+		parentCount, err := objectRepo.Count(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+			return db.Where("code = ? AND tenant_code = ? AND deleted = ?", object.ParentCode, object.TenantCode, model.NotDeleted)
+		})
+		if err != nil {
+			controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+			return
+		}
+		if parentCount == 0 {
+			controller.Failure(ctx, controller.ErrInvalidInput.WithHint("parent object is deleted, restore it first"))
+			return
+		}
+	}
+
+	now := time.Now()
+	newValue := map[string]any{
+		"deleted":    model.NotDeleted,
+		"updated_at": now,
+	}
+
+	// restore the object together with its parent or tenant link and, if asked, its group links and policies
+	var restored *casbin.RestoredRules
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Deleted goes back to zero, which a struct update would skip
+		if err := objectRepo.UpdateFields(ctx, tx, newValue, func(db *gorm.DB) *gorm.DB {
+			return db.Where("code = ?", object.Code)
+		}); err != nil {
+			return err
+		}
+		restoredObject := *object
+		restoredObject.Deleted = model.NotDeleted
+		restoredObject.UpdatedAt = now
+		if err := audit.Record(ctx, tx, audit.Entry{
+			TenantCode:   object.TenantCode,
+			Action:       audit.ActionRestore,
+			ResourceType: audit.ResourceObject,
+			ResourceCode: object.Code,
+			OldValue:     object,
+			NewValue:     restoredObject,
+		}); err != nil {
+			return err
+		}
+		var err error
+		restored, err = casbin.RestoreEntityRules(ctx, tx, object.TenantCode, object.Code, casbin.EntityObject, object.ParentCode, input.RestoreRules)
+		return err
+	}); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	if err := casbin.SyncRestoredRules(ctx, restored); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	if err := casbin.SetEntityStatus(ctx, object.Code, casbin.EntityObject, model.StatusFlag(object.Status)); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	controller.Success(ctx, objectRestoreOutput{
+		RestoredObjectLinks: restored.ObjectLinks,
+		RestoredPolicies:    restored.Policies,
+		SkippedRules:        restored.Skipped,
+	})
+}
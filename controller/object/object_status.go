@@ -0,0 +1,103 @@
+package object
+
+import (
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/controller"
+	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
+
+	"github.com/gin-gonic/gin"
+	"github.com/onnttf/kit/dal"
+	"gorm.io/gorm"
+)
+
+type objectStatusInput struct {
+	TenantCode string `json:"tenant_code" binding:"required,len=36"`
+	Code       string `json:"code" binding:"required,len=36"`
+}
+
+type objectStatusOutput struct{}
+
+// @Summary Enable an object, restoring access to it and its children
+// @Tags object
+// @Param input body objectStatusInput true "input"
+// @Success 200 {object} controller.Response{data=objectStatusOutput} "output"
+// @Router /api/object/enable [post]
+func objectEnable(ctx *gin.Context) {
+	setObjectStatus(ctx, model.StatusEnabled)
+}
+
+// @Summary Disable an object, blocking access to it and through it without removing grants
+// @Tags object
+// @Param input body objectStatusInput true "input"
+// @Success 200 {object} controller.Response{data=objectStatusOutput} "output"
+// @Router /api/object/disable [post]
+func objectDisable(ctx *gin.Context) {
+	setObjectStatus(ctx, model.StatusDisabled)
+}
+
+func setObjectStatus(ctx *gin.Context, status model.StatusFlag) {
+	var input objectStatusInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	objectRepo := dal.NewRepo[model.TblObject]()
+
+	object, err := objectRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+		return db.Where("code = ? AND tenant_code = ? AND deleted = ?", input.Code, input.TenantCode, model.NotDeleted)
+	})
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+	if object == nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithMsg("object not found"))
+		return
+	}
+
+	now := time.Now()
+	newValue := map[string]any{
+		"status":     status.Int64(),
+		"updated_at": now,
+	}
+
+	updatedObject := *object
+	updatedObject.Status = status.Int64()
+	updatedObject.UpdatedAt = now
+	action := audit.ActionEnable
+	if status == model.StatusDisabled {
+		action = audit.ActionDisable
+	}
+
+	// update the status and record the change together
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := objectRepo.UpdateFields(ctx, tx, newValue, func(db *gorm.DB) *gorm.DB {
+			return db.Where("code = ?", object.Code)
+		}); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Entry{
+			TenantCode:   object.TenantCode,
+			Action:       action,
+			ResourceType: audit.ResourceObject,
+			ResourceCode: object.Code,
+			OldValue:     object,
+			NewValue:     updatedObject,
+		})
+	}); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	if err := casbin.SetEntityStatus(ctx, object.Code, casbin.EntityObject, status); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	controller.Success(ctx, objectStatusOutput{})
+}
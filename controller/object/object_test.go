@@ -0,0 +1,122 @@
+package object
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"ac/bootstrap/bootstraptest"
+	"ac/bootstrap/database"
+	"ac/controller"
+	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(bootstraptest.Main(m, casbin.Initialize))
+}
+
+// createObject creates an object through the API and returns its code.
+func createObject(t *testing.T, tenantCode, name, parentCode string) string {
+	t.Helper()
+
+	var output objectCreateOutput
+	response := bootstraptest.Post(t, RegisterRoutes, "/object/create", objectCreateInput{TenantCode: tenantCode, Name: name, ParentCode: parentCode}, &output)
+	if response.Code != 0 {
+		t.Fatalf("create object %s: code=%d, hint=%s, err=%s", name, response.Code, response.Hint, response.Err)
+	}
+	return output.Code
+}
+
+// storedObjectParents returns the parents the database links an object to.
+func storedObjectParents(t *testing.T, objectCode string) []string {
+	t.Helper()
+
+	var parents []string
+	if err := database.DB.Model(&model.TblCasbinRule{}).
+		Where("ptype = ? AND v0 = ?", casbin.GroupingObjectGroup, casbin.AddPrefix(objectCode, casbin.EntityObject)).
+		Pluck("v1", &parents).Error; err != nil {
+		t.Fatalf("query parents: %v", err)
+	}
+	return parents
+}
+
+func TestObjectParentSync(t *testing.T) {
+	tenantCode := bootstraptest.NewTenant(t)
+	root := createObject(t, tenantCode, "root", "")
+	other := createObject(t, tenantCode, "other", "")
+	child := createObject(t, tenantCode, "child", root)
+
+	tenantRoot := casbin.AddPrefix(tenantCode, casbin.EntityTenant)
+	if parents := storedObjectParents(t, root); !slices.Equal(parents, []string{tenantRoot}) {
+		t.Fatalf("stored parents of root = %v, want %s", parents, tenantRoot)
+	}
+	if parents := storedObjectParents(t, child); !slices.Equal(parents, []string{casbin.AddPrefix(root, casbin.EntityObject)}) {
+		t.Fatalf("stored parents after create = %v, want %s", parents, root)
+	}
+
+	ctx := bootstraptest.NewContext()
+	if groups, _ := casbin.GetGroupsForObject(ctx, child); !slices.Equal(groups, []string{root}) {
+		t.Fatalf("groups after create = %v, want %s", groups, root)
+	}
+
+	// Move the child under another root
+	if response := bootstraptest.Post(t, RegisterRoutes, "/object/update", objectUpdateInput{TenantCode: tenantCode, Code: child, Name: "child", ParentCode: &other}, nil); response.Code != 0 {
+		t.Fatalf("update parent: code=%d, hint=%s, err=%s", response.Code, response.Hint, response.Err)
+	}
+	if parents := storedObjectParents(t, child); !slices.Equal(parents, []string{casbin.AddPrefix(other, casbin.EntityObject)}) {
+		t.Fatalf("stored parents after update = %v, want %s", parents, other)
+	}
+	if groups, _ := casbin.GetGroupsForObject(ctx, child); !slices.Equal(groups, []string{other}) {
+		t.Fatalf("groups after update = %v, want %s", groups, other)
+	}
+
+	// A parent may not join a group below itself
+	response := bootstraptest.Post(t, RegisterRoutes, "/object/update", objectUpdateInput{TenantCode: tenantCode, Code: other, Name: "other", ParentCode: &child}, nil)
+	if response.Code != controller.ErrInvalidInput.Code {
+		t.Fatalf("cycle update: code=%d, want %d", response.Code, controller.ErrInvalidInput.Code)
+	}
+	if parents := storedObjectParents(t, other); !slices.Equal(parents, []string{tenantRoot}) {
+		t.Fatalf("stored parents after rejected update = %v, want %s", parents, tenantRoot)
+	}
+
+	// Move the child back to the tenant root
+	empty := ""
+	if response := bootstraptest.Post(t, RegisterRoutes, "/object/update", objectUpdateInput{TenantCode: tenantCode, Code: child, Name: "child", ParentCode: &empty}, nil); response.Code != 0 {
+		t.Fatalf("move to tenant root: code=%d, hint=%s, err=%s", response.Code, response.Hint, response.Err)
+	}
+	if parents := storedObjectParents(t, child); !slices.Equal(parents, []string{tenantRoot}) {
+		t.Fatalf("stored parents after moving to the tenant root = %v, want %s", parents, tenantRoot)
+	}
+	if groups, _ := casbin.GetGroupsForObject(ctx, child); !slices.Equal(groups, []string{tenantRoot}) {
+		t.Fatalf("groups after moving to the tenant root = %v, want %s", groups, tenantRoot)
+	}
+}
+
+func TestDeletedObjectNotFound(t *testing.T) {
+	tenantCode := bootstraptest.NewTenant(t)
+	object := createObject(t, tenantCode, "object", "")
+
+	for range 2 {
+		bootstraptest.Post(t, RegisterRoutes, "/object/delete", objectDeleteInput{TenantCode: tenantCode, Code: object}, nil)
+	}
+	var audits int64
+	if err := database.DB.Model(&model.TblAuditLog{}).
+		Where("resource_code = ? AND action = ?", object, audit.ActionDelete).
+		Count(&audits).Error; err != nil {
+		t.Fatalf("count audit entries: %v", err)
+	}
+	if audits != 1 {
+		t.Fatalf("audit entries after deleting twice = %d, want 1", audits)
+	}
+
+	// A deleted object can no longer be updated
+	response := bootstraptest.Post(t, RegisterRoutes, "/object/update", objectUpdateInput{TenantCode: tenantCode, Code: object, Name: "renamed"}, nil)
+	if response.Code != controller.ErrInvalidInput.Code {
+		t.Fatalf("update deleted object: code=%d, want %d", response.Code, controller.ErrInvalidInput.Code)
+	}
+}
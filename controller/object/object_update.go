@@ -1,11 +1,14 @@
 package object
 
 import (
+	"errors"
 	"time"
 
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
 
 	"github.com/gin-gonic/gin"
 	"github.com/onnttf/kit/dal"
@@ -14,9 +17,11 @@ import (
 )
 
 type objectUpdateInput struct {
-	Code       string `json:"code" binding:"required,len=36"`
-	Name       string `json:"name" binding:"required,min=1,max=50"`
-	ParentCode string `json:"parent_code" binding:"omitempty,len=36"`
+	TenantCode string         `json:"tenant_code" binding:"required,len=36"`
+	Code       string         `json:"code" binding:"required,len=36"`
+	Name       string         `json:"name" binding:"required,min=1,max=50"`
+	ParentCode *string        `json:"parent_code" binding:"omitempty"` // omit to keep, empty string to move to the tenant root
+	Attributes map[string]any `json:"attributes" binding:"omitempty"`  // replaces stored attributes, omit to keep
 }
 
 type objectUpdateOutput struct{}
@@ -33,10 +38,16 @@ func objectUpdate(ctx *gin.Context) {
 		return
 	}
 
+	// parent_code is optional; an empty string moves the object to the tenant root
+	if input.ParentCode != nil && *input.ParentCode != "" && len(*input.ParentCode) != 36 {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithHint("parent_code must be empty or 36 characters"))
+		return
+	}
+
 	objectRepo := dal.NewRepo[model.TblObject]()
 
 	object, err := objectRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("code = ?", input.Code)
+		return db.Where("code = ? AND tenant_code = ? AND deleted = ?", input.Code, input.TenantCode, model.NotDeleted)
 	})
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -46,19 +57,81 @@ func objectUpdate(ctx *gin.Context) {
 		controller.Failure(ctx, controller.ErrInvalidInput.WithMsg("object not found"))
 		return
 	}
+	oldValue := *object
 
-	object.Name = input.Name
-	if input.ParentCode != "" {
-		object.ParentCode = input.ParentCode
+	parentChanged := input.ParentCode != nil && *input.ParentCode != object.ParentCode
+	if parentChanged && *input.ParentCode != "" {
+		if *input.ParentCode == object.Code {
+			controller.Failure(ctx, controller.ErrInvalidInput.WithHint("object cannot be its own parent"))
+			return
+		}
+		parent, err := objectRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+			return db.Where("code = ? AND tenant_code = ? AND deleted = ?", *input.ParentCode, input.TenantCode, model.NotDeleted)
+		})
+		if err != nil {
+			controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+			return
+		}
+		if parent == nil {
+			controller.Failure(ctx, controller.ErrInvalidInput.WithHint("parent object not found"))
+			return
+		}
+	}
+	if parentChanged {
+		object.ParentCode = *input.ParentCode
 	}
+
+	if input.Attributes != nil {
+		attributes, err := casbin.FormatAttributes(input.Attributes)
+		if err != nil {
+			controller.Failure(ctx, controller.ErrInvalidInput.WithHint("invalid attributes").WithError(err))
+			return
+		}
+		object.Attributes = attributes
+	}
+
+	object.Name = input.Name
 	object.UpdatedAt = time.Now()
 
-	if err := objectRepo.Update(ctx, database.DB, object, func(db *gorm.DB) *gorm.DB {
-		return db.Where("code = ?", input.Code)
+	// update the object and its group link together
+	var changed *casbin.ChangedRules
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := objectRepo.Update(ctx, tx, object, func(db *gorm.DB) *gorm.DB {
+			return db.Where("code = ?", input.Code)
+		}); err != nil {
+			return err
+		}
+		if err := audit.Record(ctx, tx, audit.Entry{
+			TenantCode:   object.TenantCode,
+			Action:       audit.ActionUpdate,
+			ResourceType: audit.ResourceObject,
+			ResourceCode: object.Code,
+			OldValue:     oldValue,
+			NewValue:     object,
+		}); err != nil {
+			return err
+		}
+		if !parentChanged {
+			return nil
+		}
+		var err error
+		changed, err = casbin.SetObjectParent(ctx, tx, object.TenantCode, object.Code, object.ParentCode)
+		return err
 	}); err != nil {
+		if errors.Is(err, casbin.ErrHierarchyCycle) {
+			controller.Failure(ctx, controller.ErrInvalidInput.WithHint("parent object would create a cycle"))
+			return
+		}
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
 	}
 
+	if changed != nil {
+		if err := casbin.SyncChangedRules(ctx, changed); err != nil {
+			controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+			return
+		}
+	}
+
 	controller.Success(ctx, objectUpdateOutput{})
 }
@@ -11,6 +11,9 @@ func RegisterRoutes(api *gin.RouterGroup) {
 	router.POST("/create", objectCreate)
 	router.POST("/update", objectUpdate)
 	router.POST("/delete", objectDelete)
+	router.POST("/enable", objectEnable)
+	router.POST("/disable", objectDisable)
+	router.POST("/restore", objectRestore)
 	router.GET("/fetch", objectFetch)
 	router.GET("/list", objectList)
 	router.GET("/query", objectQuery)
@@ -1,11 +1,15 @@
 package permission
 
 import (
+	"errors"
+	"strconv"
+	"strings"
 	"time"
 
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/audit"
 	"ac/service/casbin"
 	"ac/util"
 
@@ -16,12 +20,16 @@ import (
 )
 
 type permissionCreateInput struct {
+	TenantCode string    `json:"tenant_code" binding:"required,len=36"`
 	UserCode   string    `json:"user_code" binding:"omitempty,len=36"`
 	RoleCode   string    `json:"role_code" binding:"omitempty,len=36"`
 	ObjectCode string    `json:"object_code" binding:"required,len=36"`
 	Action     string    `json:"action" binding:"required,min=1,max=50"`
 	BeginTime  time.Time `json:"begin_time" binding:"required"`
 	EndTime    time.Time `json:"end_time" binding:"required"`
+	Recurrence string    `json:"recurrence" binding:"omitempty,max=50"` // e.g. "MON-FRI 09:00-18:00 Asia/Shanghai"
+	Condition  string    `json:"condition" binding:"omitempty,max=255"` // e.g. "r.object.owner_department == r.user.department"
+	Effect     string    `json:"effect" binding:"omitempty,oneof=allow deny"`
 }
 
 type permissionCreateOutput struct {
@@ -29,8 +37,10 @@ type permissionCreateOutput struct {
 }
 
 var (
-	ErrInvalidTimeRange = util.NewError(1004, "invalid time range", "end_time must be after begin_time")
-	ErrObjectNotFound   = util.NewError(1003, "object not found", "the specified object does not exist")
+	ErrInvalidTimeRange  = util.NewError(1004, "invalid time range", "end_time must be after begin_time")
+	ErrObjectNotFound    = util.NewError(1003, "object not found", "the specified object does not exist")
+	ErrInvalidRecurrence = util.NewError(1005, "invalid recurrence", "recurrence must look like MON-FRI 09:00-18:00 Asia/Shanghai")
+	ErrInvalidCondition  = util.NewError(1006, "invalid condition", "condition must be an expression over r.user, r.object and r.env attributes")
 )
 
 // @Summary Create a new permission
@@ -63,13 +73,27 @@ func permissionCreate(ctx *gin.Context) {
 		return
 	}
 
+	input.Recurrence = casbin.NormalizeRecurrence(input.Recurrence)
+	if input.Recurrence != "" {
+		if _, err := casbin.ParseRecurrence(input.Recurrence); err != nil {
+			controller.Failure(ctx, ErrInvalidRecurrence.WithError(err))
+			return
+		}
+	}
+
+	input.Condition = strings.TrimSpace(input.Condition)
+	if err := casbin.ValidateCondition(input.Condition); err != nil {
+		controller.Failure(ctx, ErrInvalidCondition.WithError(err))
+		return
+	}
+
 	var subjectCode string
 
 	// validate user existence
 	if input.UserCode != "" {
 		userRepo := dal.NewRepo[model.TblSubject]()
 		user, err := userRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-			return db.Where("code = ? AND type = ? AND deleted = ?", input.UserCode, model.SubjectTypeUser, model.NotDeleted)
+			return db.Where("code = ? AND tenant_code = ? AND type = ? AND deleted = ?", input.UserCode, input.TenantCode, model.SubjectTypeUser, model.NotDeleted)
 		})
 		if err != nil {
 			controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -86,7 +110,7 @@ func permissionCreate(ctx *gin.Context) {
 	if input.RoleCode != "" {
 		roleRepo := dal.NewRepo[model.TblSubject]()
 		role, err := roleRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-			return db.Where("code = ? AND type = ? AND deleted = ?", input.RoleCode, model.SubjectTypeRole, model.NotDeleted)
+			return db.Where("code = ? AND tenant_code = ? AND type = ? AND deleted = ?", input.RoleCode, input.TenantCode, model.SubjectTypeRole, model.NotDeleted)
 		})
 		if err != nil {
 			controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -106,10 +130,14 @@ func permissionCreate(ctx *gin.Context) {
 	}
 
 	objectCode := input.ObjectCode
+	effect := input.Effect
+	if effect == "" {
+		effect = casbin.EffectAllow
+	}
 
 	objectRepo := dal.NewRepo[model.TblObject]()
 	object, err := objectRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("code = ? AND deleted = ?", input.ObjectCode, model.NotDeleted)
+		return db.Where("code = ? AND tenant_code = ? AND deleted = ?", input.ObjectCode, input.TenantCode, model.NotDeleted)
 	})
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -120,38 +148,47 @@ func permissionCreate(ctx *gin.Context) {
 		return
 	}
 
+	ruleRepo := dal.NewRepo[model.TblCasbinRule]()
+	subjectPrefixed := casbin.AddPrefix(subjectCode, casbin.EntityRole)
 	if input.UserCode != "" {
-		if err := casbin.AssignPoliciesToUser(ctx, input.UserCode, []casbin.Policy{{Object: objectCode, Action: input.Action, BeginTime: input.BeginTime, EndTime: input.EndTime}}); err != nil {
+		subjectPrefixed = casbin.AddPrefix(subjectCode, casbin.EntityUser)
+	}
+
+	// look up the created rule and record it within the transaction storing it
+	var rule *model.TblCasbinRule
+	casbin.OnCommit(ctx, func(tx *gorm.DB) error {
+		var err error
+		rule, err = ruleRepo.QueryOne(ctx, tx, func(db *gorm.DB) *gorm.DB {
+			return db.Where("ptype = ? AND v0 = ? AND v1 = ? AND v2 = ? AND v3 = ? AND v4 = ? AND v5 = ?",
+				"p", subjectPrefixed, casbin.AddPrefix(objectCode, casbin.EntityObject), input.Action,
+				casbin.FormatWindow(input.BeginTime, input.EndTime, input.Recurrence), input.Condition, effect)
+		})
+		if err != nil {
+			return err
+		}
+		if rule == nil {
+			return errors.New("created policy not found")
+		}
+		return audit.Record(ctx, tx, audit.Entry{
+			TenantCode:   input.TenantCode,
+			Action:       audit.ActionCreate,
+			ResourceType: audit.ResourcePermission,
+			ResourceCode: strconv.FormatInt(rule.Id, 10),
+			NewValue:     rule,
+		})
+	})
+
+	if input.UserCode != "" {
+		if err := casbin.AssignPoliciesToUser(ctx, input.UserCode, []casbin.Policy{{Object: objectCode, Action: input.Action, BeginTime: input.BeginTime, EndTime: input.EndTime, Recurrence: input.Recurrence, Condition: input.Condition, Effect: effect}}); err != nil {
 			controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 			return
 		}
 	} else {
-		if err := casbin.AssignPoliciesToRole(ctx, input.RoleCode, []casbin.Policy{{Object: objectCode, Action: input.Action, BeginTime: input.BeginTime, EndTime: input.EndTime}}); err != nil {
+		if err := casbin.AssignPoliciesToRole(ctx, input.RoleCode, []casbin.Policy{{Object: objectCode, Action: input.Action, BeginTime: input.BeginTime, EndTime: input.EndTime, Recurrence: input.Recurrence, Condition: input.Condition, Effect: effect}}); err != nil {
 			controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 			return
 		}
 	}
 
-	ruleRepo := dal.NewRepo[model.TblCasbinRule]()
-	subjectPrefixed := subjectCode
-	if input.UserCode != "" {
-		subjectPrefixed = casbin.PrefixUser + subjectCode
-	} else {
-		subjectPrefixed = casbin.PrefixRole + subjectCode
-	}
-	rule, err := ruleRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("ptype = ? AND v0 = ? AND v1 = ? AND v2 = ? AND v3 = ? AND v4 = ?",
-			"p", subjectPrefixed, objectCode, input.Action,
-			input.BeginTime.Format(time.RFC3339), input.EndTime.Format(time.RFC3339))
-	})
-	if err != nil {
-		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
-		return
-	}
-	if rule == nil {
-		controller.Failure(ctx, controller.ErrSystemError.WithHint("created policy not found"))
-		return
-	}
-
 	controller.Success(ctx, permissionCreateOutput{Id: rule.Id})
 }
@@ -1,12 +1,12 @@
 package permission
 
 import (
-	"strings"
-	"time"
+	"strconv"
 
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/audit"
 	"ac/service/casbin"
 
 	"github.com/gin-gonic/gin"
@@ -15,7 +15,8 @@ import (
 )
 
 type permissionDeleteInput struct {
-	Id int64 `json:"id" binding:"required,min=1"`
+	TenantCode string `json:"tenant_code" binding:"required,len=36"`
+	Id         int64  `json:"id" binding:"required,min=1"`
 }
 
 type permissionDeleteOutput struct{}
@@ -35,8 +36,8 @@ func permissionDelete(ctx *gin.Context) {
 	// Get existing permission
 	ruleRepo := dal.NewRepo[model.TblCasbinRule]()
 	rule, err := ruleRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("id = ? AND ptype = ?", input.Id, "p")
-	})
+		return db.Where("id = ?", input.Id)
+	}, tenantRuleScope(input.TenantCode))
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
@@ -47,26 +48,36 @@ func permissionDelete(ctx *gin.Context) {
 	}
 
 	// Parse time
-	beginTime, err1 := time.Parse(time.RFC3339, rule.V3)
-	endTime, err2 := time.Parse(time.RFC3339, rule.V4)
-	if err1 != nil || err2 != nil {
+	beginTime, endTime, _, err := casbin.ParseWindow(rule.V3)
+	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithHint("invalid permission time format"))
 		return
 	}
 
-	if strings.HasPrefix(rule.V0, casbin.PrefixUser) {
-		userCode := strings.TrimPrefix(rule.V0, casbin.PrefixUser)
-		if err := casbin.RemovePoliciesFromUser(ctx, userCode, []casbin.Policy{{Object: rule.V1, Action: rule.V2, BeginTime: beginTime, EndTime: endTime}}); err != nil {
+	objectCode := casbin.RemovePrefix(rule.V1, casbin.EntityObject)
+
+	audit.RecordOnCommit(ctx, audit.Entry{
+		TenantCode:   input.TenantCode,
+		Action:       audit.ActionDelete,
+		ResourceType: audit.ResourcePermission,
+		ResourceCode: strconv.FormatInt(rule.Id, 10),
+		OldValue:     rule,
+	})
+
+	switch casbin.EntityTypeOf(rule.V0) {
+	case casbin.EntityUser:
+		userCode := casbin.RemovePrefix(rule.V0, casbin.EntityUser)
+		if err := casbin.RemovePoliciesFromUser(ctx, userCode, []casbin.Policy{{Object: objectCode, Action: rule.V2, BeginTime: beginTime, EndTime: endTime}}); err != nil {
 			controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 			return
 		}
-	} else if strings.HasPrefix(rule.V0, casbin.PrefixRole) {
-		roleCode := strings.TrimPrefix(rule.V0, casbin.PrefixRole)
-		if err := casbin.RemovePoliciesFromRole(ctx, roleCode, []casbin.Policy{{Object: rule.V1, Action: rule.V2, BeginTime: beginTime, EndTime: endTime}}); err != nil {
+	case casbin.EntityRole:
+		roleCode := casbin.RemovePrefix(rule.V0, casbin.EntityRole)
+		if err := casbin.RemovePoliciesFromRole(ctx, roleCode, []casbin.Policy{{Object: objectCode, Action: rule.V2, BeginTime: beginTime, EndTime: endTime}}); err != nil {
 			controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 			return
 		}
-	} else {
+	default:
 		controller.Failure(ctx, controller.ErrSystemError.WithHint("unknown subject prefix in rule"))
 		return
 	}
@@ -6,6 +6,7 @@ import (
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/casbin"
 
 	"github.com/gin-gonic/gin"
 	"github.com/onnttf/kit/dal"
@@ -13,7 +14,8 @@ import (
 )
 
 type permissionFetchInput struct {
-	Id int64 `form:"id" binding:"required,min=1"`
+	TenantCode string `form:"tenant_code" binding:"required,len=36"`
+	Id         int64  `form:"id" binding:"required,min=1"`
 }
 
 type permissionFetchOutput struct {
@@ -23,6 +25,9 @@ type permissionFetchOutput struct {
 	Action      string    `json:"action"`
 	BeginTime   time.Time `json:"begin_time"`
 	EndTime     time.Time `json:"end_time"`
+	Recurrence  string    `json:"recurrence"`
+	Condition   string    `json:"condition"`
+	Effect      string    `json:"effect"`
 }
 
 // @Summary Fetch a permission by ID
@@ -40,8 +45,8 @@ func permissionFetch(ctx *gin.Context) {
 	ruleRepo := dal.NewRepo[model.TblCasbinRule]()
 
 	rule, err := ruleRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("id = ? AND ptype = ?", input.Id, "p")
-	})
+		return db.Where("id = ?", input.Id)
+	}, tenantRuleScope(input.TenantCode))
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
@@ -51,9 +56,8 @@ func permissionFetch(ctx *gin.Context) {
 		return
 	}
 
-	beginTime, err1 := time.Parse(time.RFC3339, rule.V3)
-	endTime, err2 := time.Parse(time.RFC3339, rule.V4)
-	if err1 != nil || err2 != nil {
+	beginTime, endTime, recurrence, err := casbin.ParseWindow(rule.V3)
+	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithHint("invalid permission time format"))
 		return
 	}
@@ -61,9 +65,12 @@ func permissionFetch(ctx *gin.Context) {
 	controller.Success(ctx, permissionFetchOutput{
 		Id:          rule.Id,
 		SubjectCode: rule.V0,
-		ObjectCode:  rule.V1,
+		ObjectCode:  casbin.RemovePrefix(rule.V1, casbin.EntityObject),
 		Action:      rule.V2,
 		BeginTime:   beginTime,
 		EndTime:     endTime,
+		Recurrence:  recurrence,
+		Condition:   rule.V4,
+		Effect:      rule.V5,
 	})
 }
@@ -6,6 +6,7 @@ import (
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/casbin"
 
 	"github.com/gin-gonic/gin"
 	"github.com/onnttf/kit/dal"
@@ -13,8 +14,9 @@ import (
 )
 
 type permissionListInput struct {
-	Page     int `form:"page" binding:"required,min=1" default:"1"`
-	PageSize int `form:"page_size" binding:"required,min=1,max=100" default:"10"`
+	TenantCode string `form:"tenant_code" binding:"required,len=36"`
+	Page       int    `form:"page" binding:"required,min=1" default:"1"`
+	PageSize   int    `form:"page_size" binding:"required,min=1,max=100" default:"10"`
 }
 
 type permissionListOutput struct {
@@ -29,6 +31,9 @@ type permissionListItem struct {
 	Action      string    `json:"action"`
 	BeginTime   time.Time `json:"begin_time"`
 	EndTime     time.Time `json:"end_time"`
+	Recurrence  string    `json:"recurrence"`
+	Condition   string    `json:"condition"`
+	Effect      string    `json:"effect"`
 }
 
 // @Summary List policies with pagination
@@ -45,16 +50,14 @@ func permissionList(ctx *gin.Context) {
 
 	ruleRepo := dal.NewRepo[model.TblCasbinRule]()
 
-	total, err := ruleRepo.Count(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("ptype = ?", "p")
-	})
+	total, err := ruleRepo.Count(ctx, database.DB, tenantRuleScope(input.TenantCode))
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
 	}
 
-	ruleList, err := ruleRepo.Query(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("ptype = ?", "p").Order("id DESC")
+	ruleList, err := ruleRepo.Query(ctx, database.DB, tenantRuleScope(input.TenantCode), func(db *gorm.DB) *gorm.DB {
+		return db.Order("id DESC")
 	}, dal.Paginate(input.Page, input.PageSize))
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -63,18 +66,20 @@ func permissionList(ctx *gin.Context) {
 
 	list := make([]permissionListItem, 0, len(ruleList))
 	for _, rule := range ruleList {
-		beginTime, err1 := time.Parse(time.RFC3339, rule.V3)
-		endTime, err2 := time.Parse(time.RFC3339, rule.V4)
-		if err1 != nil || err2 != nil {
+		beginTime, endTime, recurrence, err := casbin.ParseWindow(rule.V3)
+		if err != nil {
 			continue
 		}
 		list = append(list, permissionListItem{
 			Id:          rule.Id,
 			SubjectCode: rule.V0,
-			ObjectCode:  rule.V1,
+			ObjectCode:  casbin.RemovePrefix(rule.V1, casbin.EntityObject),
 			Action:      rule.V2,
 			BeginTime:   beginTime,
 			EndTime:     endTime,
+			Recurrence:  recurrence,
+			Condition:   rule.V4,
+			Effect:      rule.V5,
 		})
 	}
 
@@ -6,6 +6,7 @@ import (
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/casbin"
 
 	"github.com/gin-gonic/gin"
 	"github.com/onnttf/kit/dal"
@@ -13,6 +14,7 @@ import (
 )
 
 type permissionQueryInput struct {
+	TenantCode  string `form:"tenant_code" binding:"required,len=36"`
 	Page        int    `form:"page" binding:"required,min=1" default:"1"`
 	PageSize    int    `form:"page_size" binding:"required,min=1,max=100" default:"10"`
 	SubjectCode string `form:"subject_code" binding:"omitempty,len=36"`
@@ -27,6 +29,9 @@ type permissionQueryOutput struct {
 	Action      string    `json:"action"`
 	BeginTime   time.Time `json:"begin_time"`
 	EndTime     time.Time `json:"end_time"`
+	Recurrence  string    `json:"recurrence"`
+	Condition   string    `json:"condition"`
+	Effect      string    `json:"effect"`
 }
 
 // @Summary Query policies by fields
@@ -43,13 +48,12 @@ func permissionQuery(ctx *gin.Context) {
 
 	ruleRepo := dal.NewRepo[model.TblCasbinRule]()
 
-	rule, err := ruleRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		db = db.Where("ptype = ?", "p")
+	rule, err := ruleRepo.QueryOne(ctx, database.DB, tenantRuleScope(input.TenantCode), func(db *gorm.DB) *gorm.DB {
 		if input.SubjectCode != "" {
 			db = db.Where("v0 = ?", input.SubjectCode)
 		}
 		if input.ObjectCode != "" {
-			db = db.Where("v1 = ?", input.ObjectCode)
+			db = db.Where("v1 = ?", casbin.AddPrefix(input.ObjectCode, casbin.EntityObject))
 		}
 		if input.Action != "" {
 			db = db.Where("v2 = ?", input.Action)
@@ -65,9 +69,8 @@ func permissionQuery(ctx *gin.Context) {
 		return
 	}
 
-	beginTime, err1 := time.Parse(time.RFC3339, rule.V3)
-	endTime, err2 := time.Parse(time.RFC3339, rule.V4)
-	if err1 != nil || err2 != nil {
+	beginTime, endTime, recurrence, err := casbin.ParseWindow(rule.V3)
+	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithHint("invalid permission time format"))
 		return
 	}
@@ -75,9 +78,12 @@ func permissionQuery(ctx *gin.Context) {
 	controller.Success(ctx, permissionQueryOutput{
 		Id:          rule.Id,
 		SubjectCode: rule.V0,
-		ObjectCode:  rule.V1,
+		ObjectCode:  casbin.RemovePrefix(rule.V1, casbin.EntityObject),
 		Action:      rule.V2,
 		BeginTime:   beginTime,
 		EndTime:     endTime,
+		Recurrence:  recurrence,
+		Condition:   rule.V4,
+		Effect:      rule.V5,
 	})
 }
@@ -1,12 +1,15 @@
 package permission
 
 import (
+	"errors"
+	"strconv"
 	"strings"
 	"time"
 
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/audit"
 	"ac/service/casbin"
 
 	"github.com/gin-gonic/gin"
@@ -15,15 +18,18 @@ import (
 )
 
 type permissionUpdateInput struct {
-	Id        int64     `json:"id" binding:"required,min=1"`   // permission ID
-	Action    string    `json:"action" binding:"required"`     // new operation type
-	BeginTime time.Time `json:"begin_time" binding:"required"` // new start time
-	EndTime   time.Time `json:"end_time" binding:"required"`   // new end time
+	TenantCode string    `json:"tenant_code" binding:"required,len=36"` // tenant owning the permission subject
+	Id         int64     `json:"id" binding:"required,min=1"`           // permission ID
+	Action     string    `json:"action" binding:"required"`             // new operation type
+	BeginTime  time.Time `json:"begin_time" binding:"required"`         // new start time
+	EndTime    time.Time `json:"end_time" binding:"required"`           // new end time
+	Recurrence *string   `json:"recurrence" binding:"omitempty,max=50"` // new recurrence, omit to keep, empty to clear
+	Condition  *string   `json:"condition" binding:"omitempty,max=255"` // new condition, omit to keep, empty to clear
 }
 
 type permissionUpdateOutput struct{}
 
-// @Summary Update an existing permission (action or time range)
+// @Summary Update an existing permission (action, time range, recurrence or condition)
 // @Tags permission
 // @Param input body permissionUpdateInput true "input"
 // @Success 200 {object} controller.Response{data=permissionUpdateOutput} "output"
@@ -53,8 +59,8 @@ func permissionUpdate(ctx *gin.Context) {
 	// Get the existing permission
 	ruleRepo := dal.NewRepo[model.TblCasbinRule]()
 	rule, err := ruleRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("id = ? AND ptype = ?", input.Id, "p")
-	})
+		return db.Where("id = ?", input.Id)
+	}, tenantRuleScope(input.TenantCode))
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
@@ -71,42 +77,67 @@ func permissionUpdate(ctx *gin.Context) {
 	}
 
 	// Parse old time values
-	oldBeginTime, err1 := time.Parse(time.RFC3339, rule.V3)
-	oldEndTime, err2 := time.Parse(time.RFC3339, rule.V4)
-	if err1 != nil || err2 != nil {
+	oldBeginTime, oldEndTime, recurrence, err := casbin.ParseWindow(rule.V3)
+	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithHint("Invalid time format in rule"))
 		return
 	}
 
-	if strings.HasPrefix(rule.V0, casbin.PrefixUser) {
-		userCode := strings.TrimPrefix(rule.V0, casbin.PrefixUser)
-		if err := casbin.RemovePoliciesFromUser(ctx, userCode, []casbin.Policy{{Object: rule.V1, Action: rule.V2, BeginTime: oldBeginTime, EndTime: oldEndTime}}); err != nil {
-			controller.Failure(ctx, controller.ErrSystemError.WithHint("Failed to remove old policy").WithError(err))
-			return
+	// Keep the old recurrence and condition unless new ones are given
+	if input.Recurrence != nil {
+		recurrence = casbin.NormalizeRecurrence(*input.Recurrence)
+		if recurrence != "" {
+			if _, err := casbin.ParseRecurrence(recurrence); err != nil {
+				controller.Failure(ctx, ErrInvalidRecurrence.WithError(err))
+				return
+			}
 		}
-	} else if strings.HasPrefix(rule.V0, casbin.PrefixRole) {
-		roleCode := strings.TrimPrefix(rule.V0, casbin.PrefixRole)
-		if err := casbin.RemovePoliciesFromRole(ctx, roleCode, []casbin.Policy{{Object: rule.V1, Action: rule.V2, BeginTime: oldBeginTime, EndTime: oldEndTime}}); err != nil {
-			controller.Failure(ctx, controller.ErrSystemError.WithHint("Failed to remove old policy").WithError(err))
+	}
+
+	condition := rule.V4
+	if input.Condition != nil {
+		condition = strings.TrimSpace(*input.Condition)
+		if err := casbin.ValidateCondition(condition); err != nil {
+			controller.Failure(ctx, ErrInvalidCondition.WithError(err))
 			return
 		}
-	} else {
+	}
+
+	objectCode := casbin.RemovePrefix(rule.V1, casbin.EntityObject)
+	subjectType := casbin.EntityTypeOf(rule.V0)
+	if subjectType != casbin.EntityUser && subjectType != casbin.EntityRole {
 		controller.Failure(ctx, controller.ErrSystemError.WithHint("Unknown subject prefix in rule"))
 		return
 	}
 
-	if strings.HasPrefix(rule.V0, casbin.PrefixUser) {
-		userCode := strings.TrimPrefix(rule.V0, casbin.PrefixUser)
-		if err := casbin.AssignPoliciesToUser(ctx, userCode, []casbin.Policy{{Object: rule.V1, Action: input.Action, BeginTime: input.BeginTime, EndTime: input.EndTime}}); err != nil {
-			controller.Failure(ctx, controller.ErrSystemError.WithError(err).WithHint("Failed to add new policy"))
-			return
+	// record the old and new rule within the transaction replacing one with the other
+	casbin.OnCommit(ctx, func(tx *gorm.DB) error {
+		newRule, err := ruleRepo.QueryOne(ctx, tx, func(db *gorm.DB) *gorm.DB {
+			return db.Where("ptype = ? AND v0 = ? AND v1 = ? AND v2 = ? AND v3 = ? AND v4 = ? AND v5 = ?",
+				"p", rule.V0, rule.V1, input.Action,
+				casbin.FormatWindow(input.BeginTime, input.EndTime, recurrence), condition, rule.V5)
+		})
+		if err != nil {
+			return err
 		}
-	} else {
-		roleCode := strings.TrimPrefix(rule.V0, casbin.PrefixRole)
-		if err := casbin.AssignPoliciesToRole(ctx, roleCode, []casbin.Policy{{Object: rule.V1, Action: input.Action, BeginTime: input.BeginTime, EndTime: input.EndTime}}); err != nil {
-			controller.Failure(ctx, controller.ErrSystemError.WithError(err).WithHint("Failed to add new policy"))
-			return
+		if newRule == nil {
+			return errors.New("updated policy not found")
 		}
+		return audit.Record(ctx, tx, audit.Entry{
+			TenantCode:   input.TenantCode,
+			Action:       audit.ActionUpdate,
+			ResourceType: audit.ResourcePermission,
+			ResourceCode: strconv.FormatInt(rule.Id, 10),
+			OldValue:     rule,
+			NewValue:     newRule,
+		})
+	})
+
+	oldPolicy := casbin.Policy{Object: objectCode, Action: rule.V2, BeginTime: oldBeginTime, EndTime: oldEndTime}
+	newPolicy := casbin.Policy{Object: objectCode, Action: input.Action, BeginTime: input.BeginTime, EndTime: input.EndTime, Recurrence: recurrence, Condition: condition, Effect: rule.V5}
+	if err := casbin.UpdatePolicy(ctx, casbin.RemovePrefix(rule.V0, subjectType), subjectType, oldPolicy, newPolicy); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err).WithHint("Failed to update policy"))
+		return
 	}
 
 	controller.Success(ctx, permissionUpdateOutput{})
@@ -0,0 +1,22 @@
+package permission
+
+import (
+	"ac/bootstrap/database"
+	"ac/model"
+	"ac/service/casbin"
+
+	"gorm.io/gorm"
+)
+
+// tenantRuleScope restricts p rules to those whose subject is a user or role of the tenant.
+func tenantRuleScope(tenantCode string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		userPrefix := database.Literal(casbin.PrefixUser + casbin.PrefixSeparator)
+		rolePrefix := database.Literal(casbin.PrefixRole + casbin.PrefixSeparator)
+		subjects := db.Session(&gorm.Session{NewDB: true}).Model(&model.TblSubject{}).
+			Select(database.Concat(db, "CASE type WHEN ? THEN "+userPrefix+" ELSE "+rolePrefix+" END", "code"),
+				model.SubjectTypeUser).
+			Where("tenant_code = ?", tenantCode)
+		return db.Where("ptype = ? AND v0 IN (?)", "p", subjects)
+	}
+}
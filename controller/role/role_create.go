@@ -1,11 +1,16 @@
 package role
 
 import (
+	"errors"
 	"time"
 
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
+	roleService "ac/service/role"
+	"ac/service/tenant"
 	"ac/util"
 
 	"github.com/gin-gonic/gin"
@@ -14,6 +19,7 @@ import (
 )
 
 type roleCreateInput struct {
+	TenantCode string `json:"tenant_code" binding:"required,len=36"`
 	Name       string `json:"name" binding:"required,min=1,max=50"`
 	ParentCode string `json:"parent_code" binding:"omitempty,len=36"`
 }
@@ -35,10 +41,16 @@ func roleCreate(ctx *gin.Context) {
 		return
 	}
 
+	if err := tenant.Verify(ctx, input.TenantCode); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithHint("tenant not found").WithError(err))
+		return
+	}
+
 	now := time.Now()
 	newValue := &model.TblSubject{
 		Type:       model.SubjectTypeRole,
 		Code:       util.GenerateCode(),
+		TenantCode: input.TenantCode,
 		Name:       input.Name,
 		ParentCode: input.ParentCode,
 		Sort:       1,
@@ -50,16 +62,12 @@ func roleCreate(ctx *gin.Context) {
 
 	roleRepo := dal.NewRepo[model.TblSubject]()
 	if input.ParentCode != "" {
-
-		parent, err := roleRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-			return db.Where("code = ? AND type = ? AND deleted = ?", input.ParentCode, model.SubjectTypeRole, model.NotDeleted)
-		})
-		if err != nil {
-			controller.Failure(ctx, controller.ErrSystemError.WithHint("parent role not found"))
-			return
-		}
-		if parent == nil {
-			controller.Failure(ctx, controller.ErrSystemError.WithHint("parent role not found"))
+		if err := roleService.VerifyParent(ctx, database.DB, input.TenantCode, "", input.ParentCode); err != nil {
+			if errors.Is(err, roleService.ErrParentNotFound) {
+				controller.Failure(ctx, controller.ErrInvalidInput.WithHint("parent role not found"))
+				return
+			}
+			controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 			return
 		}
 
@@ -75,12 +83,39 @@ func roleCreate(ctx *gin.Context) {
 		}
 	}
 
-	if err := roleRepo.Insert(ctx, database.DB, newValue); err != nil {
-
+	// insert the role and its inheritance link together
+	var changed *casbin.ChangedRules
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := roleRepo.Insert(ctx, tx, newValue); err != nil {
+			return err
+		}
+		if err := audit.Record(ctx, tx, audit.Entry{
+			TenantCode:   newValue.TenantCode,
+			Action:       audit.ActionCreate,
+			ResourceType: audit.ResourceRole,
+			ResourceCode: newValue.Code,
+			NewValue:     newValue,
+		}); err != nil {
+			return err
+		}
+		if input.ParentCode == "" {
+			return nil
+		}
+		var err error
+		changed, err = casbin.SetRoleParent(ctx, tx, input.TenantCode, newValue.Code, input.ParentCode)
+		return err
+	}); err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
 	}
 
+	if changed != nil {
+		if err := casbin.SyncChangedRules(ctx, changed); err != nil {
+			controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+			return
+		}
+	}
+
 	controller.Success(ctx, roleCreateOutput{
 		Code: newValue.Code,
 	})
@@ -6,6 +6,9 @@ import (
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
+	roleService "ac/service/role"
 
 	"github.com/gin-gonic/gin"
 	"github.com/onnttf/kit/dal"
@@ -13,10 +16,14 @@ import (
 )
 
 type roleDeleteInput struct {
-	Code string `json:"code" binding:"required,len=36"`
+	TenantCode string `json:"tenant_code" binding:"required,len=36"`
+	Code       string `json:"code" binding:"required,len=36"`
 }
 
-type roleDeleteOutput struct{}
+type roleDeleteOutput struct {
+	RemovedRoleLinks int64 `json:"removed_role_links"`
+	RemovedPolicies  int64 `json:"removed_policies"`
+}
 
 // @Summary Delete an existing role
 // @Tags role
@@ -33,7 +40,7 @@ func roleDelete(ctx *gin.Context) {
 	roleRepo := dal.NewRepo[model.TblSubject]()
 
 	role, err := roleRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("code = ? AND type = ?", input.Code, model.SubjectTypeRole)
+		return db.Where("code = ? AND tenant_code = ? AND type = ? AND deleted = ?", input.Code, input.TenantCode, model.SubjectTypeRole, model.NotDeleted)
 	})
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -44,15 +51,52 @@ func roleDelete(ctx *gin.Context) {
 		return
 	}
 
+	hasChildren, err := roleService.HasChildren(ctx, database.DB, role.Code)
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+	if hasChildren {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithHint("role has child roles, move or delete them first"))
+		return
+	}
+
+	oldValue := *role
 	role.Deleted = model.Deleted
 	role.UpdatedAt = time.Now()
 
-	if err := roleRepo.Update(ctx, database.DB, role, func(db *gorm.DB) *gorm.DB {
-		return db.Where("code = ? AND type = ?", input.Code, model.SubjectTypeRole)
+	// delete the role together with its parent link, member links and policies
+	var removed *casbin.RemovedRules
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := roleRepo.Update(ctx, tx, role, func(db *gorm.DB) *gorm.DB {
+			return db.Where("code = ? AND type = ?", input.Code, model.SubjectTypeRole)
+		}); err != nil {
+			return err
+		}
+		if err := audit.Record(ctx, tx, audit.Entry{
+			TenantCode:   role.TenantCode,
+			Action:       audit.ActionDelete,
+			ResourceType: audit.ResourceRole,
+			ResourceCode: role.Code,
+			OldValue:     oldValue,
+		}); err != nil {
+			return err
+		}
+		var err error
+		removed, err = casbin.RemoveEntityRules(ctx, tx, role.Code, casbin.EntityRole)
+		return err
 	}); err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
 	}
 
-	controller.Success(ctx, roleDeleteOutput{})
+	if err := casbin.SyncRemovedRules(ctx, removed); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	controller.Success(ctx, roleDeleteOutput{
+		RemovedRoleLinks: removed.RoleLinks,
+		RemovedPolicies:  removed.Policies,
+	})
 }
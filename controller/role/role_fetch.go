@@ -11,12 +11,14 @@ import (
 )
 
 type roleFetchInput struct {
-	Code string `json:"code" binding:"required,len=36"`
+	TenantCode string `json:"tenant_code" binding:"required,len=36"`
+	Code       string `json:"code" binding:"required,len=36"`
 }
 
 type roleFetchOutput struct {
-	Code string `json:"code"`
-	Name string `json:"name"`
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Status int64  `json:"status"`
 }
 
 // @Summary Fetch a role by code
@@ -34,7 +36,7 @@ func roleFetch(ctx *gin.Context) {
 	roleRepo := dal.NewRepo[model.TblSubject]()
 
 	role, err := roleRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("code = ? AND type = ? AND deleted = ?", input.Code, model.SubjectTypeRole, model.NotDeleted)
+		return db.Where("code = ? AND tenant_code = ? AND type = ? AND deleted = ?", input.Code, input.TenantCode, model.SubjectTypeRole, model.NotDeleted)
 	})
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -46,7 +48,8 @@ func roleFetch(ctx *gin.Context) {
 	}
 
 	controller.Success(ctx, roleFetchOutput{
-		Code: role.Code,
-		Name: role.Name,
+		Code:   role.Code,
+		Name:   role.Name,
+		Status: role.Status,
 	})
 }
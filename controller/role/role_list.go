@@ -11,8 +11,10 @@ import (
 )
 
 type roleListInput struct {
-	Page     int `form:"page" binding:"required,min=1" default:"1"`
-	PageSize int `form:"page_size" binding:"required,min=1,max=100" default:"10"`
+	TenantCode string `form:"tenant_code" binding:"required,len=36"`
+	Page       int    `form:"page" binding:"required,min=1" default:"1"`
+	PageSize   int    `form:"page_size" binding:"required,min=1,max=100" default:"10"`
+	Deleted    bool   `form:"deleted"` // list deleted roles instead, to find ones to restore
 }
 
 type roleListOutput struct {
@@ -21,10 +23,11 @@ type roleListOutput struct {
 }
 
 type roleListItem struct {
-	Id   int64             `json:"id"`
-	Code string            `json:"code"`
-	Name string            `json:"name"`
-	Type model.SubjectType `json:"type"`
+	Id     int64             `json:"id"`
+	Code   string            `json:"code"`
+	Name   string            `json:"name"`
+	Type   model.SubjectType `json:"type"`
+	Status int64             `json:"status"`
 }
 
 // @Summary List roles with pagination
@@ -39,10 +42,15 @@ func roleList(ctx *gin.Context) {
 		return
 	}
 
+	deleted := model.NotDeleted
+	if input.Deleted {
+		deleted = model.Deleted
+	}
+
 	roleRepo := dal.NewRepo[model.TblSubject]()
 
 	total, err := roleRepo.Count(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("type = ? AND deleted = ?", model.SubjectTypeRole, model.NotDeleted)
+		return db.Where("type = ? AND tenant_code = ? AND deleted = ?", model.SubjectTypeRole, input.TenantCode, deleted)
 	})
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -50,7 +58,7 @@ func roleList(ctx *gin.Context) {
 	}
 
 	roleList, err := roleRepo.Query(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("type = ? AND deleted = ?", model.SubjectTypeRole, model.NotDeleted).Order("id DESC")
+		return db.Where("type = ? AND tenant_code = ? AND deleted = ?", model.SubjectTypeRole, input.TenantCode, deleted).Order("id DESC")
 	}, dal.Paginate(input.Page, input.PageSize))
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -60,10 +68,11 @@ func roleList(ctx *gin.Context) {
 	list := make([]roleListItem, len(roleList))
 	for i, v := range roleList {
 		list[i] = roleListItem{
-			Id:   v.Id,
-			Code: v.Code,
-			Name: v.Name,
-			Type: v.Type,
+			Id:     v.Id,
+			Code:   v.Code,
+			Name:   v.Name,
+			Type:   v.Type,
+			Status: v.Status,
 		}
 	}
 
@@ -11,9 +11,10 @@ import (
 )
 
 type roleQueryInput struct {
-	Page     int    `form:"page" binding:"required,min=1" default:"1"`
-	PageSize int    `form:"page_size" binding:"required,min=1,max=100" default:"10"`
-	Name     string `form:"name" binding:"omitempty,min=1"`
+	TenantCode string `form:"tenant_code" binding:"required,len=36"`
+	Page       int    `form:"page" binding:"required,min=1" default:"1"`
+	PageSize   int    `form:"page_size" binding:"required,min=1,max=100" default:"10"`
+	Name       string `form:"name" binding:"omitempty,min=1"`
 }
 
 type roleQueryOutput struct {
@@ -37,7 +38,7 @@ func roleQuery(ctx *gin.Context) {
 	roleRepo := dal.NewRepo[model.TblSubject]()
 
 	role, err := roleRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		query := db.Where("type = ? AND deleted = ?", model.SubjectTypeRole, model.NotDeleted)
+		query := db.Where("type = ? AND tenant_code = ? AND deleted = ?", model.SubjectTypeRole, input.TenantCode, model.NotDeleted)
 		if input.Name != "" {
 			query = query.Where("name LIKE ?", "%"+input.Name+"%")
 		}
@@ -0,0 +1,117 @@
+package role
+
+import (
+	"errors"
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/controller"
+	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
+	roleService "ac/service/role"
+
+	"github.com/gin-gonic/gin"
+	"github.com/onnttf/kit/dal"
+	"gorm.io/gorm"
+)
+
+type roleRestoreInput struct {
+	TenantCode   string `json:"tenant_code" binding:"required,len=36"`
+	Code         string `json:"code" binding:"required,len=36"`
+	RestoreRules bool   `json:"restore_rules"` // also re-create the member links and policies removed on delete
+}
+
+type roleRestoreOutput struct {
+	RestoredRoleLinks int64 `json:"restored_role_links"`
+	RestoredPolicies  int64 `json:"restored_policies"`
+	SkippedRules      int64 `json:"skipped_rules"`
+}
+
+// @Summary Restore a deleted role
+// @Tags role
+// @Param input body roleRestoreInput true "input"
+// @Success 200 {object} controller.Response{data=roleRestoreOutput} "output"
+// @Router /api/role/restore [post]
+func roleRestore(ctx *gin.Context) {
+	var input roleRestoreInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	roleRepo := dal.NewRepo[model.TblSubject]()
+
+	role, err := roleRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+		return db.Where("code = ? AND tenant_code = ? AND type = ? AND deleted = ?", input.Code, input.TenantCode, model.SubjectTypeRole, model.Deleted)
+	})
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+	if role == nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithMsg("deleted role not found"))
+		return
+	}
+
+	if err := roleService.VerifyParent(ctx, database.DB, role.TenantCode, role.Code, role.ParentCode); err != nil {
+		if errors.Is(err, roleService.ErrParentNotFound) {
+			controller.Failure(ctx, controller.ErrInvalidInput.WithHint("parent role is deleted, restore it first"))
+			return
+		}
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	now := time.Now()
+	newValue := map[string]any{
+		"deleted":    model.NotDeleted,
+		"updated_at": now,
+	}
+
+	// restore the role together with its parent link and, if asked, its members and policies
+	var restored *casbin.RestoredRules
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Deleted goes back to zero, which a struct update would skip
+		if err := roleRepo.UpdateFields(ctx, tx, newValue, func(db *gorm.DB) *gorm.DB {
+			return db.Where("code = ? AND type = ?", role.Code, model.SubjectTypeRole)
+		}); err != nil {
+			return err
+		}
+		restoredRole := *role
+		restoredRole.Deleted = model.NotDeleted
+		restoredRole.UpdatedAt = now
+		if err := audit.Record(ctx, tx, audit.Entry{
+			TenantCode:   role.TenantCode,
+			Action:       audit.ActionRestore,
+			ResourceType: audit.ResourceRole,
+			ResourceCode: role.Code,
+			OldValue:     role,
+			NewValue:     restoredRole,
+		}); err != nil {
+			return err
+		}
+		var err error
+		restored, err = casbin.RestoreEntityRules(ctx, tx, role.TenantCode, role.Code, casbin.EntityRole, role.ParentCode, input.RestoreRules)
+		return err
+	}); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	if err := casbin.SyncRestoredRules(ctx, restored); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	if err := casbin.SetEntityStatus(ctx, role.Code, casbin.EntityRole, model.StatusFlag(role.Status)); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	controller.Success(ctx, roleRestoreOutput{
+		RestoredRoleLinks: restored.RoleLinks,
+		RestoredPolicies:  restored.Policies,
+		SkippedRules:      restored.Skipped,
+	})
+}
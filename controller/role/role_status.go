@@ -0,0 +1,103 @@
+package role
+
+import (
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/controller"
+	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
+
+	"github.com/gin-gonic/gin"
+	"github.com/onnttf/kit/dal"
+	"gorm.io/gorm"
+)
+
+type roleStatusInput struct {
+	TenantCode string `json:"tenant_code" binding:"required,len=36"`
+	Code       string `json:"code" binding:"required,len=36"`
+}
+
+type roleStatusOutput struct{}
+
+// @Summary Enable a role, restoring the access it grants to its members
+// @Tags role
+// @Param input body roleStatusInput true "input"
+// @Success 200 {object} controller.Response{data=roleStatusOutput} "output"
+// @Router /api/role/enable [post]
+func roleEnable(ctx *gin.Context) {
+	setRoleStatus(ctx, model.StatusEnabled)
+}
+
+// @Summary Disable a role, blocking the access it grants without removing grants or members
+// @Tags role
+// @Param input body roleStatusInput true "input"
+// @Success 200 {object} controller.Response{data=roleStatusOutput} "output"
+// @Router /api/role/disable [post]
+func roleDisable(ctx *gin.Context) {
+	setRoleStatus(ctx, model.StatusDisabled)
+}
+
+func setRoleStatus(ctx *gin.Context, status model.StatusFlag) {
+	var input roleStatusInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	roleRepo := dal.NewRepo[model.TblSubject]()
+
+	role, err := roleRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+		return db.Where("code = ? AND tenant_code = ? AND type = ? AND deleted = ?", input.Code, input.TenantCode, model.SubjectTypeRole, model.NotDeleted)
+	})
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+	if role == nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithMsg("role not found"))
+		return
+	}
+
+	now := time.Now()
+	newValue := map[string]any{
+		"status":     status.Int64(),
+		"updated_at": now,
+	}
+
+	updatedRole := *role
+	updatedRole.Status = status.Int64()
+	updatedRole.UpdatedAt = now
+	action := audit.ActionEnable
+	if status == model.StatusDisabled {
+		action = audit.ActionDisable
+	}
+
+	// update the status and record the change together
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := roleRepo.UpdateFields(ctx, tx, newValue, func(db *gorm.DB) *gorm.DB {
+			return db.Where("code = ? AND type = ?", role.Code, model.SubjectTypeRole)
+		}); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Entry{
+			TenantCode:   role.TenantCode,
+			Action:       action,
+			ResourceType: audit.ResourceRole,
+			ResourceCode: role.Code,
+			OldValue:     role,
+			NewValue:     updatedRole,
+		})
+	}); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	if err := casbin.SetEntityStatus(ctx, role.Code, casbin.EntityRole, status); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	controller.Success(ctx, roleStatusOutput{})
+}
@@ -0,0 +1,124 @@
+package role
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"ac/bootstrap/bootstraptest"
+	"ac/bootstrap/database"
+	"ac/controller"
+	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
+	"ac/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(bootstraptest.Main(m, casbin.Initialize))
+}
+
+// createRole creates a role through the API and returns its code.
+func createRole(t *testing.T, tenantCode, name, parentCode string) string {
+	t.Helper()
+
+	var output roleCreateOutput
+	response := bootstraptest.Post(t, RegisterRoutes, "/role/create", roleCreateInput{TenantCode: tenantCode, Name: name, ParentCode: parentCode}, &output)
+	if response.Code != 0 {
+		t.Fatalf("create role %s: code=%d, hint=%s, err=%s", name, response.Code, response.Hint, response.Err)
+	}
+	return output.Code
+}
+
+// storedRoleParents returns the parents the database links a role to.
+func storedRoleParents(t *testing.T, roleCode string) []string {
+	t.Helper()
+
+	var parents []string
+	if err := database.DB.Model(&model.TblCasbinRule{}).
+		Where("ptype = ? AND v0 = ?", casbin.GroupingUserRole, casbin.AddPrefix(roleCode, casbin.EntityRole)).
+		Pluck("v1", &parents).Error; err != nil {
+		t.Fatalf("query parents: %v", err)
+	}
+	return parents
+}
+
+func TestRoleParentSync(t *testing.T) {
+	tenantCode := bootstraptest.NewTenant(t)
+	parent := createRole(t, tenantCode, "parent", "")
+	other := createRole(t, tenantCode, "other", "")
+	child := createRole(t, tenantCode, "child", parent)
+
+	if parents := storedRoleParents(t, child); !slices.Equal(parents, []string{casbin.AddPrefix(parent, casbin.EntityRole)}) {
+		t.Fatalf("stored parents after create = %v, want %s", parents, parent)
+	}
+
+	ctx := bootstraptest.NewContext()
+	userCode := util.GenerateCode()
+	if err := casbin.AssignRolesToUser(ctx, tenantCode, userCode, []string{child}); err != nil {
+		t.Fatalf("assign role: %v", err)
+	}
+	if roles, _ := casbin.GetRolesForUser(ctx, tenantCode, userCode); !slices.Contains(roles, parent) {
+		t.Fatalf("roles after create = %v, want inherited %s", roles, parent)
+	}
+
+	// Move the child under another parent
+	if response := bootstraptest.Post(t, RegisterRoutes, "/role/update", roleUpdateInput{TenantCode: tenantCode, Code: child, Name: "child", ParentCode: &other}, nil); response.Code != 0 {
+		t.Fatalf("update parent: code=%d, hint=%s, err=%s", response.Code, response.Hint, response.Err)
+	}
+	if parents := storedRoleParents(t, child); !slices.Equal(parents, []string{casbin.AddPrefix(other, casbin.EntityRole)}) {
+		t.Fatalf("stored parents after update = %v, want %s", parents, other)
+	}
+	roles, _ := casbin.GetRolesForUser(ctx, tenantCode, userCode)
+	if !slices.Contains(roles, other) || slices.Contains(roles, parent) {
+		t.Fatalf("roles after update = %v, want %s without %s", roles, other, parent)
+	}
+
+	// A parent may not inherit from its child
+	response := bootstraptest.Post(t, RegisterRoutes, "/role/update", roleUpdateInput{TenantCode: tenantCode, Code: other, Name: "other", ParentCode: &child}, nil)
+	if response.Code != controller.ErrInvalidInput.Code {
+		t.Fatalf("cycle update: code=%d, want %d", response.Code, controller.ErrInvalidInput.Code)
+	}
+	if parents := storedRoleParents(t, other); len(parents) != 0 {
+		t.Fatalf("stored parents after rejected update = %v, want none", parents)
+	}
+
+	// Detach the child
+	empty := ""
+	if response := bootstraptest.Post(t, RegisterRoutes, "/role/update", roleUpdateInput{TenantCode: tenantCode, Code: child, Name: "child", ParentCode: &empty}, nil); response.Code != 0 {
+		t.Fatalf("detach parent: code=%d, hint=%s, err=%s", response.Code, response.Hint, response.Err)
+	}
+	if parents := storedRoleParents(t, child); len(parents) != 0 {
+		t.Fatalf("stored parents after detach = %v, want none", parents)
+	}
+	if roles, _ := casbin.GetRolesForUser(ctx, tenantCode, userCode); !slices.Equal(roles, []string{child}) {
+		t.Fatalf("roles after detach = %v, want only %s", roles, child)
+	}
+}
+
+func TestDeletedRoleNotFound(t *testing.T) {
+	tenantCode := bootstraptest.NewTenant(t)
+	role := createRole(t, tenantCode, "role", "")
+
+	for range 2 {
+		bootstraptest.Post(t, RegisterRoutes, "/role/delete", roleDeleteInput{TenantCode: tenantCode, Code: role}, nil)
+	}
+	var audits int64
+	if err := database.DB.Model(&model.TblAuditLog{}).
+		Where("resource_code = ? AND action = ?", role, audit.ActionDelete).
+		Count(&audits).Error; err != nil {
+		t.Fatalf("count audit entries: %v", err)
+	}
+	if audits != 1 {
+		t.Fatalf("audit entries after deleting twice = %d, want 1", audits)
+	}
+
+	// A deleted role can no longer be updated
+	response := bootstraptest.Post(t, RegisterRoutes, "/role/update", roleUpdateInput{TenantCode: tenantCode, Code: role, Name: "renamed"}, nil)
+	if response.Code != controller.ErrInvalidInput.Code {
+		t.Fatalf("update deleted role: code=%d, want %d", response.Code, controller.ErrInvalidInput.Code)
+	}
+}
@@ -1,11 +1,15 @@
 package role
 
 import (
+	"errors"
 	"time"
 
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
+	roleService "ac/service/role"
 
 	"github.com/gin-gonic/gin"
 	"github.com/onnttf/kit/dal"
@@ -13,8 +17,10 @@ import (
 )
 
 type roleUpdateInput struct {
-	Code string `json:"code" binding:"required,len=36"`
-	Name string `json:"name" binding:"required,min=1,max=50"`
+	TenantCode string  `json:"tenant_code" binding:"required,len=36"`
+	Code       string  `json:"code" binding:"required,len=36"`
+	Name       string  `json:"name" binding:"required,min=1,max=50"`
+	ParentCode *string `json:"parent_code" binding:"omitempty"`
 }
 
 type roleUpdateOutput struct{}
@@ -31,10 +37,16 @@ func roleUpdate(ctx *gin.Context) {
 		return
 	}
 
+	// parent_code is optional; an empty string moves the role to the top level
+	if input.ParentCode != nil && *input.ParentCode != "" && len(*input.ParentCode) != 36 {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithHint("parent_code must be empty or 36 characters"))
+		return
+	}
+
 	roleRepo := dal.NewRepo[model.TblSubject]()
 
 	role, err := roleRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("code = ? AND type = ?", input.Code, model.SubjectTypeRole)
+		return db.Where("code = ? AND tenant_code = ? AND type = ? AND deleted = ?", input.Code, input.TenantCode, model.SubjectTypeRole, model.NotDeleted)
 	})
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -44,16 +56,66 @@ func roleUpdate(ctx *gin.Context) {
 		controller.Failure(ctx, controller.ErrInvalidInput.WithMsg("role not found"))
 		return
 	}
+	oldValue := *role
+
+	parentChanged := input.ParentCode != nil && *input.ParentCode != role.ParentCode
+	if parentChanged {
+		if err := roleService.VerifyParent(ctx, database.DB, role.TenantCode, role.Code, *input.ParentCode); err != nil {
+			switch {
+			case errors.Is(err, roleService.ErrParentNotFound):
+				controller.Failure(ctx, controller.ErrInvalidInput.WithHint("parent role not found"))
+			case errors.Is(err, roleService.ErrHierarchyCycle):
+				controller.Failure(ctx, controller.ErrInvalidInput.WithHint("parent role would create a cycle"))
+			default:
+				controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+			}
+			return
+		}
+		role.ParentCode = *input.ParentCode
+	}
 
 	role.Name = input.Name
 	role.UpdatedAt = time.Now()
 
-	if err := roleRepo.Update(ctx, database.DB, role, func(db *gorm.DB) *gorm.DB {
-		return db.Where("code = ? AND type = ?", input.Code, model.SubjectTypeRole)
+	// update the role and its inheritance link together
+	var changed *casbin.ChangedRules
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := roleRepo.Update(ctx, tx, role, func(db *gorm.DB) *gorm.DB {
+			return db.Where("code = ? AND type = ?", input.Code, model.SubjectTypeRole)
+		}); err != nil {
+			return err
+		}
+		if err := audit.Record(ctx, tx, audit.Entry{
+			TenantCode:   role.TenantCode,
+			Action:       audit.ActionUpdate,
+			ResourceType: audit.ResourceRole,
+			ResourceCode: role.Code,
+			OldValue:     oldValue,
+			NewValue:     role,
+		}); err != nil {
+			return err
+		}
+		if !parentChanged {
+			return nil
+		}
+		var err error
+		changed, err = casbin.SetRoleParent(ctx, tx, role.TenantCode, role.Code, role.ParentCode)
+		return err
 	}); err != nil {
+		if errors.Is(err, casbin.ErrHierarchyCycle) {
+			controller.Failure(ctx, controller.ErrInvalidInput.WithHint("parent role would create a cycle"))
+			return
+		}
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
 	}
 
+	if changed != nil {
+		if err := casbin.SyncChangedRules(ctx, changed); err != nil {
+			controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+			return
+		}
+	}
+
 	controller.Success(ctx, roleUpdateOutput{})
 }
@@ -12,7 +12,8 @@ import (
 )
 
 type roleUserInput struct {
-	RoleCode string `form:"role_code" binding:"required,len=36"`
+	TenantCode string `form:"tenant_code" binding:"required,len=36"`
+	RoleCode   string `form:"role_code" binding:"required,len=36"`
 }
 
 type roleUserOutput struct {
@@ -35,7 +36,7 @@ func roleUser(ctx *gin.Context) {
 	// Validate RoleCode existence
 	roleRepo := dal.NewRepo[model.TblSubject]()
 	role, err := roleRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("code = ? AND type = ? AND deleted = ?", input.RoleCode, model.SubjectTypeRole, model.NotDeleted)
+		return db.Where("code = ? AND tenant_code = ? AND type = ? AND deleted = ?", input.RoleCode, input.TenantCode, model.SubjectTypeRole, model.NotDeleted)
 	})
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -46,7 +47,7 @@ func roleUser(ctx *gin.Context) {
 		return
 	}
 
-	userCodes, err := casbin.GetUsersForRole(ctx, input.RoleCode)
+	userCodes, err := casbin.GetUsersForRole(ctx, input.TenantCode, input.RoleCode)
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
@@ -6,6 +6,7 @@ import (
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/audit"
 	"ac/service/casbin"
 
 	"github.com/gin-gonic/gin"
@@ -14,8 +15,9 @@ import (
 )
 
 type roleUserAssignInput struct {
-	RoleCode  string   `json:"role_code" binding:"required,len=36"`
-	UserCodes []string `json:"user_codes" binding:"required,min=1,dive,len=36"`
+	TenantCode string   `json:"tenant_code" binding:"required,len=36"`
+	RoleCode   string   `json:"role_code" binding:"required,len=36"`
+	UserCodes  []string `json:"user_codes" binding:"required,min=1,dive,len=36"`
 }
 
 type roleUserAssignOutput struct{}
@@ -35,7 +37,7 @@ func roleUserAssign(ctx *gin.Context) {
 	// Validate RoleCode existence
 	roleRepo := dal.NewRepo[model.TblSubject]()
 	role, err := roleRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("code = ? AND type = ? AND deleted = ?", input.RoleCode, model.SubjectTypeRole, model.NotDeleted)
+		return db.Where("code = ? AND tenant_code = ? AND type = ? AND deleted = ?", input.RoleCode, input.TenantCode, model.SubjectTypeRole, model.NotDeleted)
 	})
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -46,7 +48,7 @@ func roleUserAssign(ctx *gin.Context) {
 		return
 	}
 
-	assignedUsers, err := casbin.GetUsersForRole(ctx, input.RoleCode)
+	assignedUsers, err := casbin.GetUsersForRole(ctx, input.TenantCode, input.RoleCode)
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
@@ -63,7 +65,7 @@ func roleUserAssign(ctx *gin.Context) {
 	// Validate that each user exists and is not deleted using batch query
 	userRepo := dal.NewRepo[model.TblSubject]()
 	users, err := userRepo.Query(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("code IN ? AND type = ? AND deleted = ?", input.UserCodes, model.SubjectTypeUser, model.NotDeleted)
+		return db.Where("code IN ? AND tenant_code = ? AND type = ? AND deleted = ?", input.UserCodes, input.TenantCode, model.SubjectTypeUser, model.NotDeleted)
 	})
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -83,7 +85,18 @@ func roleUserAssign(ctx *gin.Context) {
 		}
 	}
 
-	if err := casbin.AssignUsersToRole(ctx, input.RoleCode, input.UserCodes); err != nil {
+	newUsers := append(slices.Clone(assignedUsers), input.UserCodes...)
+	slices.Sort(newUsers)
+	audit.RecordOnCommit(ctx, audit.Entry{
+		TenantCode:   input.TenantCode,
+		Action:       audit.ActionAssign,
+		ResourceType: audit.ResourceRole,
+		ResourceCode: input.RoleCode,
+		OldValue:     map[string]any{"user_codes": assignedUsers},
+		NewValue:     map[string]any{"user_codes": newUsers},
+	})
+
+	if err := casbin.AssignUsersToRole(ctx, input.TenantCode, input.RoleCode, input.UserCodes); err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
 	}
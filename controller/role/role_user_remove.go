@@ -7,6 +7,7 @@ import (
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/audit"
 	"ac/service/casbin"
 
 	"github.com/gin-gonic/gin"
@@ -15,8 +16,9 @@ import (
 )
 
 type roleUserRemoveInput struct {
-	RoleCode  string   `json:"role_code" binding:"required,len=36"`
-	UserCodes []string `json:"user_codes" binding:"required,min=1,dive,len=36"`
+	TenantCode string   `json:"tenant_code" binding:"required,len=36"`
+	RoleCode   string   `json:"role_code" binding:"required,len=36"`
+	UserCodes  []string `json:"user_codes" binding:"required,min=1,dive,len=36"`
 }
 
 type roleUserRemoveOutput struct{}
@@ -36,7 +38,7 @@ func roleUserRemove(ctx *gin.Context) {
 	// Step 1: Validate RoleCode existence
 	roleRepo := dal.NewRepo[model.TblSubject]()
 	role, err := roleRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("code = ? AND type = ? AND deleted = ?", input.RoleCode, model.SubjectTypeRole, model.NotDeleted)
+		return db.Where("code = ? AND tenant_code = ? AND type = ? AND deleted = ?", input.RoleCode, input.TenantCode, model.SubjectTypeRole, model.NotDeleted)
 	})
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -50,7 +52,7 @@ func roleUserRemove(ctx *gin.Context) {
 	// Step 2: Validate that all users are valid (not deleted)
 	userRepo := dal.NewRepo[model.TblSubject]()
 	users, err := userRepo.Query(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Where("code IN ? AND type = ? AND deleted = ?", input.UserCodes, model.SubjectTypeUser, model.NotDeleted)
+		return db.Where("code IN ? AND tenant_code = ? AND type = ? AND deleted = ?", input.UserCodes, input.TenantCode, model.SubjectTypeUser, model.NotDeleted)
 	})
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
@@ -76,7 +78,7 @@ func roleUserRemove(ctx *gin.Context) {
 		return
 	}
 
-	assignedUsers, err := casbin.GetUsersForRole(ctx, input.RoleCode)
+	assignedUsers, err := casbin.GetUsersForRole(ctx, input.TenantCode, input.RoleCode)
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
@@ -96,7 +98,19 @@ func roleUserRemove(ctx *gin.Context) {
 		return
 	}
 
-	if err := casbin.RemoveUsersFromRole(ctx, input.RoleCode, input.UserCodes); err != nil {
+	newUsers := slices.DeleteFunc(slices.Clone(assignedUsers), func(userCode string) bool {
+		return slices.Contains(input.UserCodes, userCode)
+	})
+	audit.RecordOnCommit(ctx, audit.Entry{
+		TenantCode:   input.TenantCode,
+		Action:       audit.ActionRemove,
+		ResourceType: audit.ResourceRole,
+		ResourceCode: input.RoleCode,
+		OldValue:     map[string]any{"user_codes": assignedUsers},
+		NewValue:     map[string]any{"user_codes": newUsers},
+	})
+
+	if err := casbin.RemoveUsersFromRole(ctx, input.TenantCode, input.RoleCode, input.UserCodes); err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
 	}
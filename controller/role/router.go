@@ -11,6 +11,9 @@ func RegisterRoutes(api *gin.RouterGroup) {
 	router.POST("/create", roleCreate)
 	router.POST("/update", roleUpdate)
 	router.POST("/delete", roleDelete)
+	router.POST("/enable", roleEnable)
+	router.POST("/disable", roleDisable)
+	router.POST("/restore", roleRestore)
 	router.GET("/fetch", roleFetch)
 	router.GET("/list", roleList)
 	router.GET("/query", roleQuery)
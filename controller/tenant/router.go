@@ -0,0 +1,14 @@
+package tenant
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes registers tenant-related routes.
+func RegisterRoutes(api *gin.RouterGroup) {
+	router := api.Group("/tenant")
+
+	router.POST("/create", tenantCreate)
+	router.GET("/list", tenantList)
+	router.POST("/admin/seed", tenantAdminSeed)
+}
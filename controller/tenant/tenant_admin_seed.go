@@ -0,0 +1,122 @@
+package tenant
+
+import (
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/controller"
+	"ac/model"
+	"ac/service/casbin"
+	tenantService "ac/service/tenant"
+	"ac/util"
+
+	"github.com/gin-gonic/gin"
+	"github.com/onnttf/kit/dal"
+	"gorm.io/gorm"
+)
+
+// tenantAdminRoleName is the name of the role created for seeded tenant admins.
+const tenantAdminRoleName = "admin"
+
+type tenantAdminSeedInput struct {
+	TenantCode string    `json:"tenant_code" binding:"required,len=36"`
+	UserName   string    `json:"user_name" binding:"required,min=6,max=50" example:"Administrator"`
+	Actions    []string  `json:"actions" binding:"required,min=1,dive,min=1,max=50"`
+	BeginTime  time.Time `json:"begin_time" binding:"required"`
+	EndTime    time.Time `json:"end_time" binding:"required"`
+}
+
+type tenantAdminSeedOutput struct {
+	RoleCode string `json:"role_code"`
+	UserCode string `json:"user_code"`
+}
+
+// @Summary Seed an admin user and role that may act on every object of a tenant
+// @Tags tenant
+// @Param input body tenantAdminSeedInput true "input"
+// @Success 200 {object} controller.Response{data=tenantAdminSeedOutput} "output"
+// @Router /api/tenant/admin/seed [post]
+func tenantAdminSeed(ctx *gin.Context) {
+	var input tenantAdminSeedInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	if !input.EndTime.After(input.BeginTime) {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithHint("end_time must be after begin_time"))
+		return
+	}
+
+	if err := tenantService.Verify(ctx, input.TenantCode); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithHint("tenant not found").WithError(err))
+		return
+	}
+
+	subjectRepo := dal.NewRepo[model.TblSubject]()
+
+	nameCount, err := subjectRepo.Count(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+		return db.Where("type = ? AND tenant_code = ? AND name = ? AND deleted = ?", model.SubjectTypeUser, input.TenantCode, input.UserName, model.NotDeleted)
+	})
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+	if nameCount > 0 {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithHint("user name already exists"))
+		return
+	}
+
+	now := time.Now()
+	adminRole := &model.TblSubject{
+		Type:       model.SubjectTypeRole,
+		Code:       util.GenerateCode(),
+		TenantCode: input.TenantCode,
+		Name:       tenantAdminRoleName,
+		Sort:       1,
+		Status:     model.StatusEnabled.Int64(),
+		Deleted:    model.NotDeleted,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	adminUser := &model.TblSubject{
+		Type:       model.SubjectTypeUser,
+		Code:       util.GenerateCode(),
+		TenantCode: input.TenantCode,
+		Name:       input.UserName,
+		Status:     model.StatusEnabled.Int64(),
+		Deleted:    model.NotDeleted,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	// insert the admin role and user, then grant the role on the whole tenant
+	var assigned, granted *casbin.ChangedRules
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := subjectRepo.Insert(ctx, tx, adminRole); err != nil {
+			return err
+		}
+		if err := subjectRepo.Insert(ctx, tx, adminUser); err != nil {
+			return err
+		}
+		var err error
+		if assigned, err = casbin.AssignRolesToUserTx(ctx, tx, input.TenantCode, adminUser.Code, []string{adminRole.Code}); err != nil {
+			return err
+		}
+		granted, err = casbin.GrantTenantToRole(ctx, tx, input.TenantCode, adminRole.Code, input.Actions, input.BeginTime, input.EndTime)
+		return err
+	}); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	if err := casbin.SyncChangedRules(ctx, assigned, granted); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	controller.Success(ctx, tenantAdminSeedOutput{
+		RoleCode: adminRole.Code,
+		UserCode: adminUser.Code,
+	})
+}
@@ -0,0 +1,67 @@
+package tenant
+
+import (
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/controller"
+	"ac/model"
+	"ac/util"
+
+	"github.com/gin-gonic/gin"
+	"github.com/onnttf/kit/dal"
+
+	"gorm.io/gorm"
+)
+
+type tenantCreateInput struct {
+	Name string `json:"name" binding:"required,min=1,max=50"`
+}
+
+type tenantCreateOutput struct {
+	Code string `json:"code"`
+}
+
+// @Summary Create a new tenant
+// @Tags tenant
+// @Param input body tenantCreateInput true "input"
+// @Success 200 {object} controller.Response{data=tenantCreateOutput} "output"
+// @Router /api/tenant/create [post]
+func tenantCreate(ctx *gin.Context) {
+	var input tenantCreateInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	tenantRepo := dal.NewRepo[model.TblTenant]()
+
+	nameCount, err := tenantRepo.Count(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+		return db.Where("name = ? AND deleted = ?", input.Name, model.NotDeleted)
+	})
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+	if nameCount > 0 {
+		controller.Failure(ctx, controller.ErrAlreadyExists.WithHint("tenant name already exists"))
+		return
+	}
+
+	now := time.Now()
+	newValue := &model.TblTenant{
+		Code:      util.GenerateCode(),
+		Name:      input.Name,
+		Status:    model.StatusEnabled.Int64(),
+		Deleted:   model.NotDeleted,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := tenantRepo.Insert(ctx, database.DB, newValue); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	controller.Success(ctx, tenantCreateOutput{Code: newValue.Code})
+}
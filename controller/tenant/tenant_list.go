@@ -0,0 +1,71 @@
+package tenant
+
+import (
+	"ac/bootstrap/database"
+	"ac/controller"
+	"ac/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/onnttf/kit/dal"
+	"gorm.io/gorm"
+)
+
+type tenantListInput struct {
+	Page     int `form:"page" binding:"required,min=1" default:"1"`
+	PageSize int `form:"page_size" binding:"required,min=1,max=100" default:"10"`
+}
+
+type tenantListOutput struct {
+	Total int64            `json:"total"`
+	List  []tenantListItem `json:"list"`
+}
+
+type tenantListItem struct {
+	Id     int64  `json:"id"`
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Status int64  `json:"status"`
+}
+
+// @Summary List tenants with pagination
+// @Tags tenant
+// @Param input query tenantListInput true "input"
+// @Success 200 {object} controller.Response{data=tenantListOutput} "output"
+// @Router /api/tenant/list [get]
+func tenantList(ctx *gin.Context) {
+	var input tenantListInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	tenantRepo := dal.NewRepo[model.TblTenant]()
+
+	total, err := tenantRepo.Count(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+		return db.Where("deleted = ?", model.NotDeleted)
+	})
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	tenantList, err := tenantRepo.Query(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+		return db.Where("deleted = ?", model.NotDeleted).Order("id DESC")
+	}, dal.Paginate(input.Page, input.PageSize))
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	list := make([]tenantListItem, len(tenantList))
+	for i, v := range tenantList {
+		list[i] = tenantListItem{
+			Id:     v.Id,
+			Code:   v.Code,
+			Name:   v.Name,
+			Status: v.Status,
+		}
+	}
+
+	controller.Success(ctx, tenantListOutput{Total: total, List: list})
+}
@@ -11,6 +11,9 @@ func RegisterRoutes(api *gin.RouterGroup) {
 	router.POST("/create", userCreate)
 	router.POST("/update", userUpdate)
 	router.POST("/delete", userDelete)
+	router.POST("/enable", userEnable)
+	router.POST("/disable", userDisable)
+	router.POST("/restore", userRestore)
 	router.GET("/fetch", userFetch)
 	router.GET("/list", userList)
 	router.POST("/role/assign", userRoleAssign)
@@ -6,6 +6,9 @@ import (
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
+	"ac/service/tenant"
 	"ac/util"
 
 	"github.com/gin-gonic/gin"
@@ -15,7 +18,9 @@ import (
 )
 
 type userCreateInput struct {
-	Name string `json:"name" binding:"required,min=6,max=50" example:"Alice"`
+	TenantCode string         `json:"tenant_code" binding:"required,len=36"`
+	Name       string         `json:"name" binding:"required,min=6,max=50" example:"Alice"`
+	Attributes map[string]any `json:"attributes" binding:"omitempty"` // attributes conditions read as r.user
 }
 
 type userCreateOutput struct {
@@ -35,10 +40,15 @@ func userCreate(ctx *gin.Context) {
 		return
 	}
 
+	if err := tenant.Verify(ctx, input.TenantCode); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithHint("tenant not found").WithError(err))
+		return
+	}
+
 	userRepo := dal.NewRepo[model.TblSubject]()
 
 	emailCount, err := userRepo.Count(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Unscoped().Where("type = ? AND name = ?", model.SubjectTypeUser, input.Name)
+		return db.Where("type = ? AND tenant_code = ? AND name = ? AND deleted = ?", model.SubjectTypeUser, input.TenantCode, input.Name, model.NotDeleted)
 	})
 	if err != nil {
 
@@ -51,19 +61,39 @@ func userCreate(ctx *gin.Context) {
 		return
 	}
 
+	attributes, err := casbin.FormatAttributes(input.Attributes)
+	if err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithHint("invalid attributes").WithError(err))
+		return
+	}
+
 	// Create new user
 	now := time.Now()
 	newValue := &model.TblSubject{
-		Type:      model.SubjectTypeUser,
-		Code:      util.GenerateCode(),
-		Name:      input.Name,
-		Status:    model.StatusEnabled.Int64(),
-		Deleted:   model.NotDeleted,
-		CreatedAt: now,
-		UpdatedAt: now,
+		Type:       model.SubjectTypeUser,
+		Code:       util.GenerateCode(),
+		TenantCode: input.TenantCode,
+		Name:       input.Name,
+		Attributes: attributes,
+		Status:     model.StatusEnabled.Int64(),
+		Deleted:    model.NotDeleted,
+		CreatedAt:  now,
+		UpdatedAt:  now,
 	}
 
-	if err := userRepo.Insert(ctx, database.DB, newValue); err != nil {
+	// insert the user and record the change together
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := userRepo.Insert(ctx, tx, newValue); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Entry{
+			TenantCode:   newValue.TenantCode,
+			Action:       audit.ActionCreate,
+			ResourceType: audit.ResourceUser,
+			ResourceCode: newValue.Code,
+			NewValue:     newValue,
+		})
+	}); err != nil {
 
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
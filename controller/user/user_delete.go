@@ -6,6 +6,8 @@ import (
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
 
 	"github.com/gin-gonic/gin"
 	"github.com/onnttf/kit/dal"
@@ -14,10 +16,14 @@ import (
 )
 
 type userDeleteInput struct {
-	Code string `json:"code" binding:"required,len=36"`
+	TenantCode string `json:"tenant_code" binding:"required,len=36"`
+	Code       string `json:"code" binding:"required,len=36"`
 }
 
-type userDeleteOutput struct{}
+type userDeleteOutput struct {
+	RemovedRoleLinks int64 `json:"removed_role_links"`
+	RemovedPolicies  int64 `json:"removed_policies"`
+}
 
 // @Summary Delete an existing user
 // @Tags user
@@ -32,9 +38,10 @@ func userDelete(ctx *gin.Context) {
 	}
 
 	condition := map[string]any{
-		"type":    model.SubjectTypeUser,
-		"code":    input.Code,
-		"deleted": model.NotDeleted,
+		"type":        model.SubjectTypeUser,
+		"tenant_code": input.TenantCode,
+		"code":        input.Code,
+		"deleted":     model.NotDeleted,
 	}
 
 	newValue := map[string]any{
@@ -43,12 +50,46 @@ func userDelete(ctx *gin.Context) {
 	}
 
 	userRepo := dal.NewRepo[model.TblSubject]()
-	if err := userRepo.UpdateFields(ctx, database.DB, newValue, func(db *gorm.DB) *gorm.DB {
-		return db.Where(condition)
+
+	// delete the user and every rule referencing it together
+	var removed *casbin.RemovedRules
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		user, err := userRepo.QueryOne(ctx, tx, func(db *gorm.DB) *gorm.DB {
+			return db.Where(condition)
+		})
+		if err != nil {
+			return err
+		}
+		if err := userRepo.UpdateFields(ctx, tx, newValue, func(db *gorm.DB) *gorm.DB {
+			return db.Where(condition)
+		}); err != nil {
+			return err
+		}
+		if user != nil {
+			if err := audit.Record(ctx, tx, audit.Entry{
+				TenantCode:   user.TenantCode,
+				Action:       audit.ActionDelete,
+				ResourceType: audit.ResourceUser,
+				ResourceCode: user.Code,
+				OldValue:     user,
+			}); err != nil {
+				return err
+			}
+		}
+		removed, err = casbin.RemoveEntityRules(ctx, tx, input.Code, casbin.EntityUser)
+		return err
 	}); err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
 	}
 
-	controller.Success(ctx, userDeleteOutput{})
+	if err := casbin.SyncRemovedRules(ctx, removed); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	controller.Success(ctx, userDeleteOutput{
+		RemovedRoleLinks: removed.RoleLinks,
+		RemovedPolicies:  removed.Policies,
+	})
 }
@@ -4,6 +4,7 @@ import (
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/casbin"
 
 	"github.com/gin-gonic/gin"
 	"github.com/onnttf/kit/dal"
@@ -11,12 +12,15 @@ import (
 )
 
 type userFetchInput struct {
-	Code string `form:"code" binding:"required,len=36"`
+	TenantCode string `form:"tenant_code" binding:"required,len=36"`
+	Code       string `form:"code" binding:"required,len=36"`
 }
 
 type userFetchOutput struct {
-	Code string `json:"code"`
-	Name string `json:"name"`
+	Code       string         `json:"code"`
+	Name       string         `json:"name"`
+	Status     int64          `json:"status"`
+	Attributes map[string]any `json:"attributes"`
 }
 
 // @Summary Fetch a user by code
@@ -32,9 +36,10 @@ func userFetch(ctx *gin.Context) {
 	}
 
 	condition := map[string]any{
-		"type":    model.SubjectTypeUser,
-		"code":    input.Code,
-		"deleted": model.NotDeleted,
+		"type":        model.SubjectTypeUser,
+		"tenant_code": input.TenantCode,
+		"code":        input.Code,
+		"deleted":     model.NotDeleted,
 	}
 
 	userRepo := dal.NewRepo[model.TblSubject]()
@@ -51,8 +56,16 @@ func userFetch(ctx *gin.Context) {
 		return
 	}
 
+	attributes, err := casbin.ParseAttributes(user.Attributes)
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithHint("invalid user attributes").WithError(err))
+		return
+	}
+
 	controller.Success(ctx, userFetchOutput{
-		Code: user.Code,
-		Name: user.Name,
+		Code:       user.Code,
+		Name:       user.Name,
+		Status:     user.Status,
+		Attributes: attributes,
 	})
 }
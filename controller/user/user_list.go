@@ -11,8 +11,10 @@ import (
 )
 
 type userListInput struct {
-	Page     int `form:"page" binding:"required,min=1" default:"1"`
-	PageSize int `form:"page_size" binding:"required,min=1,max=100" default:"10"`
+	TenantCode string `form:"tenant_code" binding:"required,len=36"`
+	Page       int    `form:"page" binding:"required,min=1" default:"1"`
+	PageSize   int    `form:"page_size" binding:"required,min=1,max=100" default:"10"`
+	Deleted    bool   `form:"deleted"` // list deleted users instead, to find ones to restore
 }
 
 type userListOutput struct {
@@ -21,9 +23,10 @@ type userListOutput struct {
 }
 
 type userListItem struct {
-	Code  string `json:"code"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Status int64  `json:"status"`
 }
 
 // @Summary List users with pagination
@@ -39,8 +42,12 @@ func userList(ctx *gin.Context) {
 	}
 
 	condition := map[string]any{
-		"type":    model.SubjectTypeUser,
-		"deleted": model.NotDeleted,
+		"type":        model.SubjectTypeUser,
+		"tenant_code": input.TenantCode,
+		"deleted":     model.NotDeleted,
+	}
+	if input.Deleted {
+		condition["deleted"] = model.Deleted
 	}
 
 	whereScopes := []func(*gorm.DB) *gorm.DB{
@@ -68,8 +75,9 @@ func userList(ctx *gin.Context) {
 	list := make([]userListItem, len(userList))
 	for i, u := range userList {
 		list[i] = userListItem{
-			Code: u.Code,
-			Name: u.Name,
+			Code:   u.Code,
+			Name:   u.Name,
+			Status: u.Status,
 		}
 	}
 
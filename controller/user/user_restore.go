@@ -0,0 +1,117 @@
+package user
+
+import (
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/controller"
+	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
+
+	"github.com/gin-gonic/gin"
+	"github.com/onnttf/kit/dal"
+	"gorm.io/gorm"
+)
+
+type userRestoreInput struct {
+	TenantCode   string `json:"tenant_code" binding:"required,len=36"`
+	Code         string `json:"code" binding:"required,len=36"`
+	RestoreRules bool   `json:"restore_rules"` // also re-create the role links and policies removed on delete
+}
+
+type userRestoreOutput struct {
+	RestoredRoleLinks int64 `json:"restored_role_links"`
+	RestoredPolicies  int64 `json:"restored_policies"`
+	SkippedRules      int64 `json:"skipped_rules"`
+}
+
+// @Summary Restore a deleted user
+// @Tags user
+// @Param input body userRestoreInput true "input"
+// @Success 200 {object} controller.Response{data=userRestoreOutput} "output"
+// @Router /api/user/restore [post]
+func userRestore(ctx *gin.Context) {
+	var input userRestoreInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	userRepo := dal.NewRepo[model.TblSubject]()
+
+	user, err := userRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+		return db.Where("code = ? AND tenant_code = ? AND type = ? AND deleted = ?", input.Code, input.TenantCode, model.SubjectTypeUser, model.Deleted)
+	})
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+	if user == nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithMsg("deleted user not found"))
+		return
+	}
+
+	nameCount, err := userRepo.Count(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+		return db.Where("type = ? AND tenant_code = ? AND name = ? AND deleted = ?", model.SubjectTypeUser, input.TenantCode, user.Name, model.NotDeleted)
+	})
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+	if nameCount > 0 {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithHint("user name already exists, rename the other user first"))
+		return
+	}
+
+	now := time.Now()
+	newValue := map[string]any{
+		"deleted":    model.NotDeleted,
+		"updated_at": now,
+	}
+
+	// restore the user and its rules together
+	var restored *casbin.RestoredRules
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := userRepo.UpdateFields(ctx, tx, newValue, func(db *gorm.DB) *gorm.DB {
+			return db.Where("code = ? AND type = ?", user.Code, model.SubjectTypeUser)
+		}); err != nil {
+			return err
+		}
+		restoredUser := *user
+		restoredUser.Deleted = model.NotDeleted
+		restoredUser.UpdatedAt = now
+		if err := audit.Record(ctx, tx, audit.Entry{
+			TenantCode:   user.TenantCode,
+			Action:       audit.ActionRestore,
+			ResourceType: audit.ResourceUser,
+			ResourceCode: user.Code,
+			OldValue:     user,
+			NewValue:     restoredUser,
+		}); err != nil {
+			return err
+		}
+		var err error
+		restored, err = casbin.RestoreEntityRules(ctx, tx, user.TenantCode, user.Code, casbin.EntityUser, "", input.RestoreRules)
+		return err
+	}); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	if err := casbin.SyncRestoredRules(ctx, restored); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	if err := casbin.SetEntityStatus(ctx, user.Code, casbin.EntityUser, model.StatusFlag(user.Status)); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	controller.Success(ctx, userRestoreOutput{
+		RestoredRoleLinks: restored.RoleLinks,
+		RestoredPolicies:  restored.Policies,
+		SkippedRules:      restored.Skipped,
+	})
+}
@@ -12,7 +12,8 @@ import (
 )
 
 type userRoleInput struct {
-	UserCode string `form:"user_code" binding:"required,len=36"`
+	TenantCode string `form:"tenant_code" binding:"required,len=36"`
+	UserCode   string `form:"user_code" binding:"required,len=36"`
 }
 
 type userRoleOutput struct {
@@ -36,12 +37,12 @@ func userRole(ctx *gin.Context) {
 		return
 	}
 
-	if err := user.Verify(ctx, input.UserCode); err != nil {
+	if err := user.Verify(ctx, input.TenantCode, input.UserCode); err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
 	}
 
-	roleCodeList, err := casbin.GetRolesForUser(ctx, input.UserCode)
+	roleCodeList, err := casbin.GetRolesForUser(ctx, input.TenantCode, input.UserCode)
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
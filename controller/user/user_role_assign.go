@@ -1,9 +1,11 @@
 package user
 
 import (
+	"slices"
 	"strings"
 
 	"ac/controller"
+	"ac/service/audit"
 	"ac/service/casbin"
 	"ac/service/role"
 	"ac/service/user"
@@ -12,8 +14,9 @@ import (
 )
 
 type userRoleAssignInput struct {
-	UserCode  string   `json:"user_code" binding:"required,len=36"`
-	RoleCodes []string `json:"role_codes" binding:"required,min=1,dive,len=36"`
+	TenantCode string   `json:"tenant_code" binding:"required,len=36"`
+	UserCode   string   `json:"user_code" binding:"required,len=36"`
+	RoleCodes  []string `json:"role_codes" binding:"required,min=1,dive,len=36"`
 }
 
 type userRoleAssignOutput struct{}
@@ -30,12 +33,12 @@ func userRoleAssign(ctx *gin.Context) {
 		return
 	}
 
-	if err := user.Verify(ctx, input.UserCode); err != nil {
+	if err := user.Verify(ctx, input.TenantCode, input.UserCode); err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
 	}
 
-	code2Error, err := role.BatchVerify(ctx, input.RoleCodes)
+	code2Error, err := role.BatchVerify(ctx, input.TenantCode, input.RoleCodes)
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
@@ -49,7 +52,7 @@ func userRoleAssign(ctx *gin.Context) {
 		return
 	}
 
-	assignedRoles, err := casbin.GetRolesForUser(ctx, input.UserCode)
+	assignedRoles, err := casbin.GetRolesForUser(ctx, input.TenantCode, input.UserCode)
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
@@ -73,7 +76,18 @@ func userRoleAssign(ctx *gin.Context) {
 		return
 	}
 
-	if err := casbin.AssignRolesToUser(ctx, input.UserCode, input.RoleCodes); err != nil {
+	newRoles := append(slices.Clone(assignedRoles), input.RoleCodes...)
+	slices.Sort(newRoles)
+	audit.RecordOnCommit(ctx, audit.Entry{
+		TenantCode:   input.TenantCode,
+		Action:       audit.ActionAssign,
+		ResourceType: audit.ResourceUser,
+		ResourceCode: input.UserCode,
+		OldValue:     map[string]any{"role_codes": assignedRoles},
+		NewValue:     map[string]any{"role_codes": newRoles},
+	})
+
+	if err := casbin.AssignRolesToUser(ctx, input.TenantCode, input.UserCode, input.RoleCodes); err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
 	}
@@ -1,9 +1,11 @@
 package user
 
 import (
+	"slices"
 	"strings"
 
 	"ac/controller"
+	"ac/service/audit"
 	"ac/service/casbin"
 	"ac/service/role"
 	"ac/service/user"
@@ -12,8 +14,9 @@ import (
 )
 
 type userRoleRemoveInput struct {
-	UserCode  string   `json:"user_code" binding:"required,len=36"`
-	RoleCodes []string `json:"role_codes" binding:"required,min=1,dive,len=36"`
+	TenantCode string   `json:"tenant_code" binding:"required,len=36"`
+	UserCode   string   `json:"user_code" binding:"required,len=36"`
+	RoleCodes  []string `json:"role_codes" binding:"required,min=1,dive,len=36"`
 }
 
 type userRoleRemoveOutput struct{}
@@ -30,12 +33,12 @@ func userRoleRemove(ctx *gin.Context) {
 		return
 	}
 
-	if err := user.Verify(ctx, input.UserCode); err != nil {
+	if err := user.Verify(ctx, input.TenantCode, input.UserCode); err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
 	}
 
-	code2Error, err := role.BatchVerify(ctx, input.RoleCodes)
+	code2Error, err := role.BatchVerify(ctx, input.TenantCode, input.RoleCodes)
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
@@ -49,7 +52,7 @@ func userRoleRemove(ctx *gin.Context) {
 		return
 	}
 
-	assignedRoles, err := casbin.GetRolesForUser(ctx, input.UserCode)
+	assignedRoles, err := casbin.GetRolesForUser(ctx, input.TenantCode, input.UserCode)
 	if err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
@@ -74,7 +77,19 @@ func userRoleRemove(ctx *gin.Context) {
 		return
 	}
 
-	if err := casbin.RemoveRolesFromUser(ctx, input.UserCode, input.RoleCodes); err != nil {
+	newRoles := slices.DeleteFunc(slices.Clone(assignedRoles), func(roleCode string) bool {
+		return slices.Contains(input.RoleCodes, roleCode)
+	})
+	audit.RecordOnCommit(ctx, audit.Entry{
+		TenantCode:   input.TenantCode,
+		Action:       audit.ActionRemove,
+		ResourceType: audit.ResourceUser,
+		ResourceCode: input.UserCode,
+		OldValue:     map[string]any{"role_codes": assignedRoles},
+		NewValue:     map[string]any{"role_codes": newRoles},
+	})
+
+	if err := casbin.RemoveRolesFromUser(ctx, input.TenantCode, input.UserCode, input.RoleCodes); err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
 	}
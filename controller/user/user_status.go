@@ -0,0 +1,103 @@
+package user
+
+import (
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/controller"
+	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
+
+	"github.com/gin-gonic/gin"
+	"github.com/onnttf/kit/dal"
+	"gorm.io/gorm"
+)
+
+type userStatusInput struct {
+	TenantCode string `json:"tenant_code" binding:"required,len=36"`
+	Code       string `json:"code" binding:"required,len=36"`
+}
+
+type userStatusOutput struct{}
+
+// @Summary Enable a user, restoring the access its grants give
+// @Tags user
+// @Param input body userStatusInput true "input"
+// @Success 200 {object} controller.Response{data=userStatusOutput} "output"
+// @Router /api/user/enable [post]
+func userEnable(ctx *gin.Context) {
+	setUserStatus(ctx, model.StatusEnabled)
+}
+
+// @Summary Disable a user, blocking its access without removing grants
+// @Tags user
+// @Param input body userStatusInput true "input"
+// @Success 200 {object} controller.Response{data=userStatusOutput} "output"
+// @Router /api/user/disable [post]
+func userDisable(ctx *gin.Context) {
+	setUserStatus(ctx, model.StatusDisabled)
+}
+
+func setUserStatus(ctx *gin.Context, status model.StatusFlag) {
+	var input userStatusInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithError(err))
+		return
+	}
+
+	userRepo := dal.NewRepo[model.TblSubject]()
+
+	user, err := userRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+		return db.Where("code = ? AND tenant_code = ? AND type = ? AND deleted = ?", input.Code, input.TenantCode, model.SubjectTypeUser, model.NotDeleted)
+	})
+	if err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+	if user == nil {
+		controller.Failure(ctx, controller.ErrInvalidInput.WithMsg("user not found"))
+		return
+	}
+
+	now := time.Now()
+	newValue := map[string]any{
+		"status":     status.Int64(),
+		"updated_at": now,
+	}
+
+	updatedUser := *user
+	updatedUser.Status = status.Int64()
+	updatedUser.UpdatedAt = now
+	action := audit.ActionEnable
+	if status == model.StatusDisabled {
+		action = audit.ActionDisable
+	}
+
+	// update the status and record the change together
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := userRepo.UpdateFields(ctx, tx, newValue, func(db *gorm.DB) *gorm.DB {
+			return db.Where("code = ? AND type = ?", user.Code, model.SubjectTypeUser)
+		}); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, audit.Entry{
+			TenantCode:   user.TenantCode,
+			Action:       action,
+			ResourceType: audit.ResourceUser,
+			ResourceCode: user.Code,
+			OldValue:     user,
+			NewValue:     updatedUser,
+		})
+	}); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	if err := casbin.SetEntityStatus(ctx, user.Code, casbin.EntityUser, status); err != nil {
+		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
+		return
+	}
+
+	controller.Success(ctx, userStatusOutput{})
+}
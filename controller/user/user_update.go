@@ -6,6 +6,8 @@ import (
 	"ac/bootstrap/database"
 	"ac/controller"
 	"ac/model"
+	"ac/service/audit"
+	"ac/service/casbin"
 
 	"github.com/gin-gonic/gin"
 	"github.com/onnttf/kit/dal"
@@ -13,8 +15,10 @@ import (
 )
 
 type userUpdateInput struct {
-	Code string `json:"code" binding:"required,len=36"`
-	Name string `json:"name" binding:"required,min=6,max=50" example:"Alice"`
+	TenantCode string         `json:"tenant_code" binding:"required,len=36"`
+	Code       string         `json:"code" binding:"required,len=36"`
+	Name       string         `json:"name" binding:"required,min=6,max=50" example:"Alice"`
+	Attributes map[string]any `json:"attributes" binding:"omitempty"` // replaces stored attributes, omit to keep
 }
 
 type userUpdateOutput struct{}
@@ -32,19 +36,60 @@ func userUpdate(ctx *gin.Context) {
 	}
 
 	condition := map[string]any{
-		"type":    model.SubjectTypeUser,
-		"code":    input.Code,
-		"deleted": model.NotDeleted,
+		"type":        model.SubjectTypeUser,
+		"tenant_code": input.TenantCode,
+		"code":        input.Code,
+		"deleted":     model.NotDeleted,
 	}
 
+	now := time.Now()
 	newValue := map[string]any{
 		"name":       input.Name,
-		"updated_at": time.Now(),
+		"updated_at": now,
+	}
+	if input.Attributes != nil {
+		attributes, err := casbin.FormatAttributes(input.Attributes)
+		if err != nil {
+			controller.Failure(ctx, controller.ErrInvalidInput.WithHint("invalid attributes").WithError(err))
+			return
+		}
+		newValue["attributes"] = attributes
 	}
 
 	userRepo := dal.NewRepo[model.TblSubject]()
-	if err := userRepo.UpdateFields(ctx, database.DB, newValue, func(db *gorm.DB) *gorm.DB {
-		return db.Where(condition)
+
+	// update the user and record the change together
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		user, err := userRepo.QueryOne(ctx, tx, func(db *gorm.DB) *gorm.DB {
+			return db.Where(condition)
+		})
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return nil
+		}
+
+		if err := userRepo.UpdateFields(ctx, tx, newValue, func(db *gorm.DB) *gorm.DB {
+			return db.Where(condition)
+		}); err != nil {
+			return err
+		}
+
+		updatedUser := *user
+		updatedUser.Name = input.Name
+		updatedUser.UpdatedAt = now
+		if attributes, ok := newValue["attributes"].(string); ok {
+			updatedUser.Attributes = attributes
+		}
+		return audit.Record(ctx, tx, audit.Entry{
+			TenantCode:   user.TenantCode,
+			Action:       audit.ActionUpdate,
+			ResourceType: audit.ResourceUser,
+			ResourceCode: user.Code,
+			OldValue:     user,
+			NewValue:     updatedUser,
+		})
 	}); err != nil {
 		controller.Failure(ctx, controller.ErrSystemError.WithError(err))
 		return
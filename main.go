@@ -11,14 +11,22 @@ import (
 	"time"
 
 	"ac/bootstrap"
+	"ac/bootstrap/config"
+	"ac/bootstrap/database"
+	"ac/bootstrap/migration"
 	"ac/controller"
 
+	apiAdmin "ac/controller/admin"
+	apiAudit "ac/controller/audit"
+	apiAuthz "ac/controller/authz"
 	apiObject "ac/controller/object"
 	apiPermission "ac/controller/permission"
 	apiRole "ac/controller/role"
+	apiTenant "ac/controller/tenant"
 	apiUser "ac/controller/user"
 	"ac/middleware"
 	"ac/service/casbin"
+	"ac/service/purge"
 
 	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
@@ -48,6 +56,21 @@ func main() {
 		panic(err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: main: migrate failed, error=%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if config.Config().Database.AutoMigrate {
+		if _, err := migration.Up(database.DB); err != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: main: failed to migrate database, error=%v\n", err)
+			panic(err)
+		}
+	}
+
 	if err := casbin.Initialize(); err != nil {
 		fmt.Fprintf(os.Stderr, "ERROR: main: failed to initialize casbin, error=%v\n", err)
 		panic(err)
@@ -56,6 +79,10 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	purge.Start(ctx)
+	casbin.StartAutoReload(ctx)
+	casbin.StartDecisionLog(ctx)
+
 	router := gin.New()
 	router.Use(middleware.Logger(), gin.Recovery(), requestid.New())
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
@@ -68,10 +95,14 @@ func main() {
 	})
 
 	api := router.Group("/api")
+	apiTenant.RegisterRoutes(api)
 	apiUser.RegisterRoutes(api)
 	apiRole.RegisterRoutes(api)
 	apiObject.RegisterRoutes(api)
 	apiPermission.RegisterRoutes(api)
+	apiAuthz.RegisterRoutes(api)
+	apiAdmin.RegisterRoutes(api)
+	apiAudit.RegisterRoutes(api)
 
 	srv := &http.Server{
 		Addr:         ":8082",
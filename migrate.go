@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/bootstrap/migration"
+)
+
+const migrateUsage = "usage: ac migrate up | down [steps] | status"
+
+// runMigrate executes the migrate subcommand: up applies pending migrations, down reverts
+// the latest one or the given number of them, and status lists every migration.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migration.Up(database.DB)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "INFO: main: migrate up: applied=%d\n", len(applied))
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid steps %q, %s", args[1], migrateUsage)
+			}
+			steps = n
+		}
+		reverted, err := migration.Down(database.DB, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "INFO: main: migrate down: reverted=%d\n", len(reverted))
+	case "status":
+		statuses, err := migration.List(database.DB)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			fmt.Fprintf(os.Stdout, "%04d  %-24s  %s\n", status.Version, status.Name, appliedAt)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
@@ -43,16 +43,18 @@ const TableNameTblSubject = "tbl_subject"
 
 // TblSubject mapped from table <tbl_subject>
 type TblSubject struct {
-	Id         int64       `gorm:"column:id;type:int unsigned;primaryKey;autoIncrement:true;comment:id" json:"id"`                                                                                      // id
-	Type       SubjectType `gorm:"column:type;type:int;not null;index:idx_subject_type_deleted_status,priority:1;comment:type" json:"type"`                                                             // type
-	Code       string      `gorm:"column:code;type:varchar(100);not null;uniqueIndex:uk_subject_code,priority:1;comment:code" json:"code"`                                                              // code
-	Name       string      `gorm:"column:name;type:varchar(100);not null;index:idx_subject_name,priority:1;comment:name" json:"name"`                                                                   // name
-	ParentCode string      `gorm:"column:parent_code;type:varchar(100);not null;index:idx_subject_parent_deleted_status,priority:1;comment:parent_code" json:"parent_code"`                             // parent_code
-	Sort       int64       `gorm:"column:sort;type:int;not null;comment:sort" json:"sort"`                                                                                                              // sort
-	Status     int64       `gorm:"column:status;type:int;not null;index:idx_subject_parent_deleted_status,priority:3;index:idx_subject_type_deleted_status,priority:3;comment:status" json:"status"`    // status
-	Deleted    DeletedFlag `gorm:"column:deleted;type:int;not null;index:idx_subject_parent_deleted_status,priority:2;index:idx_subject_type_deleted_status,priority:2;comment:deleted" json:"deleted"` // deleted
-	CreatedAt  time.Time   `gorm:"column:created_at;type:datetime;not null;default:CURRENT_TIMESTAMP;comment:created_at" json:"created_at"`                                                             // created_at
-	UpdatedAt  time.Time   `gorm:"column:updated_at;type:datetime;not null;default:CURRENT_TIMESTAMP;comment:updated_at" json:"updated_at"`                                                             // updated_at
+	Id         int64       `gorm:"column:id;primaryKey;autoIncrement:true;comment:id" json:"id"`                                                                                                                                                    // id
+	Type       SubjectType `gorm:"column:type;type:int;not null;index:idx_subject_type_deleted_status,priority:1;comment:type" json:"type"`                                                                                                         // type
+	Code       string      `gorm:"column:code;type:varchar(100);not null;uniqueIndex:uk_subject_code,priority:1;comment:code" json:"code"`                                                                                                          // code
+	TenantCode string      `gorm:"column:tenant_code;type:varchar(100);not null;index:idx_subject_tenant_deleted,priority:1;comment:tenant_code" json:"tenant_code"`                                                                                // tenant_code
+	Name       string      `gorm:"column:name;type:varchar(100);not null;index:idx_subject_name,priority:1;comment:name" json:"name"`                                                                                                               // name
+	ParentCode string      `gorm:"column:parent_code;type:varchar(100);not null;index:idx_subject_parent_deleted_status,priority:1;comment:parent_code" json:"parent_code"`                                                                         // parent_code
+	Sort       int64       `gorm:"column:sort;type:int;not null;comment:sort" json:"sort"`                                                                                                                                                          // sort
+	Attributes string      `gorm:"column:attributes;type:varchar(2000);not null;comment:attributes" json:"attributes"`                                                                                                                              // attributes
+	Status     int64       `gorm:"column:status;type:int;not null;index:idx_subject_parent_deleted_status,priority:3;index:idx_subject_type_deleted_status,priority:3;comment:status" json:"status"`                                                // status
+	Deleted    DeletedFlag `gorm:"column:deleted;type:int;not null;index:idx_subject_parent_deleted_status,priority:2;index:idx_subject_type_deleted_status,priority:2;index:idx_subject_tenant_deleted,priority:2;comment:deleted" json:"deleted"` // deleted
+	CreatedAt  time.Time   `gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP;comment:created_at" json:"created_at"`                                                                                                                       // created_at
+	UpdatedAt  time.Time   `gorm:"column:updated_at;not null;default:CURRENT_TIMESTAMP;comment:updated_at" json:"updated_at"`                                                                                                                       // updated_at
 }
 
 // TableName TblSubject's table name
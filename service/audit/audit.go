@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"ac/bootstrap/logger"
+	"ac/model"
+	"ac/service/casbin"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/onnttf/kit/dal"
+	"gorm.io/gorm"
+)
+
+// ActorHeader names the request header identifying who makes a change.
+// Requests without it are recorded with the client IP as the actor.
+const ActorHeader = "X-Actor"
+
+// maxActorLength is the width of the actor column.
+const maxActorLength = 100
+
+// Actions recorded in the audit log
+const (
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionDelete   = "delete"
+	ActionRestore  = "restore"
+	ActionEnable   = "enable"
+	ActionDisable  = "disable"
+	ActionAssign   = "assign"
+	ActionRemove   = "remove"
+	ActionRollback = "rollback"
+)
+
+// Resource types recorded in the audit log
+const (
+	ResourceUser       = "user"
+	ResourceRole       = "role"
+	ResourceObject     = "object"
+	ResourcePermission = "permission"
+	ResourceSnapshot   = "snapshot"
+)
+
+// Entry describes one administrative change.
+type Entry struct {
+	TenantCode   string
+	Action       string
+	ResourceType string
+	ResourceCode string
+	OldValue     any // State before the change, nil when the resource did not exist
+	NewValue     any // State after the change, nil when the resource no longer exists
+}
+
+// Record appends entry to the audit log within tx, so it commits or rolls back with the change.
+func Record(ctx *gin.Context, tx *gorm.DB, entry Entry) error {
+	oldValue, err := encode(entry.OldValue)
+	if err != nil {
+		return fmt.Errorf("failed to encode old value: %w", err)
+	}
+	newValue, err := encode(entry.NewValue)
+	if err != nil {
+		return fmt.Errorf("failed to encode new value: %w", err)
+	}
+
+	log := &model.TblAuditLog{
+		TenantCode:   entry.TenantCode,
+		Actor:        Actor(ctx),
+		Action:       entry.Action,
+		ResourceType: entry.ResourceType,
+		ResourceCode: entry.ResourceCode,
+		OldValue:     oldValue,
+		NewValue:     newValue,
+		RequestId:    requestid.Get(ctx),
+		CreatedAt:    time.Now(),
+	}
+	if err := dal.NewRepo[model.TblAuditLog]().Insert(ctx, tx, log); err != nil {
+		logger.Errorf(ctx, "audit: failed to record change: action=%s, resource_type=%s, resource_code=%s, error=%v",
+			entry.Action, entry.ResourceType, entry.ResourceCode, err)
+		return fmt.Errorf("failed to record audit log: %w", err)
+	}
+	return nil
+}
+
+// RecordOnCommit appends entry to the audit log within the database transaction of the next
+// policy change made with ctx. Use it for changes stored through the casbin service.
+func RecordOnCommit(ctx *gin.Context, entry Entry) {
+	casbin.OnCommit(ctx, func(tx *gorm.DB) error {
+		return Record(ctx, tx, entry)
+	})
+}
+
+// Actor returns who makes the changes of a request.
+func Actor(ctx *gin.Context) string {
+	if actor := ctx.GetHeader(ActorHeader); actor != "" {
+		return actor[:min(len(actor), maxActorLength)]
+	}
+	return ctx.ClientIP()
+}
+
+// encode renders a state as JSON, or as an empty string when there is none.
+func encode(value any) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,212 @@
+package casbin
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DecisionCacheStats reports how the decision cache has been used since startup.
+type DecisionCacheStats struct {
+	Enabled bool
+	Entries int
+	Hits    int64
+	Misses  int64
+}
+
+// decisionKey identifies one Enforce call. Requests falling into the same time bucket share
+// a decision; buckets in which a policy window opens or closes are never cached.
+type decisionKey struct {
+	subject    string
+	domain     string
+	object     string
+	action     string
+	bucket     int64  // Request time truncated to the bucket size, in Unix seconds
+	attributes string // Request attributes in canonical JSON
+}
+
+// decisionCache remembers Enforce results until the next policy, grouping or status change.
+// generation counts invalidations, so a decision computed before one is never stored after it.
+var decisionCache = struct {
+	sync.RWMutex
+	enabled    bool
+	bucket     time.Duration
+	size       int
+	generation uint64
+	entries    map[decisionKey]bool
+	boundaries *windowBoundaries // Built on first store after an invalidation
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}{entries: make(map[decisionKey]bool)}
+
+// configureDecisionCache enables the decision cache with the given bucket size and capacity.
+// A non-positive size disables it.
+func configureDecisionCache(bucket time.Duration, size int) {
+	decisionCache.Lock()
+	defer decisionCache.Unlock()
+
+	decisionCache.enabled = size > 0 && bucket > 0
+	decisionCache.bucket = bucket
+	decisionCache.size = size
+	decisionCache.generation++
+	decisionCache.entries = make(map[decisionKey]bool)
+	decisionCache.boundaries = nil
+}
+
+// cachedDecision looks up a decision. ok is false on a miss, in which case key and generation
+// are what storeDecision needs; key is nil when the request cannot be cached.
+func cachedDecision(subject, domain, object, action string, attributes RequestAttributes, currentTime time.Time) (allowed, ok bool, key *decisionKey, generation uint64) {
+	decisionCache.RLock()
+	defer decisionCache.RUnlock()
+
+	if !decisionCache.enabled {
+		return false, false, nil, 0
+	}
+	encoded, err := json.Marshal(attributes)
+	if err != nil {
+		return false, false, nil, 0
+	}
+
+	key = &decisionKey{
+		subject:    subject,
+		domain:     domain,
+		object:     object,
+		action:     action,
+		bucket:     currentTime.Truncate(decisionCache.bucket).Unix(),
+		attributes: string(encoded),
+	}
+	if allowed, ok = decisionCache.entries[*key]; ok {
+		decisionCache.hits.Add(1)
+		return allowed, true, nil, 0
+	}
+	decisionCache.misses.Add(1)
+	return false, false, key, decisionCache.generation
+}
+
+// storeDecision caches a decision computed after cachedDecision missed, unless the cache
+// was invalidated in the meantime or a policy window of the action opens or closes within
+// the bucket. An arbitrary entry makes room when the cache is full.
+func storeDecision(key *decisionKey, generation uint64, allowed bool) {
+	if key == nil {
+		return
+	}
+
+	decisionCache.Lock()
+	defer decisionCache.Unlock()
+
+	if !decisionCache.enabled || generation != decisionCache.generation {
+		return
+	}
+	if decisionCache.boundaries == nil {
+		decisionCache.boundaries = buildWindowBoundaries()
+	}
+	start := time.Unix(key.bucket, 0)
+	if decisionCache.boundaries.crosses(key.action, start, start.Add(decisionCache.bucket)) {
+		return
+	}
+	if len(decisionCache.entries) >= decisionCache.size {
+		for evicted := range decisionCache.entries {
+			delete(decisionCache.entries, evicted)
+			break
+		}
+	}
+	decisionCache.entries[*key] = allowed
+}
+
+// invalidateDecisions drops every cached decision and whether any policy carries a condition.
+// Call it after any change to the in-memory policies, groupings or entity statuses.
+func invalidateDecisions() {
+	resetConditions()
+
+	decisionCache.Lock()
+	defer decisionCache.Unlock()
+
+	decisionCache.generation++
+	decisionCache.boundaries = nil
+	if len(decisionCache.entries) > 0 {
+		decisionCache.entries = make(map[decisionKey]bool)
+	}
+}
+
+// windowBoundaries indexes, per action, when the windows of the loaded policies may open or close.
+// Subjects and objects are not considered, so a boundary of any policy on an action keeps
+// decisions on that action out of the cache for the bucket holding it.
+type windowBoundaries struct {
+	instants    map[string][]int64       // Sorted Unix seconds at which a window opens or closes
+	recurrences map[string][]*Recurrence // Recurrences the windows repeat in
+	timed       map[string]bool          // Actions with a condition reading the request time
+}
+
+// buildWindowBoundaries indexes the windows of the policies the enforcer holds.
+func buildWindowBoundaries() *windowBoundaries {
+	boundaries := &windowBoundaries{
+		instants:    make(map[string][]int64),
+		recurrences: make(map[string][]*Recurrence),
+		timed:       make(map[string]bool),
+	}
+	seen := make(map[string]struct{})
+	for _, rule := range enforcer.Load().GetModel()["p"]["p"].Policy {
+		if len(rule) < 4 {
+			continue
+		}
+		action := rule[2]
+		if len(rule) > 4 && strings.Contains(rule[4], "r.time") {
+			boundaries.timed[action] = true
+		}
+		beginTime, endTime, spec, err := ParseWindow(rule[3])
+		if err != nil {
+			// A window that does not parse never matches, at any time
+			continue
+		}
+		// Both bounds are inclusive to the second, so the window closes a second after its end
+		boundaries.instants[action] = append(boundaries.instants[action], beginTime.Unix(), endTime.Unix()+1)
+
+		if _, ok := seen[action+RecurrenceSeparator+spec]; spec == "" || ok {
+			continue
+		}
+		seen[action+RecurrenceSeparator+spec] = struct{}{}
+		if recurrence, err := ParseRecurrence(spec); err == nil {
+			boundaries.recurrences[action] = append(boundaries.recurrences[action], recurrence)
+		}
+	}
+	for _, instants := range boundaries.instants {
+		sort.Slice(instants, func(i, j int) bool { return instants[i] < instants[j] })
+	}
+	return boundaries
+}
+
+// crosses reports whether a window of a policy on action may open or close after start and before end.
+// A boundary at start itself applies to the whole bucket and does not count.
+func (b *windowBoundaries) crosses(action string, start, end time.Time) bool {
+	if b.timed[action] {
+		return true
+	}
+	instants := b.instants[action]
+	i := sort.Search(len(instants), func(i int) bool { return instants[i] > start.Unix() })
+	if i < len(instants) && instants[i] < end.Unix() {
+		return true
+	}
+	for _, recurrence := range b.recurrences[action] {
+		if recurrence.nextChange(start).Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDecisionCacheStats returns the hit and miss counts of the decision cache.
+func GetDecisionCacheStats() DecisionCacheStats {
+	decisionCache.RLock()
+	defer decisionCache.RUnlock()
+
+	return DecisionCacheStats{
+		Enabled: decisionCache.enabled,
+		Entries: len(decisionCache.entries),
+		Hits:    decisionCache.hits.Load(),
+		Misses:  decisionCache.misses.Load(),
+	}
+}
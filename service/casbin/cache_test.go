@@ -0,0 +1,177 @@
+package casbin
+
+import (
+	"testing"
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/model"
+)
+
+// enableDecisionCache turns the decision cache on for the duration of a test.
+func enableDecisionCache(t *testing.T, bucket time.Duration) {
+	t.Helper()
+
+	configureDecisionCache(bucket, 1000)
+	t.Cleanup(func() { configureDecisionCache(0, 0) })
+}
+
+func TestDecisionCacheWindowBoundary(t *testing.T) {
+	enableDecisionCache(t, time.Hour)
+
+	ctx := newTestContext()
+	tenantCode := newTenant(t)
+	userCode := newSubject(t, tenantCode, model.SubjectTypeUser)
+	objectCode := newObject(t, ctx, tenantCode, "")
+
+	// The window closes in the middle of an hour bucket
+	bucket := time.Now().UTC().Truncate(time.Hour).Add(24 * time.Hour)
+	policy := Policy{Object: objectCode, Action: "boundary", BeginTime: bucket.Add(-time.Hour), EndTime: bucket.Add(30 * time.Minute)}
+	if err := AssignPoliciesToUser(ctx, userCode, []Policy{policy}); err != nil {
+		t.Fatalf("assign policy: %v", err)
+	}
+
+	for _, check := range []struct {
+		at   time.Time
+		want bool
+	}{
+		{bucket.Add(10 * time.Minute), true},
+		{bucket.Add(50 * time.Minute), false},
+		{bucket.Add(20 * time.Minute), true},
+	} {
+		allowed, _, err := CheckPermission(ctx, tenantCode, userCode, objectCode, "boundary", nil, check.at)
+		if err != nil {
+			t.Fatalf("check at %s: %v", check.at, err)
+		}
+		if allowed != check.want {
+			t.Fatalf("check at %s allowed = %v, want %v", check.at, allowed, check.want)
+		}
+	}
+	if entries := GetDecisionCacheStats().Entries; entries != 0 {
+		t.Fatalf("cached %d decisions in a bucket the window closes in, want none", entries)
+	}
+
+	// The bucket before has no boundary and is cached
+	before := bucket.Add(-30 * time.Minute)
+	for range 2 {
+		if allowed, _, err := CheckPermission(ctx, tenantCode, userCode, objectCode, "boundary", nil, before); err != nil || !allowed {
+			t.Fatalf("check before boundary: allowed=%v, error=%v", allowed, err)
+		}
+	}
+	if entries := GetDecisionCacheStats().Entries; entries != 1 {
+		t.Fatalf("cached %d decisions in a bucket without boundary, want 1", entries)
+	}
+}
+
+func TestDecisionCacheRecurrenceBoundary(t *testing.T) {
+	enableDecisionCache(t, time.Hour)
+
+	ctx := newTestContext()
+	tenantCode := newTenant(t)
+	userCode := newSubject(t, tenantCode, model.SubjectTypeUser)
+	objectCode := newObject(t, ctx, tenantCode, "")
+
+	now := time.Now().UTC()
+	policy := Policy{Object: objectCode, Action: "recurring", BeginTime: now.Add(-48 * time.Hour), EndTime: now.Add(48 * time.Hour), Recurrence: "* 09:30-17:00"}
+	if err := AssignPoliciesToUser(ctx, userCode, []Policy{policy}); err != nil {
+		t.Fatalf("assign policy: %v", err)
+	}
+
+	day := now.Truncate(24 * time.Hour)
+	for _, check := range []struct {
+		at   time.Time
+		want bool
+	}{
+		{day.Add(9*time.Hour + 10*time.Minute), false},
+		{day.Add(9*time.Hour + 40*time.Minute), true},
+	} {
+		allowed, _, err := CheckPermission(ctx, tenantCode, userCode, objectCode, "recurring", nil, check.at)
+		if err != nil {
+			t.Fatalf("check at %s: %v", check.at, err)
+		}
+		if allowed != check.want {
+			t.Fatalf("check at %s allowed = %v, want %v", check.at, allowed, check.want)
+		}
+	}
+}
+
+// checkCached runs a check and reports its result and whether it was answered from the cache.
+func checkCached(t *testing.T, tenantCode, userCode, objectCode, action string, at time.Time) (allowed, hit bool) {
+	t.Helper()
+
+	hits := GetDecisionCacheStats().Hits
+	allowed, _, err := CheckPermission(newTestContext(), tenantCode, userCode, objectCode, action, nil, at)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	return allowed, GetDecisionCacheStats().Hits > hits
+}
+
+func TestDecisionCacheInvalidation(t *testing.T) {
+	enableDecisionCache(t, time.Hour)
+
+	ctx := newTestContext()
+	tenantCode := newTenant(t)
+	userCode := newSubject(t, tenantCode, model.SubjectTypeUser)
+	objectCode := newObject(t, ctx, tenantCode, "")
+	policy := activePolicy(objectCode, "cached")
+	now := time.Now()
+
+	// Checks skip the enforcer and the cache while no policy is loaded at all
+	if err := AssignPoliciesToUser(ctx, userCode, []Policy{activePolicy(objectCode, "other")}); err != nil {
+		t.Fatalf("assign policy: %v", err)
+	}
+
+	steps := []struct {
+		name   string
+		change func() error
+		want   bool
+	}{
+		{"no policy", func() error { return nil }, false},
+		{"policy granted", func() error { return AssignPoliciesToUser(ctx, userCode, []Policy{policy}) }, true},
+		{"user disabled", func() error { return SetEntityStatus(ctx, userCode, EntityUser, model.StatusDisabled) }, false},
+		{"user enabled", func() error { return SetEntityStatus(ctx, userCode, EntityUser, model.StatusEnabled) }, true},
+		{"policy removed", func() error { return RemovePoliciesFromUser(ctx, userCode, []Policy{policy}) }, false},
+		{"policy stored and reloaded", func() error {
+			rule := model.TblCasbinRule{
+				Ptype: "p",
+				V0:    AddPrefix(userCode, EntityUser),
+				V1:    AddPrefix(objectCode, EntityObject),
+				V2:    policy.Action,
+				V3:    FormatWindow(policy.BeginTime, policy.EndTime, ""),
+				V5:    EffectAllow,
+			}
+			if err := database.DB.Create(&rule).Error; err != nil {
+				return err
+			}
+			return LoadPolicy(ctx)
+		}, true},
+	}
+	for _, step := range steps {
+		if err := step.change(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if allowed, hit := checkCached(t, tenantCode, userCode, objectCode, "cached", now); allowed != step.want || hit {
+			t.Fatalf("%s: first check allowed=%v, hit=%v, want allowed=%v from the enforcer", step.name, allowed, hit, step.want)
+		}
+		if allowed, hit := checkCached(t, tenantCode, userCode, objectCode, "cached", now); allowed != step.want || !hit {
+			t.Fatalf("%s: second check allowed=%v, hit=%v, want allowed=%v from the cache", step.name, allowed, hit, step.want)
+		}
+	}
+}
+
+func TestStoreDecisionAfterInvalidation(t *testing.T) {
+	enableDecisionCache(t, time.Hour)
+
+	_, ok, key, generation := cachedDecision("u:racing", "t:racing", "o:racing", "racing", RequestAttributes{}, time.Now())
+	if ok || key == nil {
+		t.Fatalf("cachedDecision() = ok %v, key %v, want a miss with a key", ok, key)
+	}
+
+	// A change lands while the decision is being computed
+	invalidateDecisions()
+	storeDecision(key, generation, true)
+	if entries := GetDecisionCacheStats().Entries; entries != 0 {
+		t.Fatalf("stored a decision computed before an invalidation: entries=%d", entries)
+	}
+}
@@ -1,13 +1,16 @@
 package casbin
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"ac/bootstrap/config"
 	"ac/bootstrap/database"
 	"ac/bootstrap/logger"
 	"ac/model"
@@ -16,19 +19,26 @@ import (
 	casbinModel "github.com/casbin/casbin/v2/model"
 	gormAdapter "github.com/casbin/gorm-adapter/v3"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-// Global enforcer with thread-safe initialization
+// Global enforcer with thread-safe initialization. Reloads build a new enforcer and swap
+// it in, so Enforce always sees a complete policy without waiting for the reload.
 var (
-	enforcer *casbin.TransactionalEnforcer
-	initOnce sync.Once
-	initErr  error
+	enforcer      atomic.Pointer[casbin.TransactionalEnforcer]
+	policyAdapter *gormAdapter.Adapter
+	initOnce      sync.Once
+	initErr       error
 )
 
+// policyMu keeps in-memory policy changes from landing on an enforcer that a reload is
+// about to replace. Changes hold it for reading, reloads for writing.
+var policyMu sync.RWMutex
+
 // Casbin grouping policy identifiers
 const (
-	GroupingUserRole    = "g"  // User-to-Role inheritance
-	GroupingObjectGroup = "g2" // Object-to-Group hierarchy
+	GroupingUserRole    = "g"  // User-to-Role inheritance within a tenant
+	GroupingObjectGroup = "g2" // Object-to-Group hierarchy, rooted at the owning tenant
 )
 
 // Entity type identifiers for validation
@@ -36,6 +46,13 @@ const (
 	EntityUser   = "user"
 	EntityRole   = "role"
 	EntityObject = "object"
+	EntityTenant = "tenant"
+)
+
+// Policy effects, stored in the eft field of p rules
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
 )
 
 // Entity prefixes for Casbin policy identification
@@ -43,6 +60,7 @@ const (
 	PrefixUser   = "u"
 	PrefixRole   = "r"
 	PrefixObject = "o"
+	PrefixTenant = "t"
 
 	PrefixSeparator = ":"
 )
@@ -59,13 +77,28 @@ var (
 	ErrUserAlreadyAssigned    = fmt.Errorf("user already assigned to role")
 	ErrUserNotAssigned        = fmt.Errorf("user not assigned to role")
 	ErrInvalidTimeRange       = fmt.Errorf("end time must be after begin time")
+	ErrInvalidEffect          = fmt.Errorf("effect must be allow or deny")
 	ErrInvalidPolicyFields    = fmt.Errorf("object and action are required")
 	ErrInvalidUserCode        = fmt.Errorf("user code cannot be empty")
 	ErrInvalidRoleCode        = fmt.Errorf("role code cannot be empty")
 	ErrInvalidGroupCode       = fmt.Errorf("group code cannot be empty")
 	ErrInvalidObjectCode      = fmt.Errorf("object code cannot be empty")
+	ErrInvalidTenantCode      = fmt.Errorf("tenant code cannot be empty")
+	ErrHierarchyCycle         = fmt.Errorf("hierarchy cycle detected")
+	ErrInvalidBatchSize       = fmt.Errorf("batch size must be between 1 and %d", MaxBatchCheckSize)
 )
 
+// policyMatcher matches requests against policies regardless of entity status.
+const policyMatcher = `g(r.sub, p.sub, r.dom) && g2(r.obj, r.dom) && g2(r.obj, p.obj) && r.act == p.act && ` +
+	`withinWindow(r.time, p.window) && (p.cond == "" || conditionHolds(p.cond, r.act, r.time, r.user, r.object, r.env))`
+
+// statusMatcher additionally drops allow policies reached through a disabled entity.
+// Deny policies keep applying, so disabling an entity never widens access.
+const statusMatcher = policyMatcher + ` && (p.eft == "deny" || subjectEnabled(r.sub, p.sub, r.dom) && objectEnabled(r.obj, p.obj))`
+
+// MaxBatchCheckSize caps the number of requests evaluated by one batch check.
+const MaxBatchCheckSize = 100
+
 // Initialize creates the Casbin enforcer with RBAC model and GORM adapter.
 // Thread-safe - can be called multiple times without side effects.
 func Initialize() error {
@@ -74,7 +107,8 @@ func Initialize() error {
 
 		gormAdapter.TurnOffAutoMigrate(database.DB)
 
-		adapter, err := gormAdapter.NewAdapterByDBWithCustomTable(
+		var err error
+		policyAdapter, err = gormAdapter.NewAdapterByDBWithCustomTable(
 			database.DB,
 			&model.TblCasbinRule{},
 			model.TableNameTblCasbinRule,
@@ -85,85 +119,423 @@ func Initialize() error {
 			return
 		}
 
-		m, err := casbinModel.NewModelFromString(`
-[request_definition]
-r = sub, obj, act, time
-
-[policy_definition]
-p = sub, obj, act, begin_time, end_time
-
-[role_definition]
-g = _, _ 
-g2 = _, _ 
-
-[policy_effect]
-e = some(where (p.eft == allow))
-
-[matchers]
-m = g(r.sub, p.sub) && g2(r.obj, p.obj) && r.act == p.act && r.time >= p.begin_time && r.time <= p.end_time
-`)
-		if err != nil {
-			initErr = fmt.Errorf("failed to load Casbin model: %w", err)
-			fmt.Fprintf(os.Stderr, "ERROR: casbin: init: load model failed: %v\n", err)
+		if err := loadDisabledEntities(); err != nil {
+			initErr = fmt.Errorf("failed to load disabled entities: %w", err)
+			fmt.Fprintf(os.Stderr, "ERROR: casbin: init: load disabled entities failed: %v\n", err)
 			return
 		}
 
-		enforcer, err = casbin.NewTransactionalEnforcer(m, adapter)
+		if cfg := config.Config().Policy; cfg.LoadMode == PolicyLoadTenant {
+			configureLazyPolicies(cfg.MaxTenants)
+		}
+
+		e, err := newEnforcer()
 		if err != nil {
-			initErr = fmt.Errorf("failed to create Casbin enforcer: %w", err)
+			initErr = err
 			fmt.Fprintf(os.Stderr, "ERROR: casbin: init: create enforcer failed: %v\n", err)
 			return
 		}
+		enforcer.Store(e)
+
+		if cfg := config.Config().DecisionCache; cfg.Size > 0 {
+			bucket, _ := time.ParseDuration(cfg.Bucket)
+			configureDecisionCache(bucket, cfg.Size)
+		}
+		configureDecisionLog(config.Config().DecisionLog)
+
+		if cfg := config.Config().Watcher; cfg.Driver == "database" {
+			interval, _ := time.ParseDuration(cfg.Interval)
+			w, err := NewDBWatcher(interval)
+			if err != nil {
+				initErr = fmt.Errorf("failed to create policy watcher: %w", err)
+				fmt.Fprintf(os.Stderr, "ERROR: casbin: init: create watcher failed: %v\n", err)
+				return
+			}
+			if err := SetWatcher(w); err != nil {
+				initErr = err
+				fmt.Fprintf(os.Stderr, "ERROR: casbin: init: set watcher failed: %v\n", err)
+				return
+			}
+		}
 
 		fmt.Fprintf(os.Stdout, "INFO: casbin: init: succeeded, model=memory, policy_table=tbl_casbin_rule\n")
 	})
 	return initErr
 }
 
-// LoadPolicy refreshes the in-memory policy cache from database.
-// Use after direct database modifications or cache inconsistencies.
-func LoadPolicy(ctx *gin.Context) error {
-	if enforcer == nil {
-		logger.Errorf(ctx, "casbin: load policy failed: enforcer not initialized")
-		return ErrEnforcerNotInitialized
+// newModel parses the Casbin model enforced by this service.
+func newModel() (casbinModel.Model, error) {
+	m, err := casbinModel.NewModelFromString(`
+[request_definition]
+r = sub, dom, obj, act, time, user, object, env
+
+[policy_definition]
+p = sub, obj, act, window, cond, eft
+
+[role_definition]
+g = _, _, _
+g2 = _, _
+
+[policy_effect]
+e = some(where (p.eft == allow)) && !some(where (p.eft == deny))
+
+[matchers]
+m = ` + statusMatcher + `
+`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Casbin model: %w", err)
 	}
+	return m, nil
+}
 
-	logger.Infof(ctx, "casbin: loading policies from database")
-	if err := enforcer.LoadPolicy(); err != nil {
-		logger.Errorf(ctx, "casbin: load policy failed: error=%v", err)
-		return fmt.Errorf("failed to load policies: %w", err)
+// newEnforcer builds an enforcer holding the policies currently stored in the database.
+// When policies load lazily, it starts empty and tenants are loaded into it on first use.
+func newEnforcer() (*casbin.TransactionalEnforcer, error) {
+	m, err := newModel()
+	if err != nil {
+		return nil, err
 	}
-	logger.Infof(ctx, "casbin: policies loaded successfully")
-	return nil
+
+	var e *casbin.TransactionalEnforcer
+	adapter := &hookedAdapter{policyAdapter}
+	if lazyPolicies.enabled {
+		e, err = casbin.NewTransactionalEnforcer(m)
+		if err == nil {
+			e.SetAdapter(adapter)
+		}
+	} else {
+		e, err = casbin.NewTransactionalEnforcer(m, adapter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Casbin enforcer: %w", err)
+	}
+	e.AddFunction(FunctionWithinWindow, withinWindow)
+	e.AddFunction(FunctionConditionHolds, conditionHolds)
+	e.AddFunction(FunctionSubjectEnabled, subjectEnabled)
+	e.AddFunction(FunctionObjectEnabled, objectEnabled)
+	return e, nil
+}
+
+// LoadPolicy refreshes the in-memory policy cache and entity statuses from database.
+// Use after direct database modifications or cache inconsistencies.
+func LoadPolicy(ctx context.Context) error {
+	_, _, err := ReloadPolicy(ctx)
+	return err
 }
 
-// Enforce performs authorization check with time-based policy evaluation.
-// Returns true if access is granted, false if denied.
-func Enforce(ctx *gin.Context, subject, object, action string, currentTime time.Time) (bool, error) {
-	if enforcer == nil {
+// Enforce performs authorization check with time-based policy evaluation within a domain.
+// Policy conditions are evaluated against attributes. Returns true if access is granted, false if denied.
+func Enforce(ctx *gin.Context, subject, domain, object, action string, attributes RequestAttributes, currentTime time.Time) (bool, error) {
+	if enforcer.Load() == nil {
 		logger.Errorf(ctx, "casbin: enforce check failed: enforcer not initialized")
 		return false, ErrEnforcerNotInitialized
 	}
 
 	timeStr := formatTime(currentTime)
-	logger.Debugf(ctx, "casbin: enforce check: subject=%s, object=%s, action=%s, time=%s", subject, object, action, timeStr)
-	allowed, err := enforcer.Enforce(subject, object, action, timeStr)
+	logger.Debugf(ctx, "casbin: enforce check: subject=%s, domain=%s, object=%s, action=%s, time=%s", subject, domain, object, action, timeStr)
+	release, err := ensureTenantsLoaded(ctx, RemovePrefix(domain, EntityTenant))
 	if err != nil {
-		logger.Errorf(ctx, "casbin: enforce check failed: subject=%s, object=%s, action=%s, time=%s, error=%v", subject, object, action, timeStr, err)
-		return false, fmt.Errorf("enforce check failed (subject=%s, object=%s, action=%s, time=%s): %w",
-			subject, object, action, timeStr, err)
+		logger.Errorf(ctx, "casbin: enforce check failed to load tenant policies: domain=%s, error=%v", domain, err)
+		return false, err
+	}
+	defer release()
+
+	e := enforcer.Load()
+	rvals := append([]interface{}{subject, domain, object, action, timeStr}, attributes.rvals()...)
+	if !hasPolicies() {
+		logger.Debugf(ctx, "casbin: enforce result: allowed=false, no policies loaded")
+		recordDecision(ctx, e, rvals, false)
+		return false, nil
+	}
+	allowed, cached, key, generation := cachedDecision(subject, domain, object, action, attributes, currentTime)
+	if cached {
+		logger.Debugf(ctx, "casbin: enforce result: allowed=%v, cached=true, subject=%s, domain=%s, object=%s, action=%s", allowed, subject, domain, object, action)
+		recordDecision(ctx, e, rvals, allowed)
+		return allowed, nil
 	}
-	logger.Debugf(ctx, "casbin: enforce result: allowed=%v, subject=%s, object=%s, action=%s", allowed, subject, object, action)
+	allowed, err = e.Enforce(rvals...)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: enforce check failed: subject=%s, domain=%s, object=%s, action=%s, time=%s, error=%v", subject, domain, object, action, timeStr, err)
+		return false, fmt.Errorf("enforce check failed (subject=%s, domain=%s, object=%s, action=%s, time=%s): %w",
+			subject, domain, object, action, timeStr, err)
+	}
+	storeDecision(key, generation, allowed)
+	recordDecision(ctx, e, rvals, allowed)
+	logger.Debugf(ctx, "casbin: enforce result: allowed=%v, subject=%s, domain=%s, object=%s, action=%s", allowed, subject, domain, object, action)
 	return allowed, nil
 }
 
+// CheckPermission decides whether a user may perform an action on an object of a tenant at the given time.
+// Takes raw entity codes and applies Casbin prefixes before enforcing. env carries the request
+// attributes conditions may read next to the stored user and object attributes.
+// A denial caused by a disabled user, role or object comes with one of the DenyReason values.
+func CheckPermission(ctx *gin.Context, tenantCode, userCode, objectCode, action string, env Attributes, currentTime time.Time) (bool, string, error) {
+	if err := validateCode(tenantCode, EntityTenant); err != nil {
+		logger.Errorf(ctx, "casbin: check permission validation failed: tenant_code=%s, error=%v", tenantCode, err)
+		return false, "", err
+	}
+
+	if err := validateCode(userCode, EntityUser); err != nil {
+		logger.Errorf(ctx, "casbin: check permission validation failed: user_code=%s, error=%v", userCode, err)
+		return false, "", err
+	}
+
+	if err := validateCode(objectCode, EntityObject); err != nil {
+		logger.Errorf(ctx, "casbin: check permission validation failed: object_code=%s, error=%v", objectCode, err)
+		return false, "", err
+	}
+
+	action = strings.TrimSpace(action)
+	if action == "" {
+		logger.Errorf(ctx, "casbin: check permission validation failed: empty action")
+		return false, "", ErrInvalidPolicyFields
+	}
+
+	release, err := ensureTenantsLoaded(ctx, tenantCode)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: check permission failed to load tenant policies: tenant_code=%s, error=%v", tenantCode, err)
+		return false, "", err
+	}
+	defer release()
+
+	userAttributes, objectAttributes, err := loadAttributes(ctx, tenantCode, []string{userCode}, []string{objectCode})
+	if err != nil {
+		logger.Errorf(ctx, "casbin: check permission failed to load attributes: user_code=%s, object_code=%s, error=%v", userCode, objectCode, err)
+		return false, "", err
+	}
+
+	subject := AddPrefix(userCode, EntityUser)
+	domain := AddPrefix(tenantCode, EntityTenant)
+	object := AddPrefix(objectCode, EntityObject)
+	attributes := RequestAttributes{User: userAttributes[userCode], Object: objectAttributes[objectCode], Env: env}
+	allowed, err := Enforce(ctx, subject, domain, object, action, attributes, currentTime)
+	if err != nil || allowed {
+		return allowed, "", err
+	}
+
+	reason, err := disabledDenyReason(subject, domain, object, append([]interface{}{subject, domain, object, action, formatTime(currentTime)}, attributes.rvals()...))
+	if err != nil {
+		logger.Errorf(ctx, "casbin: check permission failed to resolve deny reason: subject=%s, object=%s, action=%s, error=%v", subject, object, action, err)
+		return false, "", fmt.Errorf("failed to resolve deny reason (subject=%s, object=%s, action=%s): %w", subject, object, action, err)
+	}
+	if reason != "" {
+		logger.Debugf(ctx, "casbin: check permission denied by status: subject=%s, object=%s, action=%s, reason=%s", subject, object, action, reason)
+	}
+	return false, reason, nil
+}
+
+// BatchCheckPermission evaluates multiple permission requests of a tenant in a single enforcer pass.
+// Results are returned in the same order as the requests.
+func BatchCheckPermission(ctx *gin.Context, tenantCode string, requests []PermissionRequest, currentTime time.Time) ([]bool, error) {
+	if enforcer.Load() == nil {
+		logger.Errorf(ctx, "casbin: batch check permission failed: enforcer not initialized")
+		return nil, ErrEnforcerNotInitialized
+	}
+
+	if err := validateCode(tenantCode, EntityTenant); err != nil {
+		logger.Errorf(ctx, "casbin: batch check permission validation failed: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+
+	if len(requests) == 0 || len(requests) > MaxBatchCheckSize {
+		logger.Errorf(ctx, "casbin: batch check permission validation failed: request_count=%d", len(requests))
+		return nil, ErrInvalidBatchSize
+	}
+
+	tenantWithPrefix := AddPrefix(tenantCode, EntityTenant)
+	timeStr := formatTime(currentTime)
+	userCodes := make([]string, 0, len(requests))
+	objectCodes := make([]string, 0, len(requests))
+	rvals := make([][]interface{}, len(requests))
+	for i, request := range requests {
+		if err := validateCode(request.UserCode, EntityUser); err != nil {
+			logger.Errorf(ctx, "casbin: batch check permission validation failed: index=%d, user_code=%s, error=%v", i, request.UserCode, err)
+			return nil, fmt.Errorf("invalid request at index %d: %w", i, err)
+		}
+		if err := validateCode(request.ObjectCode, EntityObject); err != nil {
+			logger.Errorf(ctx, "casbin: batch check permission validation failed: index=%d, object_code=%s, error=%v", i, request.ObjectCode, err)
+			return nil, fmt.Errorf("invalid request at index %d: %w", i, err)
+		}
+		action := strings.TrimSpace(request.Action)
+		if action == "" {
+			logger.Errorf(ctx, "casbin: batch check permission validation failed: index=%d, empty action", i)
+			return nil, fmt.Errorf("invalid request at index %d: %w", i, ErrInvalidPolicyFields)
+		}
+
+		rvals[i] = []interface{}{
+			AddPrefix(request.UserCode, EntityUser),
+			tenantWithPrefix,
+			AddPrefix(request.ObjectCode, EntityObject),
+			action,
+			timeStr,
+		}
+		userCodes = append(userCodes, request.UserCode)
+		objectCodes = append(objectCodes, request.ObjectCode)
+	}
+
+	release, err := ensureTenantsLoaded(ctx, tenantCode)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: batch check permission failed to load tenant policies: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+	defer release()
+
+	if !hasPolicies() {
+		logger.Debugf(ctx, "casbin: batch enforce result: no policies loaded, request_count=%d", len(requests))
+		e := enforcer.Load()
+		for i := range requests {
+			recordDecision(ctx, e, rvals[i], false)
+		}
+		return make([]bool, len(requests)), nil
+	}
+
+	userAttributes, objectAttributes, err := loadAttributes(ctx, tenantCode, userCodes, objectCodes)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: batch check permission failed to load attributes: error=%v", err)
+		return nil, err
+	}
+	for i, request := range requests {
+		attributes := RequestAttributes{User: userAttributes[request.UserCode], Object: objectAttributes[request.ObjectCode], Env: request.Attributes}
+		rvals[i] = append(rvals[i], attributes.rvals()...)
+	}
+
+	logger.Debugf(ctx, "casbin: batch enforce check: domain=%s, request_count=%d, time=%s", tenantWithPrefix, len(requests), timeStr)
+	e := enforcer.Load()
+	results, err := e.BatchEnforce(rvals)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: batch enforce check failed: request_count=%d, time=%s, error=%v", len(requests), timeStr, err)
+		return nil, fmt.Errorf("batch enforce check failed (request_count=%d, time=%s): %w", len(requests), timeStr, err)
+	}
+	for i, allowed := range results {
+		recordDecision(ctx, e, rvals[i], allowed)
+	}
+	logger.Debugf(ctx, "casbin: batch enforce result: request_count=%d", len(results))
+	return results, nil
+}
+
+// FilterPermittedObjects returns the subset of objectCodes the user may act on within a tenant at the given time.
+// Evaluates all objects in a single enforcer pass, with env as request attributes.
+func FilterPermittedObjects(ctx *gin.Context, tenantCode, userCode string, objectCodes []string, action string, env Attributes, currentTime time.Time) (map[string]struct{}, error) {
+	if enforcer.Load() == nil {
+		logger.Errorf(ctx, "casbin: filter permitted objects failed: enforcer not initialized")
+		return nil, ErrEnforcerNotInitialized
+	}
+
+	if err := validateCode(tenantCode, EntityTenant); err != nil {
+		logger.Errorf(ctx, "casbin: filter permitted objects validation failed: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+
+	if err := validateCode(userCode, EntityUser); err != nil {
+		logger.Errorf(ctx, "casbin: filter permitted objects validation failed: user_code=%s, error=%v", userCode, err)
+		return nil, err
+	}
+
+	action = strings.TrimSpace(action)
+	if action == "" {
+		logger.Errorf(ctx, "casbin: filter permitted objects validation failed: empty action")
+		return nil, ErrInvalidPolicyFields
+	}
+
+	permitted := make(map[string]struct{})
+	if len(objectCodes) == 0 {
+		return permitted, nil
+	}
+
+	if err := validateCodes(objectCodes, EntityObject); err != nil {
+		logger.Errorf(ctx, "casbin: filter permitted objects validation failed: object_codes=%v, error=%v", objectCodes, err)
+		return nil, err
+	}
+
+	release, err := ensureTenantsLoaded(ctx, tenantCode)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: filter permitted objects failed to load tenant policies: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+	defer release()
+
+	userWithPrefix := AddPrefix(userCode, EntityUser)
+	tenantWithPrefix := AddPrefix(tenantCode, EntityTenant)
+	timeStr := formatTime(currentTime)
+	rvals := make([][]interface{}, len(objectCodes))
+	for i, objectCode := range objectCodes {
+		rvals[i] = []interface{}{userWithPrefix, tenantWithPrefix, AddPrefix(objectCode, EntityObject), action, timeStr}
+	}
+
+	if !hasPolicies() {
+		logger.Debugf(ctx, "casbin: permitted objects filtered: no policies loaded, object_count=%d", len(objectCodes))
+		e := enforcer.Load()
+		for i := range objectCodes {
+			recordDecision(ctx, e, rvals[i], false)
+		}
+		return permitted, nil
+	}
+
+	userAttributes, objectAttributes, err := loadAttributes(ctx, tenantCode, []string{userCode}, objectCodes)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: filter permitted objects failed to load attributes: user_code=%s, error=%v", userCode, err)
+		return nil, err
+	}
+	for i, objectCode := range objectCodes {
+		attributes := RequestAttributes{User: userAttributes[userCode], Object: objectAttributes[objectCode], Env: env}
+		rvals[i] = append(rvals[i], attributes.rvals()...)
+	}
+
+	logger.Debugf(ctx, "casbin: filtering permitted objects: user=%s, action=%s, object_count=%d, time=%s", userWithPrefix, action, len(objectCodes), timeStr)
+	e := enforcer.Load()
+	results, err := e.BatchEnforce(rvals)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: filter permitted objects failed: user=%s, action=%s, error=%v", userWithPrefix, action, err)
+		return nil, fmt.Errorf("filter permitted objects failed (user=%s, action=%s): %w", userWithPrefix, action, err)
+	}
+
+	for i, allowed := range results {
+		recordDecision(ctx, e, rvals[i], allowed)
+		if allowed {
+			permitted[objectCodes[i]] = struct{}{}
+		}
+	}
+	logger.Debugf(ctx, "casbin: permitted objects filtered: user=%s, action=%s, permitted_count=%d", userWithPrefix, action, len(permitted))
+	return permitted, nil
+}
+
+// PermissionRequest identifies a single user/object/action authorization query.
+type PermissionRequest struct {
+	UserCode   string     // User code without Casbin prefix
+	ObjectCode string     // Object code without Casbin prefix
+	Action     string     // Permission type (read, write, etc.)
+	Attributes Attributes // Request attributes conditions may read, optional
+}
+
+// withTransaction runs fn in an enforcer transaction and restores role managers afterwards.
+// Commit swaps in a model copy without role managers and only rebuilds them when
+// grouping rules changed, which would otherwise break g()/g2() after policy-only writes.
+func withTransaction(ctx *gin.Context, fn func(tx *casbin.Transaction) error) error {
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+
+	e := enforcer.Load()
+	if err := e.WithTransaction(ctx, fn); err != nil {
+		return err
+	}
+	invalidateDecisions()
+	notifyPeers(ctx)
+
+	for ptype, assertion := range e.GetModel()["g"] {
+		if assertion.RM == nil {
+			assertion.RM = e.GetNamedRoleManager(ptype)
+		}
+	}
+	return nil
+}
+
 // formatTime standardizes time format for Casbin policy evaluation.
 func formatTime(t time.Time) string {
 	return t.UTC().Format(time.RFC3339)
 }
 
-// addPrefix creates Casbin-compatible entity identifiers.
-func addPrefix(code, entityType string) string {
+// AddPrefix creates Casbin-compatible entity identifiers.
+func AddPrefix(code, entityType string) string {
 	switch entityType {
 	case EntityUser:
 		return PrefixUser + PrefixSeparator + code
@@ -171,13 +543,15 @@ func addPrefix(code, entityType string) string {
 		return PrefixRole + PrefixSeparator + code
 	case EntityObject:
 		return PrefixObject + PrefixSeparator + code
+	case EntityTenant:
+		return PrefixTenant + PrefixSeparator + code
 	default:
 		return code
 	}
 }
 
-// removePrefix extracts raw entity codes from Casbin identifiers.
-func removePrefix(code, entityType string) string {
+// RemovePrefix extracts raw entity codes from Casbin identifiers.
+func RemovePrefix(code, entityType string) string {
 	switch entityType {
 	case EntityUser:
 		return strings.TrimPrefix(code, PrefixUser+PrefixSeparator)
@@ -185,11 +559,30 @@ func removePrefix(code, entityType string) string {
 		return strings.TrimPrefix(code, PrefixRole+PrefixSeparator)
 	case EntityObject:
 		return strings.TrimPrefix(code, PrefixObject+PrefixSeparator)
+	case EntityTenant:
+		return strings.TrimPrefix(code, PrefixTenant+PrefixSeparator)
 	default:
 		return code
 	}
 }
 
+// EntityTypeOf resolves the entity type of a prefixed Casbin identifier.
+// Returns an empty string for identifiers without a known prefix.
+func EntityTypeOf(code string) string {
+	switch {
+	case strings.HasPrefix(code, PrefixUser+PrefixSeparator):
+		return EntityUser
+	case strings.HasPrefix(code, PrefixRole+PrefixSeparator):
+		return EntityRole
+	case strings.HasPrefix(code, PrefixObject+PrefixSeparator):
+		return EntityObject
+	case strings.HasPrefix(code, PrefixTenant+PrefixSeparator):
+		return EntityTenant
+	default:
+		return ""
+	}
+}
+
 // validateCode prevents empty entity identifiers in policy operations.
 func validateCode(code, entityType string) error {
 	if strings.TrimSpace(code) == "" {
@@ -200,6 +593,8 @@ func validateCode(code, entityType string) error {
 			return ErrInvalidRoleCode
 		case EntityObject:
 			return ErrInvalidObjectCode
+		case EntityTenant:
+			return ErrInvalidTenantCode
 		default:
 			return fmt.Errorf("invalid entity type: %s", entityType)
 		}
@@ -217,6 +612,8 @@ func validateCodes(codes []string, entityType string) error {
 			return ErrInvalidRoleCode
 		case EntityObject:
 			return ErrInvalidObjectCode
+		case EntityTenant:
+			return ErrInvalidTenantCode
 		default:
 			return fmt.Errorf("invalid entity type: %s", entityType)
 		}
@@ -238,7 +635,7 @@ func buildPolicyKey(object, action string) string {
 // assignPoliciesToSubject grants access policies to users or roles.
 // Performs duplicate detection and validates time ranges atomically.
 func assignPoliciesToSubject(ctx *gin.Context, subjectCode, subjectType string, policies []Policy) error {
-	if enforcer == nil {
+	if enforcer.Load() == nil {
 		logger.Errorf(ctx, "casbin: assign policies failed: enforcer not initialized")
 		return ErrEnforcerNotInitialized
 	}
@@ -253,10 +650,17 @@ func assignPoliciesToSubject(ctx *gin.Context, subjectCode, subjectType string,
 		return ErrInvalidPolicyFields
 	}
 
-	subjectWithPrefix := addPrefix(subjectCode, subjectType)
+	subjectWithPrefix := AddPrefix(subjectCode, subjectType)
 	logger.Infof(ctx, "casbin: assigning policies: subject_type=%s, subject=%s, policy_count=%d", subjectType, subjectWithPrefix, len(policies))
 
-	existingPolicies, err := enforcer.GetFilteredPolicy(0, subjectWithPrefix)
+	release, err := ensureEntityTenantsLoaded(ctx, subjectWithPrefix)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: assign policies failed to load tenant policies: subject=%s, error=%v", subjectWithPrefix, err)
+		return err
+	}
+	defer release()
+
+	existingPolicies, err := enforcer.Load().GetFilteredPolicy(0, subjectWithPrefix)
 	if err != nil {
 		logger.Errorf(ctx, "casbin: failed to get existing policies: subject=%s, error=%v", subjectWithPrefix, err)
 		return fmt.Errorf("failed to get existing policies for %s: %w", subjectWithPrefix, err)
@@ -275,6 +679,8 @@ func assignPoliciesToSubject(ctx *gin.Context, subjectCode, subjectType string,
 	for i := range policies {
 		policies[i].Object = strings.TrimSpace(policies[i].Object)
 		policies[i].Action = strings.TrimSpace(policies[i].Action)
+		policies[i].Recurrence = NormalizeRecurrence(policies[i].Recurrence)
+		policies[i].Condition = strings.TrimSpace(policies[i].Condition)
 		policy := policies[i]
 
 		if err := policy.Validate(); err != nil {
@@ -286,7 +692,7 @@ func assignPoliciesToSubject(ctx *gin.Context, subjectCode, subjectType string,
 				formatTime(policy.BeginTime), formatTime(policy.EndTime), err)
 		}
 
-		key := buildPolicyKey(policy.Object, policy.Action)
+		key := buildPolicyKey(AddPrefix(policy.Object, EntityObject), policy.Action)
 		if _, exists := existingPolicyMap[key]; exists {
 			logger.Warnf(ctx, "casbin: policy already exists: subject=%s, object=%s, action=%s", subjectWithPrefix, policy.Object, policy.Action)
 			return fmt.Errorf("policy already exists (subject=%s, object=%s, action=%s): %w",
@@ -298,14 +704,15 @@ func assignPoliciesToSubject(ctx *gin.Context, subjectCode, subjectType string,
 	}
 
 	// Add all policies in transaction
-	return enforcer.WithTransaction(ctx, func(tx *casbin.Transaction) error {
+	return withTransaction(ctx, func(tx *casbin.Transaction) error {
 		for _, policy := range policies {
 			_, err := tx.AddPolicy(
 				subjectWithPrefix,
-				policy.Object,
+				AddPrefix(policy.Object, EntityObject),
 				policy.Action,
-				formatTime(policy.BeginTime),
-				formatTime(policy.EndTime),
+				FormatWindow(policy.BeginTime, policy.EndTime, policy.Recurrence),
+				policy.Condition,
+				policy.EffectOrDefault(),
 			)
 			if err != nil {
 				logger.Errorf(ctx, "casbin: failed to add policy: subject=%s, object=%s, action=%s, error=%v",
@@ -313,9 +720,9 @@ func assignPoliciesToSubject(ctx *gin.Context, subjectCode, subjectType string,
 				return fmt.Errorf("failed to add policy (subject=%s, object=%s, action=%s): %w",
 					subjectWithPrefix, policy.Object, policy.Action, err)
 			}
-			logger.Debugf(ctx, "casbin: policy added successfully: subject=%s, object=%s, action=%s, begin=%s, end=%s",
+			logger.Debugf(ctx, "casbin: policy added successfully: subject=%s, object=%s, action=%s, begin=%s, end=%s, recurrence=%s, condition=%s, effect=%s",
 				subjectWithPrefix, policy.Object, policy.Action,
-				formatTime(policy.BeginTime), formatTime(policy.EndTime))
+				formatTime(policy.BeginTime), formatTime(policy.EndTime), policy.Recurrence, policy.Condition, policy.EffectOrDefault())
 		}
 		logger.Infof(ctx, "casbin: policies assigned successfully: subject=%s, count=%d", subjectWithPrefix, len(policies))
 		return nil
@@ -325,7 +732,7 @@ func assignPoliciesToSubject(ctx *gin.Context, subjectCode, subjectType string,
 // removePoliciesFromSubject revokes specific policies from users or roles.
 // Validates policy existence before removal to prevent silent failures.
 func removePoliciesFromSubject(ctx *gin.Context, subjectCode, subjectType string, policies []Policy) error {
-	if enforcer == nil {
+	if enforcer.Load() == nil {
 		logger.Errorf(ctx, "casbin: remove policies failed: enforcer not initialized")
 		return ErrEnforcerNotInitialized
 	}
@@ -335,10 +742,17 @@ func removePoliciesFromSubject(ctx *gin.Context, subjectCode, subjectType string
 		return err
 	}
 
-	subjectWithPrefix := addPrefix(subjectCode, subjectType)
+	subjectWithPrefix := AddPrefix(subjectCode, subjectType)
 	logger.Infof(ctx, "casbin: removing policies: subject_type=%s, subject=%s, policy_count=%d", subjectType, subjectWithPrefix, len(policies))
 
-	existingPolicies, err := enforcer.GetFilteredPolicy(0, subjectWithPrefix)
+	release, err := ensureEntityTenantsLoaded(ctx, subjectWithPrefix)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: remove policies failed to load tenant policies: subject=%s, error=%v", subjectWithPrefix, err)
+		return err
+	}
+	defer release()
+
+	existingPolicies, err := enforcer.Load().GetFilteredPolicy(0, subjectWithPrefix)
 	if err != nil {
 		logger.Errorf(ctx, "casbin: failed to get existing policies for removal: subject=%s, error=%v", subjectWithPrefix, err)
 		return fmt.Errorf("failed to get policies for %s: %w", subjectWithPrefix, err)
@@ -367,7 +781,7 @@ func removePoliciesFromSubject(ctx *gin.Context, subjectCode, subjectType string
 				formatTime(policy.BeginTime), formatTime(policy.EndTime), err)
 		}
 
-		key := buildPolicyKey(policy.Object, policy.Action)
+		key := buildPolicyKey(AddPrefix(policy.Object, EntityObject), policy.Action)
 		if _, exists := existingPolicyMap[key]; !exists {
 			logger.Warnf(ctx, "casbin: policy not found for removal: subject=%s, object=%s, action=%s", subjectWithPrefix, policy.Object, policy.Action)
 			return fmt.Errorf("policy not found (subject=%s, object=%s, action=%s): %w",
@@ -375,16 +789,15 @@ func removePoliciesFromSubject(ctx *gin.Context, subjectCode, subjectType string
 		}
 	}
 
-	// Remove all policies in transaction
-	return enforcer.WithTransaction(ctx, func(tx *casbin.Transaction) error {
+	// Remove all policies in transaction, using the stored rule so its effect need not be known
+	return withTransaction(ctx, func(tx *casbin.Transaction) error {
 		for _, policy := range policies {
-			_, err := tx.RemovePolicy(
-				subjectWithPrefix,
-				policy.Object,
-				policy.Action,
-				formatTime(policy.BeginTime),
-				formatTime(policy.EndTime),
-			)
+			storedFields := existingPolicyMap[buildPolicyKey(AddPrefix(policy.Object, EntityObject), policy.Action)]
+			rule := make([]interface{}, len(storedFields))
+			for i, field := range storedFields {
+				rule[i] = field
+			}
+			_, err := tx.RemovePolicy(rule...)
 			if err != nil {
 				logger.Errorf(ctx, "casbin: failed to remove policy: subject=%s, object=%s, action=%s, error=%v",
 					subjectWithPrefix, policy.Object, policy.Action, err)
@@ -399,14 +812,119 @@ func removePoliciesFromSubject(ctx *gin.Context, subjectCode, subjectType string
 	})
 }
 
-// AssignRolesToUser grants multiple roles to a user atomically.
+// UpdatePolicy replaces the policy a user or role holds on old's object and action with updated,
+// removing the old rule and adding the new one in a single transaction so a failure keeps the old rule.
+// Hooks registered with OnCommit run within that transaction.
+func UpdatePolicy(ctx *gin.Context, subjectCode, subjectType string, old, updated Policy) error {
+	if enforcer.Load() == nil {
+		logger.Errorf(ctx, "casbin: update policy failed: enforcer not initialized")
+		return ErrEnforcerNotInitialized
+	}
+
+	if err := validateCode(subjectCode, subjectType); err != nil {
+		logger.Errorf(ctx, "casbin: update policy validation failed: subject_type=%s, subject_code=%s, error=%v", subjectType, subjectCode, err)
+		return err
+	}
+
+	if subjectType != EntityUser && subjectType != EntityRole {
+		logger.Errorf(ctx, "casbin: update policy failed: invalid subject type=%s", subjectType)
+		return ErrInvalidPolicyFields
+	}
+
+	updated.Object = strings.TrimSpace(updated.Object)
+	updated.Action = strings.TrimSpace(updated.Action)
+	updated.Recurrence = NormalizeRecurrence(updated.Recurrence)
+	updated.Condition = strings.TrimSpace(updated.Condition)
+
+	subjectWithPrefix := AddPrefix(subjectCode, subjectType)
+	if err := updated.Validate(); err != nil {
+		logger.Errorf(ctx, "casbin: policy validation failed during update: subject=%s, object=%s, action=%s, begin=%s, end=%s, error=%v",
+			subjectWithPrefix, updated.Object, updated.Action,
+			formatTime(updated.BeginTime), formatTime(updated.EndTime), err)
+		return fmt.Errorf("invalid policy (subject=%s, object=%s, action=%s, begin=%s, end=%s): %w",
+			subjectWithPrefix, updated.Object, updated.Action,
+			formatTime(updated.BeginTime), formatTime(updated.EndTime), err)
+	}
+
+	release, err := ensureEntityTenantsLoaded(ctx, subjectWithPrefix)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: update policy failed to load tenant policies: subject=%s, error=%v", subjectWithPrefix, err)
+		return err
+	}
+	defer release()
+
+	existingPolicies, err := enforcer.Load().GetFilteredPolicy(0, subjectWithPrefix)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: failed to get existing policies for update: subject=%s, error=%v", subjectWithPrefix, err)
+		return fmt.Errorf("failed to get policies for %s: %w", subjectWithPrefix, err)
+	}
+
+	existingPolicyMap := make(map[string][]string)
+	for _, policyFields := range existingPolicies {
+		if len(policyFields) >= 5 {
+			key := buildPolicyKey(policyFields[1], policyFields[2])
+			existingPolicyMap[key] = policyFields
+		}
+	}
+
+	oldKey := buildPolicyKey(AddPrefix(strings.TrimSpace(old.Object), EntityObject), strings.TrimSpace(old.Action))
+	storedFields, exists := existingPolicyMap[oldKey]
+	if !exists {
+		logger.Warnf(ctx, "casbin: policy not found for update: subject=%s, object=%s, action=%s", subjectWithPrefix, old.Object, old.Action)
+		return fmt.Errorf("policy not found (subject=%s, object=%s, action=%s): %w",
+			subjectWithPrefix, old.Object, old.Action, ErrPolicyNotFound)
+	}
+	if key := buildPolicyKey(AddPrefix(updated.Object, EntityObject), updated.Action); key != oldKey {
+		if _, exists := existingPolicyMap[key]; exists {
+			logger.Warnf(ctx, "casbin: policy already exists: subject=%s, object=%s, action=%s", subjectWithPrefix, updated.Object, updated.Action)
+			return fmt.Errorf("policy already exists (subject=%s, object=%s, action=%s): %w",
+				subjectWithPrefix, updated.Object, updated.Action, ErrPolicyAlreadyExists)
+		}
+	}
+
+	return withTransaction(ctx, func(tx *casbin.Transaction) error {
+		rule := make([]interface{}, len(storedFields))
+		for i, field := range storedFields {
+			rule[i] = field
+		}
+		if _, err := tx.RemovePolicy(rule...); err != nil {
+			logger.Errorf(ctx, "casbin: failed to remove policy for update: subject=%s, object=%s, action=%s, error=%v",
+				subjectWithPrefix, old.Object, old.Action, err)
+			return fmt.Errorf("failed to remove policy (subject=%s, object=%s, action=%s): %w",
+				subjectWithPrefix, old.Object, old.Action, err)
+		}
+		if _, err := tx.AddPolicy(
+			subjectWithPrefix,
+			AddPrefix(updated.Object, EntityObject),
+			updated.Action,
+			FormatWindow(updated.BeginTime, updated.EndTime, updated.Recurrence),
+			updated.Condition,
+			updated.EffectOrDefault(),
+		); err != nil {
+			logger.Errorf(ctx, "casbin: failed to add policy for update: subject=%s, object=%s, action=%s, error=%v",
+				subjectWithPrefix, updated.Object, updated.Action, err)
+			return fmt.Errorf("failed to add policy (subject=%s, object=%s, action=%s): %w",
+				subjectWithPrefix, updated.Object, updated.Action, err)
+		}
+		logger.Infof(ctx, "casbin: policy updated successfully: subject=%s, object=%s, action=%s -> %s",
+			subjectWithPrefix, updated.Object, old.Action, updated.Action)
+		return nil
+	})
+}
+
+// AssignRolesToUser grants multiple roles to a user within a tenant atomically.
 // Prevents duplicate role assignments within the same transaction.
-func AssignRolesToUser(ctx *gin.Context, userCode string, roleCodes []string) error {
-	if enforcer == nil {
+func AssignRolesToUser(ctx *gin.Context, tenantCode, userCode string, roleCodes []string) error {
+	if enforcer.Load() == nil {
 		logger.Errorf(ctx, "casbin: assign roles to user failed: enforcer not initialized")
 		return ErrEnforcerNotInitialized
 	}
 
+	if err := validateCode(tenantCode, EntityTenant); err != nil {
+		logger.Errorf(ctx, "casbin: assign roles to user validation failed: tenant_code=%s, error=%v", tenantCode, err)
+		return err
+	}
+
 	if err := validateCode(userCode, EntityUser); err != nil {
 		logger.Errorf(ctx, "casbin: assign roles to user validation failed: user_code=%s, error=%v", userCode, err)
 		return err
@@ -417,13 +935,21 @@ func AssignRolesToUser(ctx *gin.Context, userCode string, roleCodes []string) er
 		return err
 	}
 
-	userWithPrefix := addPrefix(userCode, EntityUser)
-	logger.Infof(ctx, "casbin: assigning roles to user: user=%s, role_count=%d", userWithPrefix, len(roleCodes))
+	userWithPrefix := AddPrefix(userCode, EntityUser)
+	tenantWithPrefix := AddPrefix(tenantCode, EntityTenant)
+	logger.Infof(ctx, "casbin: assigning roles to user: user=%s, domain=%s, role_count=%d", userWithPrefix, tenantWithPrefix, len(roleCodes))
 
-	existingGroupings, err := enforcer.GetFilteredNamedGroupingPolicy(GroupingUserRole, 0, userWithPrefix)
+	release, err := ensureTenantsLoaded(ctx, tenantCode)
 	if err != nil {
-		logger.Errorf(ctx, "casbin: failed to get existing roles for user: user=%s, error=%v", userWithPrefix, err)
-		return fmt.Errorf("failed to get existing roles for user %s: %w", userWithPrefix, err)
+		logger.Errorf(ctx, "casbin: assign roles to user failed to load tenant policies: tenant_code=%s, error=%v", tenantCode, err)
+		return err
+	}
+	defer release()
+
+	existingGroupings, err := enforcer.Load().GetFilteredNamedGroupingPolicy(GroupingUserRole, 0, userWithPrefix, "", tenantWithPrefix)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: failed to get existing roles for user: user=%s, domain=%s, error=%v", userWithPrefix, tenantWithPrefix, err)
+		return fmt.Errorf("failed to get existing roles for user %s in %s: %w", userWithPrefix, tenantWithPrefix, err)
 	}
 
 	existingRoles := make(map[string]struct{})
@@ -435,39 +961,112 @@ func AssignRolesToUser(ctx *gin.Context, userCode string, roleCodes []string) er
 
 	// Check for duplicates before adding
 	for _, roleCode := range roleCodes {
-		roleWithPrefix := addPrefix(roleCode, EntityRole)
+		roleWithPrefix := AddPrefix(roleCode, EntityRole)
 		if _, exists := existingRoles[roleWithPrefix]; exists {
-			logger.Warnf(ctx, "casbin: role already assigned to user: user=%s, role=%s", userWithPrefix, roleWithPrefix)
-			return fmt.Errorf("role already assigned (user=%s, role=%s): %w",
-				userWithPrefix, roleWithPrefix, ErrRoleAlreadyAssigned)
+			logger.Warnf(ctx, "casbin: role already assigned to user: user=%s, role=%s, domain=%s", userWithPrefix, roleWithPrefix, tenantWithPrefix)
+			return fmt.Errorf("role already assigned (user=%s, role=%s, domain=%s): %w",
+				userWithPrefix, roleWithPrefix, tenantWithPrefix, ErrRoleAlreadyAssigned)
 		}
 	}
 
 	// Add all role assignments in transaction
-	return enforcer.WithTransaction(ctx, func(tx *casbin.Transaction) error {
+	return withTransaction(ctx, func(tx *casbin.Transaction) error {
 		for _, roleCode := range roleCodes {
-			roleWithPrefix := addPrefix(roleCode, EntityRole)
-			_, err := tx.AddNamedGroupingPolicy(GroupingUserRole, userWithPrefix, roleWithPrefix)
+			roleWithPrefix := AddPrefix(roleCode, EntityRole)
+			_, err := tx.AddNamedGroupingPolicy(GroupingUserRole, userWithPrefix, roleWithPrefix, tenantWithPrefix)
 			if err != nil {
-				logger.Errorf(ctx, "casbin: failed to assign role to user: user=%s, role=%s, error=%v", userWithPrefix, roleWithPrefix, err)
-				return fmt.Errorf("failed to assign role (user=%s, role=%s): %w",
-					userWithPrefix, roleWithPrefix, err)
+				logger.Errorf(ctx, "casbin: failed to assign role to user: user=%s, role=%s, domain=%s, error=%v", userWithPrefix, roleWithPrefix, tenantWithPrefix, err)
+				return fmt.Errorf("failed to assign role (user=%s, role=%s, domain=%s): %w",
+					userWithPrefix, roleWithPrefix, tenantWithPrefix, err)
 			}
-			logger.Debugf(ctx, "casbin: role assigned to user: user=%s, role=%s", userWithPrefix, roleWithPrefix)
+			logger.Debugf(ctx, "casbin: role assigned to user: user=%s, role=%s, domain=%s", userWithPrefix, roleWithPrefix, tenantWithPrefix)
 		}
-		logger.Infof(ctx, "casbin: roles assigned to user successfully: user=%s, count=%d", userWithPrefix, len(roleCodes))
+		logger.Infof(ctx, "casbin: roles assigned to user successfully: user=%s, domain=%s, count=%d", userWithPrefix, tenantWithPrefix, len(roleCodes))
 		return nil
 	})
 }
 
-// RemoveRolesFromUser revokes multiple roles from a user atomically.
+// AssignRolesToUserTx grants multiple roles to a user within a tenant like AssignRolesToUser,
+// but writes the assignments within tx so they commit or roll back together with the caller's
+// other writes. Call SyncChangedRules once tx has committed.
+func AssignRolesToUserTx(ctx *gin.Context, tx *gorm.DB, tenantCode, userCode string, roleCodes []string) (*ChangedRules, error) {
+	if enforcer.Load() == nil {
+		logger.Errorf(ctx, "casbin: assign roles to user failed: enforcer not initialized")
+		return nil, ErrEnforcerNotInitialized
+	}
+
+	if err := validateCode(tenantCode, EntityTenant); err != nil {
+		logger.Errorf(ctx, "casbin: assign roles to user validation failed: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+
+	if err := validateCode(userCode, EntityUser); err != nil {
+		logger.Errorf(ctx, "casbin: assign roles to user validation failed: user_code=%s, error=%v", userCode, err)
+		return nil, err
+	}
+
+	if err := validateCodes(roleCodes, EntityRole); err != nil {
+		logger.Errorf(ctx, "casbin: assign roles to user validation failed: role_codes=%v, error=%v", roleCodes, err)
+		return nil, err
+	}
+
+	userWithPrefix := AddPrefix(userCode, EntityUser)
+	tenantWithPrefix := AddPrefix(tenantCode, EntityTenant)
+	logger.Infof(ctx, "casbin: assigning roles to user: user=%s, domain=%s, role_count=%d", userWithPrefix, tenantWithPrefix, len(roleCodes))
+
+	release, err := ensureTenantsLoaded(ctx, tenantCode)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: assign roles to user failed to load tenant policies: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+	defer release()
+
+	var existingRoles []string
+	if err := tx.WithContext(ctx).Model(&model.TblCasbinRule{}).
+		Where("ptype = ? AND v0 = ? AND v2 = ?", GroupingUserRole, userWithPrefix, tenantWithPrefix).
+		Pluck("v1", &existingRoles).Error; err != nil {
+		logger.Errorf(ctx, "casbin: failed to get existing roles for user: user=%s, domain=%s, error=%v", userWithPrefix, tenantWithPrefix, err)
+		return nil, fmt.Errorf("failed to get existing roles for user %s in %s: %w", userWithPrefix, tenantWithPrefix, err)
+	}
+
+	existing := make(map[string]struct{}, len(existingRoles))
+	for _, role := range existingRoles {
+		existing[role] = struct{}{}
+	}
+
+	rules := make([]model.TblCasbinRule, 0, len(roleCodes))
+	for _, roleCode := range roleCodes {
+		roleWithPrefix := AddPrefix(roleCode, EntityRole)
+		if _, exists := existing[roleWithPrefix]; exists {
+			logger.Warnf(ctx, "casbin: role already assigned to user: user=%s, role=%s, domain=%s", userWithPrefix, roleWithPrefix, tenantWithPrefix)
+			return nil, fmt.Errorf("role already assigned (user=%s, role=%s, domain=%s): %w",
+				userWithPrefix, roleWithPrefix, tenantWithPrefix, ErrRoleAlreadyAssigned)
+		}
+		rules = append(rules, model.TblCasbinRule{Ptype: GroupingUserRole, V0: userWithPrefix, V1: roleWithPrefix, V2: tenantWithPrefix})
+	}
+
+	changed := &ChangedRules{}
+	if err := changed.add(ctx, tx, rules...); err != nil {
+		logger.Errorf(ctx, "casbin: failed to assign roles to user: user=%s, domain=%s, error=%v", userWithPrefix, tenantWithPrefix, err)
+		return nil, fmt.Errorf("failed to assign roles (user=%s, domain=%s): %w", userWithPrefix, tenantWithPrefix, err)
+	}
+	logger.Infof(ctx, "casbin: roles assigned to user successfully: user=%s, domain=%s, count=%d", userWithPrefix, tenantWithPrefix, len(roleCodes))
+	return changed, nil
+}
+
+// RemoveRolesFromUser revokes multiple roles from a user within a tenant atomically.
 // Validates role assignments before removal to catch inconsistencies.
-func RemoveRolesFromUser(ctx *gin.Context, userCode string, roleCodes []string) error {
-	if enforcer == nil {
+func RemoveRolesFromUser(ctx *gin.Context, tenantCode, userCode string, roleCodes []string) error {
+	if enforcer.Load() == nil {
 		logger.Errorf(ctx, "casbin: remove roles from user failed: enforcer not initialized")
 		return ErrEnforcerNotInitialized
 	}
 
+	if err := validateCode(tenantCode, EntityTenant); err != nil {
+		logger.Errorf(ctx, "casbin: remove roles from user validation failed: tenant_code=%s, error=%v", tenantCode, err)
+		return err
+	}
+
 	if err := validateCode(userCode, EntityUser); err != nil {
 		logger.Errorf(ctx, "casbin: remove roles from user validation failed: user_code=%s, error=%v", userCode, err)
 		return err
@@ -478,13 +1077,21 @@ func RemoveRolesFromUser(ctx *gin.Context, userCode string, roleCodes []string)
 		return err
 	}
 
-	userWithPrefix := addPrefix(userCode, EntityUser)
-	logger.Infof(ctx, "casbin: removing roles from user: user=%s, role_count=%d", userWithPrefix, len(roleCodes))
+	userWithPrefix := AddPrefix(userCode, EntityUser)
+	tenantWithPrefix := AddPrefix(tenantCode, EntityTenant)
+	logger.Infof(ctx, "casbin: removing roles from user: user=%s, domain=%s, role_count=%d", userWithPrefix, tenantWithPrefix, len(roleCodes))
 
-	existingGroupings, err := enforcer.GetFilteredNamedGroupingPolicy(GroupingUserRole, 0, userWithPrefix)
+	release, err := ensureTenantsLoaded(ctx, tenantCode)
 	if err != nil {
-		logger.Errorf(ctx, "casbin: failed to get existing roles for removal: user=%s, error=%v", userWithPrefix, err)
-		return fmt.Errorf("failed to get existing roles for user %s: %w", userWithPrefix, err)
+		logger.Errorf(ctx, "casbin: remove roles from user failed to load tenant policies: tenant_code=%s, error=%v", tenantCode, err)
+		return err
+	}
+	defer release()
+
+	existingGroupings, err := enforcer.Load().GetFilteredNamedGroupingPolicy(GroupingUserRole, 0, userWithPrefix, "", tenantWithPrefix)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: failed to get existing roles for removal: user=%s, domain=%s, error=%v", userWithPrefix, tenantWithPrefix, err)
+		return fmt.Errorf("failed to get existing roles for user %s in %s: %w", userWithPrefix, tenantWithPrefix, err)
 	}
 
 	existingRoles := make(map[string]struct{})
@@ -496,57 +1103,70 @@ func RemoveRolesFromUser(ctx *gin.Context, userCode string, roleCodes []string)
 
 	// Verify all roles exist before removing
 	for _, roleCode := range roleCodes {
-		roleWithPrefix := addPrefix(roleCode, EntityRole)
+		roleWithPrefix := AddPrefix(roleCode, EntityRole)
 		if _, exists := existingRoles[roleWithPrefix]; !exists {
-			logger.Warnf(ctx, "casbin: role not assigned to user for removal: user=%s, role=%s", userWithPrefix, roleWithPrefix)
-			return fmt.Errorf("role not assigned (user=%s, role=%s): %w",
-				userWithPrefix, roleWithPrefix, ErrRoleNotAssigned)
+			logger.Warnf(ctx, "casbin: role not assigned to user for removal: user=%s, role=%s, domain=%s", userWithPrefix, roleWithPrefix, tenantWithPrefix)
+			return fmt.Errorf("role not assigned (user=%s, role=%s, domain=%s): %w",
+				userWithPrefix, roleWithPrefix, tenantWithPrefix, ErrRoleNotAssigned)
 		}
 	}
 
 	// Remove all role assignments in transaction
-	return enforcer.WithTransaction(ctx, func(tx *casbin.Transaction) error {
+	return withTransaction(ctx, func(tx *casbin.Transaction) error {
 		for _, roleCode := range roleCodes {
-			roleWithPrefix := addPrefix(roleCode, EntityRole)
-			_, err := tx.RemoveNamedGroupingPolicy(GroupingUserRole, userWithPrefix, roleWithPrefix)
+			roleWithPrefix := AddPrefix(roleCode, EntityRole)
+			_, err := tx.RemoveNamedGroupingPolicy(GroupingUserRole, userWithPrefix, roleWithPrefix, tenantWithPrefix)
 			if err != nil {
-				logger.Errorf(ctx, "casbin: failed to remove role from user: user=%s, role=%s, error=%v", userWithPrefix, roleWithPrefix, err)
-				return fmt.Errorf("failed to remove role (user=%s, role=%s): %w",
-					userWithPrefix, roleWithPrefix, err)
+				logger.Errorf(ctx, "casbin: failed to remove role from user: user=%s, role=%s, domain=%s, error=%v", userWithPrefix, roleWithPrefix, tenantWithPrefix, err)
+				return fmt.Errorf("failed to remove role (user=%s, role=%s, domain=%s): %w",
+					userWithPrefix, roleWithPrefix, tenantWithPrefix, err)
 			}
-			logger.Debugf(ctx, "casbin: role removed from user: user=%s, role=%s", userWithPrefix, roleWithPrefix)
+			logger.Debugf(ctx, "casbin: role removed from user: user=%s, role=%s, domain=%s", userWithPrefix, roleWithPrefix, tenantWithPrefix)
 		}
-		logger.Infof(ctx, "casbin: roles removed from user successfully: user=%s, count=%d", userWithPrefix, len(roleCodes))
+		logger.Infof(ctx, "casbin: roles removed from user successfully: user=%s, domain=%s, count=%d", userWithPrefix, tenantWithPrefix, len(roleCodes))
 		return nil
 	})
 }
 
-// GetRolesForUser retrieves all roles for a user, including inherited ones.
+// GetRolesForUser retrieves all roles for a user within a tenant, including inherited ones.
 // Returns deduplicated and sorted role codes.
-func GetRolesForUser(ctx *gin.Context, userCode string) ([]string, error) {
-	if enforcer == nil {
+func GetRolesForUser(ctx *gin.Context, tenantCode, userCode string) ([]string, error) {
+	if enforcer.Load() == nil {
 		logger.Errorf(ctx, "casbin: get roles for user failed: enforcer not initialized")
 		return nil, ErrEnforcerNotInitialized
 	}
 
+	if err := validateCode(tenantCode, EntityTenant); err != nil {
+		logger.Errorf(ctx, "casbin: get roles for user validation failed: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+
 	if err := validateCode(userCode, EntityUser); err != nil {
 		logger.Errorf(ctx, "casbin: get roles for user validation failed: user_code=%s, error=%v", userCode, err)
 		return nil, err
 	}
 
-	userWithPrefix := addPrefix(userCode, EntityUser)
-	logger.Debugf(ctx, "casbin: getting roles for user: user=%s", userWithPrefix)
+	userWithPrefix := AddPrefix(userCode, EntityUser)
+	tenantWithPrefix := AddPrefix(tenantCode, EntityTenant)
+	logger.Debugf(ctx, "casbin: getting roles for user: user=%s, domain=%s", userWithPrefix, tenantWithPrefix)
 
-	rolesWithPrefix, err := enforcer.GetImplicitRolesForUser(userWithPrefix)
+	release, err := ensureTenantsLoaded(ctx, tenantCode)
 	if err != nil {
-		logger.Errorf(ctx, "casbin: failed to get roles for user: user=%s, error=%v", userWithPrefix, err)
-		return nil, fmt.Errorf("failed to get roles for user %s: %w", userWithPrefix, err)
+		logger.Errorf(ctx, "casbin: get roles for user failed to load tenant policies: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+	defer release()
+
+	rolesWithPrefix, err := enforcer.Load().GetImplicitRolesForUser(userWithPrefix, tenantWithPrefix)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: failed to get roles for user: user=%s, domain=%s, error=%v", userWithPrefix, tenantWithPrefix, err)
+		return nil, fmt.Errorf("failed to get roles for user %s in %s: %w", userWithPrefix, tenantWithPrefix, err)
 	}
 
 	// Remove duplicates and prefixes
 	roleSet := make(map[string]struct{}, len(rolesWithPrefix))
 	for _, role := range rolesWithPrefix {
-		roleSet[removePrefix(role, EntityRole)] = struct{}{}
+		roleSet[RemovePrefix(role, EntityRole)] = struct{}{}
 	}
 
 	roles := make([]string, 0, len(roleSet))
@@ -555,17 +1175,21 @@ func GetRolesForUser(ctx *gin.Context, userCode string) ([]string, error) {
 	}
 	sort.Strings(roles)
 
-	logger.Debugf(ctx, "casbin: retrieved roles for user: user=%s, role_count=%d, roles=%v", userWithPrefix, len(roles), roles)
+	logger.Debugf(ctx, "casbin: retrieved roles for user: user=%s, domain=%s, role_count=%d, roles=%v", userWithPrefix, tenantWithPrefix, len(roles), roles)
 	return roles, nil
 }
 
-// AssignUsersToRole grants a role to multiple users atomically.
+// AssignUsersToRole grants a role to multiple users within a tenant atomically.
 // Performs reverse duplicate checking (user-to-role vs role-to-user).
-func AssignUsersToRole(ctx *gin.Context, roleCode string, userCodes []string) error {
-	if enforcer == nil {
+func AssignUsersToRole(ctx *gin.Context, tenantCode, roleCode string, userCodes []string) error {
+	if enforcer.Load() == nil {
 		return ErrEnforcerNotInitialized
 	}
 
+	if err := validateCode(tenantCode, EntityTenant); err != nil {
+		return err
+	}
+
 	if err := validateCode(roleCode, EntityRole); err != nil {
 		return err
 	}
@@ -574,10 +1198,18 @@ func AssignUsersToRole(ctx *gin.Context, roleCode string, userCodes []string) er
 		return err
 	}
 
-	roleWithPrefix := addPrefix(roleCode, EntityRole)
-	existingGroupings, err := enforcer.GetFilteredNamedGroupingPolicy(GroupingUserRole, 1, roleWithPrefix)
+	roleWithPrefix := AddPrefix(roleCode, EntityRole)
+	tenantWithPrefix := AddPrefix(tenantCode, EntityTenant)
+	release, err := ensureTenantsLoaded(ctx, tenantCode)
 	if err != nil {
-		return fmt.Errorf("failed to get existing users for role %s: %w", roleWithPrefix, err)
+		logger.Errorf(ctx, "casbin: assign users to role failed to load tenant policies: tenant_code=%s, error=%v", tenantCode, err)
+		return err
+	}
+	defer release()
+
+	existingGroupings, err := enforcer.Load().GetFilteredNamedGroupingPolicy(GroupingUserRole, 1, roleWithPrefix, tenantWithPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to get existing users for role %s in %s: %w", roleWithPrefix, tenantWithPrefix, err)
 	}
 
 	existingUsers := make(map[string]struct{})
@@ -589,34 +1221,38 @@ func AssignUsersToRole(ctx *gin.Context, roleCode string, userCodes []string) er
 
 	// Check for duplicates before adding
 	for _, userCode := range userCodes {
-		userWithPrefix := addPrefix(userCode, EntityUser)
+		userWithPrefix := AddPrefix(userCode, EntityUser)
 		if _, exists := existingUsers[userWithPrefix]; exists {
-			return fmt.Errorf("user already assigned (role=%s, user=%s): %w",
-				roleWithPrefix, userWithPrefix, ErrUserAlreadyAssigned)
+			return fmt.Errorf("user already assigned (role=%s, user=%s, domain=%s): %w",
+				roleWithPrefix, userWithPrefix, tenantWithPrefix, ErrUserAlreadyAssigned)
 		}
 	}
 
 	// Add all user assignments in transaction
-	return enforcer.WithTransaction(ctx, func(tx *casbin.Transaction) error {
+	return withTransaction(ctx, func(tx *casbin.Transaction) error {
 		for _, userCode := range userCodes {
-			userWithPrefix := addPrefix(userCode, EntityUser)
-			_, err := tx.AddNamedGroupingPolicy(GroupingUserRole, userWithPrefix, roleWithPrefix)
+			userWithPrefix := AddPrefix(userCode, EntityUser)
+			_, err := tx.AddNamedGroupingPolicy(GroupingUserRole, userWithPrefix, roleWithPrefix, tenantWithPrefix)
 			if err != nil {
-				return fmt.Errorf("failed to assign user (role=%s, user=%s): %w",
-					roleWithPrefix, userWithPrefix, err)
+				return fmt.Errorf("failed to assign user (role=%s, user=%s, domain=%s): %w",
+					roleWithPrefix, userWithPrefix, tenantWithPrefix, err)
 			}
 		}
 		return nil
 	})
 }
 
-// RemoveUsersFromRole revokes a role from multiple users atomically.
+// RemoveUsersFromRole revokes a role from multiple users within a tenant atomically.
 // Use for bulk user removal operations.
-func RemoveUsersFromRole(ctx *gin.Context, roleCode string, userCodes []string) error {
-	if enforcer == nil {
+func RemoveUsersFromRole(ctx *gin.Context, tenantCode, roleCode string, userCodes []string) error {
+	if enforcer.Load() == nil {
 		return ErrEnforcerNotInitialized
 	}
 
+	if err := validateCode(tenantCode, EntityTenant); err != nil {
+		return err
+	}
+
 	if err := validateCode(roleCode, EntityRole); err != nil {
 		return err
 	}
@@ -625,10 +1261,18 @@ func RemoveUsersFromRole(ctx *gin.Context, roleCode string, userCodes []string)
 		return err
 	}
 
-	roleWithPrefix := addPrefix(roleCode, EntityRole)
-	existingGroupings, err := enforcer.GetFilteredNamedGroupingPolicy(GroupingUserRole, 1, roleWithPrefix)
+	roleWithPrefix := AddPrefix(roleCode, EntityRole)
+	tenantWithPrefix := AddPrefix(tenantCode, EntityTenant)
+	release, err := ensureTenantsLoaded(ctx, tenantCode)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: remove users from role failed to load tenant policies: tenant_code=%s, error=%v", tenantCode, err)
+		return err
+	}
+	defer release()
+
+	existingGroupings, err := enforcer.Load().GetFilteredNamedGroupingPolicy(GroupingUserRole, 1, roleWithPrefix, tenantWithPrefix)
 	if err != nil {
-		return fmt.Errorf("failed to get existing users for role %s: %w", roleWithPrefix, err)
+		return fmt.Errorf("failed to get existing users for role %s in %s: %w", roleWithPrefix, tenantWithPrefix, err)
 	}
 
 	existingUsers := make(map[string]struct{})
@@ -640,49 +1284,64 @@ func RemoveUsersFromRole(ctx *gin.Context, roleCode string, userCodes []string)
 
 	// Verify all users exist before removing
 	for _, userCode := range userCodes {
-		userWithPrefix := addPrefix(userCode, EntityUser)
+		userWithPrefix := AddPrefix(userCode, EntityUser)
 		if _, exists := existingUsers[userWithPrefix]; !exists {
-			return fmt.Errorf("user not assigned (role=%s, user=%s): %w",
-				roleWithPrefix, userWithPrefix, ErrUserNotAssigned)
+			return fmt.Errorf("user not assigned (role=%s, user=%s, domain=%s): %w",
+				roleWithPrefix, userWithPrefix, tenantWithPrefix, ErrUserNotAssigned)
 		}
 	}
 
 	// Remove all user assignments in transaction
-	return enforcer.WithTransaction(ctx, func(tx *casbin.Transaction) error {
+	return withTransaction(ctx, func(tx *casbin.Transaction) error {
 		for _, userCode := range userCodes {
-			userWithPrefix := addPrefix(userCode, EntityUser)
-			_, err := tx.RemoveNamedGroupingPolicy(GroupingUserRole, userWithPrefix, roleWithPrefix)
+			userWithPrefix := AddPrefix(userCode, EntityUser)
+			_, err := tx.RemoveNamedGroupingPolicy(GroupingUserRole, userWithPrefix, roleWithPrefix, tenantWithPrefix)
 			if err != nil {
-				return fmt.Errorf("failed to remove user (role=%s, user=%s): %w",
-					roleWithPrefix, userWithPrefix, err)
+				return fmt.Errorf("failed to remove user (role=%s, user=%s, domain=%s): %w",
+					roleWithPrefix, userWithPrefix, tenantWithPrefix, err)
 			}
 		}
 		return nil
 	})
 }
 
-// GetUsersForRole retrieves all users for a role, including indirect assignments.
+// GetUsersForRole retrieves all users for a role within a tenant, including indirect assignments.
 // Returns deduplicated and sorted user codes.
-func GetUsersForRole(ctx *gin.Context, roleCode string) ([]string, error) {
-	if enforcer == nil {
+func GetUsersForRole(ctx *gin.Context, tenantCode, roleCode string) ([]string, error) {
+	if enforcer.Load() == nil {
 		return nil, ErrEnforcerNotInitialized
 	}
 
+	if err := validateCode(tenantCode, EntityTenant); err != nil {
+		return nil, err
+	}
+
 	if err := validateCode(roleCode, EntityRole); err != nil {
 		return nil, err
 	}
 
-	roleWithPrefix := addPrefix(roleCode, EntityRole)
+	roleWithPrefix := AddPrefix(roleCode, EntityRole)
+	tenantWithPrefix := AddPrefix(tenantCode, EntityTenant)
 
-	usersWithPrefix, err := enforcer.GetImplicitUsersForRole(roleWithPrefix)
+	release, err := ensureTenantsLoaded(ctx, tenantCode)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get users for role %s: %w", roleWithPrefix, err)
+		logger.Errorf(ctx, "casbin: get users for role failed to load tenant policies: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
 	}
+	defer release()
 
-	// Remove duplicates and prefixes
+	usersWithPrefix, err := enforcer.Load().GetRoleManager().GetImplicitUsers(roleWithPrefix, tenantWithPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users for role %s in %s: %w", roleWithPrefix, tenantWithPrefix, err)
+	}
+
+	// Remove duplicates and prefixes, skipping child roles reached via inheritance
 	userSet := make(map[string]struct{}, len(usersWithPrefix))
 	for _, user := range usersWithPrefix {
-		userSet[removePrefix(user, EntityUser)] = struct{}{}
+		if EntityTypeOf(user) != EntityUser {
+			continue
+		}
+		userSet[RemovePrefix(user, EntityUser)] = struct{}{}
 	}
 
 	users := make([]string, 0, len(userSet))
@@ -694,10 +1353,132 @@ func GetUsersForRole(ctx *gin.Context, roleCode string) ([]string, error) {
 	return users, nil
 }
 
+// SetRoleParent replaces within tx the inheritance link of a role within its tenant with a link to parentCode,
+// so the link commits or rolls back together with the role. An empty parentCode detaches the role from its parent.
+// Rejects links that would form a cycle. Call SyncChangedRules once tx has committed.
+func SetRoleParent(ctx *gin.Context, tx *gorm.DB, tenantCode, roleCode, parentCode string) (*ChangedRules, error) {
+	if err := validateCode(tenantCode, EntityTenant); err != nil {
+		logger.Errorf(ctx, "casbin: set role parent validation failed: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+
+	if err := validateCode(roleCode, EntityRole); err != nil {
+		logger.Errorf(ctx, "casbin: set role parent validation failed: role_code=%s, error=%v", roleCode, err)
+		return nil, err
+	}
+
+	parentWithPrefix := ""
+	if parentCode != "" {
+		parentWithPrefix = AddPrefix(parentCode, EntityRole)
+	}
+	release, err := ensureTenantsLoaded(ctx, tenantCode)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: set role parent failed to load tenant policies: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+	defer release()
+
+	return writeParent(ctx, tx, GroupingUserRole, AddPrefix(roleCode, EntityRole), parentWithPrefix, AddPrefix(tenantCode, EntityTenant))
+}
+
+// SetObjectParent replaces within tx the group link of an object with a link to its parent object,
+// so grants on the parent cover the object. An empty parentCode links the object to the
+// root of its tenant, which every object must reach to be matched in that tenant.
+// Call SyncChangedRules once tx has committed.
+func SetObjectParent(ctx *gin.Context, tx *gorm.DB, tenantCode, objectCode, parentCode string) (*ChangedRules, error) {
+	if err := validateCode(tenantCode, EntityTenant); err != nil {
+		logger.Errorf(ctx, "casbin: set object parent validation failed: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+
+	if err := validateCode(objectCode, EntityObject); err != nil {
+		logger.Errorf(ctx, "casbin: set object parent validation failed: object_code=%s, error=%v", objectCode, err)
+		return nil, err
+	}
+
+	parentWithPrefix := AddPrefix(tenantCode, EntityTenant)
+	if parentCode != "" {
+		parentWithPrefix = AddPrefix(parentCode, EntityObject)
+	}
+	release, err := ensureTenantsLoaded(ctx, tenantCode)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: set object parent failed to load tenant policies: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+	defer release()
+
+	return writeParent(ctx, tx, GroupingObjectGroup, AddPrefix(objectCode, EntityObject), parentWithPrefix, "")
+}
+
+// writeParent keeps a single child-to-parent grouping rule of the given ptype in sync within tx.
+// Takes prefixed identifiers; an empty parent removes all links of the child.
+// domain is appended to the rule when non-empty.
+func writeParent(ctx *gin.Context, tx *gorm.DB, ptype, child, parent, domain string) (*ChangedRules, error) {
+	if enforcer.Load() == nil {
+		logger.Errorf(ctx, "casbin: write parent failed: enforcer not initialized")
+		return nil, ErrEnforcerNotInitialized
+	}
+
+	domains := make([]string, 0, 1)
+	if domain != "" {
+		domains = append(domains, domain)
+	}
+
+	if parent != "" {
+		if parent == child {
+			logger.Warnf(ctx, "casbin: entity cannot inherit from itself: ptype=%s, child=%s", ptype, child)
+			return nil, fmt.Errorf("entity cannot inherit from itself (ptype=%s, child=%s): %w", ptype, child, ErrHierarchyCycle)
+		}
+		hasLink, err := enforcer.Load().GetNamedRoleManager(ptype).HasLink(parent, child, domains...)
+		if err != nil {
+			logger.Errorf(ctx, "casbin: failed to check hierarchy: ptype=%s, child=%s, parent=%s, domain=%s, error=%v", ptype, child, parent, domain, err)
+			return nil, fmt.Errorf("failed to check hierarchy (ptype=%s, child=%s, parent=%s, domain=%s): %w", ptype, child, parent, domain, err)
+		}
+		if hasLink {
+			logger.Warnf(ctx, "casbin: hierarchy cycle detected: ptype=%s, child=%s, parent=%s, domain=%s", ptype, child, parent, domain)
+			return nil, fmt.Errorf("parent inherits from child (ptype=%s, child=%s, parent=%s, domain=%s): %w", ptype, child, parent, domain, ErrHierarchyCycle)
+		}
+	}
+
+	var existing []model.TblCasbinRule
+	if err := tx.WithContext(ctx).Where("ptype = ? AND v0 = ?", ptype, child).Find(&existing).Error; err != nil {
+		logger.Errorf(ctx, "casbin: failed to get existing parents: ptype=%s, child=%s, error=%v", ptype, child, err)
+		return nil, fmt.Errorf("failed to get existing parents (ptype=%s, child=%s): %w", ptype, child, err)
+	}
+
+	target := model.TblCasbinRule{Ptype: ptype, V0: child, V1: parent, V2: domain}
+	targetKey := strings.Join(ruleFields(target), ",")
+	logger.Infof(ctx, "casbin: setting parent: ptype=%s, child=%s, parent=%s, domain=%s", ptype, child, parent, domain)
+
+	changed := &ChangedRules{}
+	targetExists := false
+	stale := make([]model.TblCasbinRule, 0, len(existing))
+	for _, rule := range existing {
+		if parent != "" && strings.Join(ruleFields(rule), ",") == targetKey {
+			targetExists = true
+			continue
+		}
+		stale = append(stale, rule)
+	}
+	if err := changed.remove(ctx, tx, stale...); err != nil {
+		logger.Errorf(ctx, "casbin: failed to remove parent: ptype=%s, child=%s, error=%v", ptype, child, err)
+		return nil, fmt.Errorf("failed to remove parent (ptype=%s, child=%s): %w", ptype, child, err)
+	}
+	if parent != "" && !targetExists {
+		if err := changed.add(ctx, tx, target); err != nil {
+			logger.Errorf(ctx, "casbin: failed to add parent: ptype=%s, child=%s, parent=%s, domain=%s, error=%v", ptype, child, parent, domain, err)
+			return nil, fmt.Errorf("failed to add parent (ptype=%s, child=%s, parent=%s, domain=%s): %w", ptype, child, parent, domain, err)
+		}
+	}
+	logger.Infof(ctx, "casbin: parent set successfully: ptype=%s, child=%s, parent=%s, domain=%s", ptype, child, parent, domain)
+	return changed, nil
+}
+
 // AssignObjectsToGroup creates resource hierarchies by grouping objects.
 // Enables inheritance-based access control for object collections.
+// Groups are objects themselves, so both sides carry the object prefix.
 func AssignObjectsToGroup(ctx *gin.Context, groupCode string, objectCodes []string) error {
-	if enforcer == nil {
+	if enforcer.Load() == nil {
 		return ErrEnforcerNotInitialized
 	}
 
@@ -710,7 +1491,15 @@ func AssignObjectsToGroup(ctx *gin.Context, groupCode string, objectCodes []stri
 		return err
 	}
 
-	existingGroupings, err := enforcer.GetFilteredNamedGroupingPolicy(GroupingObjectGroup, 1, groupCode)
+	groupWithPrefix := AddPrefix(groupCode, EntityObject)
+	release, err := ensureEntityTenantsLoaded(ctx, groupWithPrefix)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: assign objects to group failed to load tenant policies: group=%s, error=%v", groupWithPrefix, err)
+		return err
+	}
+	defer release()
+
+	existingGroupings, err := enforcer.Load().GetFilteredNamedGroupingPolicy(GroupingObjectGroup, 1, groupWithPrefix)
 	if err != nil {
 		return fmt.Errorf("failed to get existing objects for group %s: %w", groupCode, err)
 	}
@@ -724,16 +1513,16 @@ func AssignObjectsToGroup(ctx *gin.Context, groupCode string, objectCodes []stri
 
 	// Check for duplicates before adding
 	for _, objectCode := range objectCodes {
-		if _, exists := existingObjects[objectCode]; exists {
+		if _, exists := existingObjects[AddPrefix(objectCode, EntityObject)]; exists {
 			return fmt.Errorf("object already in group (group=%s, object=%s): %w",
 				groupCode, objectCode, ErrGroupingAlreadyExists)
 		}
 	}
 
 	// Add all object assignments in transaction
-	return enforcer.WithTransaction(ctx, func(tx *casbin.Transaction) error {
+	return withTransaction(ctx, func(tx *casbin.Transaction) error {
 		for _, objectCode := range objectCodes {
-			_, err := tx.AddNamedGroupingPolicy(GroupingObjectGroup, objectCode, groupCode)
+			_, err := tx.AddNamedGroupingPolicy(GroupingObjectGroup, AddPrefix(objectCode, EntityObject), groupWithPrefix)
 			if err != nil {
 				return fmt.Errorf("failed to add object to group (group=%s, object=%s): %w",
 					groupCode, objectCode, err)
@@ -745,7 +1534,7 @@ func AssignObjectsToGroup(ctx *gin.Context, groupCode string, objectCodes []stri
 
 // RemoveObjectsFromGroup dissolves resource hierarchies by removing objects from groups.
 func RemoveObjectsFromGroup(ctx *gin.Context, groupCode string, objectCodes []string) error {
-	if enforcer == nil {
+	if enforcer.Load() == nil {
 		return ErrEnforcerNotInitialized
 	}
 
@@ -758,7 +1547,15 @@ func RemoveObjectsFromGroup(ctx *gin.Context, groupCode string, objectCodes []st
 		return err
 	}
 
-	existingGroupings, err := enforcer.GetFilteredNamedGroupingPolicy(GroupingObjectGroup, 1, groupCode)
+	groupWithPrefix := AddPrefix(groupCode, EntityObject)
+	release, err := ensureEntityTenantsLoaded(ctx, groupWithPrefix)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: remove objects from group failed to load tenant policies: group=%s, error=%v", groupWithPrefix, err)
+		return err
+	}
+	defer release()
+
+	existingGroupings, err := enforcer.Load().GetFilteredNamedGroupingPolicy(GroupingObjectGroup, 1, groupWithPrefix)
 	if err != nil {
 		return fmt.Errorf("failed to get existing objects for group %s: %w", groupCode, err)
 	}
@@ -772,16 +1569,16 @@ func RemoveObjectsFromGroup(ctx *gin.Context, groupCode string, objectCodes []st
 
 	// Verify all objects exist before removing
 	for _, objectCode := range objectCodes {
-		if _, exists := existingObjects[objectCode]; !exists {
+		if _, exists := existingObjects[AddPrefix(objectCode, EntityObject)]; !exists {
 			return fmt.Errorf("object not in group (group=%s, object=%s): %w",
 				groupCode, objectCode, ErrGroupingNotFound)
 		}
 	}
 
 	// Remove all object assignments in transaction
-	return enforcer.WithTransaction(ctx, func(tx *casbin.Transaction) error {
+	return withTransaction(ctx, func(tx *casbin.Transaction) error {
 		for _, objectCode := range objectCodes {
-			_, err := tx.RemoveNamedGroupingPolicy(GroupingObjectGroup, objectCode, groupCode)
+			_, err := tx.RemoveNamedGroupingPolicy(GroupingObjectGroup, AddPrefix(objectCode, EntityObject), groupWithPrefix)
 			if err != nil {
 				return fmt.Errorf("failed to remove object from group (group=%s, object=%s): %w",
 					groupCode, objectCode, err)
@@ -794,7 +1591,7 @@ func RemoveObjectsFromGroup(ctx *gin.Context, groupCode string, objectCodes []st
 // GetGroupsForObject retrieves all groups containing an object.
 // Useful for debugging resource hierarchy access issues.
 func GetGroupsForObject(ctx *gin.Context, objectCode string) ([]string, error) {
-	if enforcer == nil {
+	if enforcer.Load() == nil {
 		return nil, ErrEnforcerNotInitialized
 	}
 
@@ -802,7 +1599,14 @@ func GetGroupsForObject(ctx *gin.Context, objectCode string) ([]string, error) {
 		return nil, err
 	}
 
-	groupings, err := enforcer.GetFilteredNamedGroupingPolicy(GroupingObjectGroup, 0, objectCode)
+	release, err := ensureEntityTenantsLoaded(ctx, AddPrefix(objectCode, EntityObject))
+	if err != nil {
+		logger.Errorf(ctx, "casbin: get groups for object failed to load tenant policies: object_code=%s, error=%v", objectCode, err)
+		return nil, err
+	}
+	defer release()
+
+	groupings, err := enforcer.Load().GetFilteredNamedGroupingPolicy(GroupingObjectGroup, 0, AddPrefix(objectCode, EntityObject))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get groups for object %s: %w", objectCode, err)
 	}
@@ -810,7 +1614,7 @@ func GetGroupsForObject(ctx *gin.Context, objectCode string) ([]string, error) {
 	groupSet := make(map[string]struct{}, len(groupings))
 	for _, grouping := range groupings {
 		if len(grouping) >= 2 {
-			groupSet[grouping[1]] = struct{}{}
+			groupSet[RemovePrefix(grouping[1], EntityObject)] = struct{}{}
 		}
 	}
 
@@ -826,7 +1630,7 @@ func GetGroupsForObject(ctx *gin.Context, objectCode string) ([]string, error) {
 // GetObjectsForGroup retrieves all objects within a specific group.
 // Returns sorted list for consistent ordering.
 func GetObjectsForGroup(ctx *gin.Context, groupCode string) ([]string, error) {
-	if enforcer == nil {
+	if enforcer.Load() == nil {
 		return nil, ErrEnforcerNotInitialized
 	}
 
@@ -834,7 +1638,14 @@ func GetObjectsForGroup(ctx *gin.Context, groupCode string) ([]string, error) {
 		return nil, ErrInvalidGroupCode
 	}
 
-	groupings, err := enforcer.GetFilteredNamedGroupingPolicy(GroupingObjectGroup, 1, groupCode)
+	release, err := ensureEntityTenantsLoaded(ctx, AddPrefix(groupCode, EntityObject))
+	if err != nil {
+		logger.Errorf(ctx, "casbin: get objects for group failed to load tenant policies: group_code=%s, error=%v", groupCode, err)
+		return nil, err
+	}
+	defer release()
+
+	groupings, err := enforcer.Load().GetFilteredNamedGroupingPolicy(GroupingObjectGroup, 1, AddPrefix(groupCode, EntityObject))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get objects for group %s: %w", groupCode, err)
 	}
@@ -842,7 +1653,7 @@ func GetObjectsForGroup(ctx *gin.Context, groupCode string) ([]string, error) {
 	objectSet := make(map[string]struct{}, len(groupings))
 	for _, grouping := range groupings {
 		if len(grouping) >= 2 {
-			objectSet[grouping[0]] = struct{}{}
+			objectSet[RemovePrefix(grouping[0], EntityObject)] = struct{}{}
 		}
 	}
 
@@ -868,7 +1679,7 @@ func RemovePoliciesFromRole(ctx *gin.Context, roleCode string, policies []Policy
 // GetPoliciesForRole retrieves active policies for a role.
 // Automatically filters expired policies and returns sorted results.
 func GetPoliciesForRole(ctx *gin.Context, roleCode string) ([]Policy, error) {
-	if enforcer == nil {
+	if enforcer.Load() == nil {
 		return nil, ErrEnforcerNotInitialized
 	}
 
@@ -876,8 +1687,15 @@ func GetPoliciesForRole(ctx *gin.Context, roleCode string) ([]Policy, error) {
 		return nil, err
 	}
 
-	roleWithPrefix := addPrefix(roleCode, EntityRole)
-	policyFields, err := enforcer.GetFilteredPolicy(0, roleWithPrefix)
+	roleWithPrefix := AddPrefix(roleCode, EntityRole)
+	release, err := ensureEntityTenantsLoaded(ctx, roleWithPrefix)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: get policies for role failed to load tenant policies: role=%s, error=%v", roleWithPrefix, err)
+		return nil, err
+	}
+	defer release()
+
+	policyFields, err := enforcer.Load().GetFilteredPolicy(0, roleWithPrefix)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get policies for role %s: %w", roleWithPrefix, err)
 	}
@@ -888,22 +1706,11 @@ func GetPoliciesForRole(ctx *gin.Context, roleCode string) ([]Policy, error) {
 			continue
 		}
 
-		beginTime, err := time.Parse(time.RFC3339, fields[3])
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse begin time %s: %w", fields[3], err)
-		}
-
-		endTime, err := time.Parse(time.RFC3339, fields[4])
+		policy, err := policyFromFields(fields)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse end time %s: %w", fields[4], err)
+			return nil, err
 		}
-
-		policies = append(policies, Policy{
-			Object:    fields[1],
-			Action:    fields[2],
-			BeginTime: beginTime,
-			EndTime:   endTime,
-		})
+		policies = append(policies, policy)
 	}
 
 	// Filter out expired policies
@@ -938,26 +1745,50 @@ func RemovePoliciesFromUser(ctx *gin.Context, userCode string, policies []Policy
 	return removePoliciesFromSubject(ctx, userCode, EntityUser, policies)
 }
 
-// GetPoliciesForUser retrieves all effective policies for a user.
+// GetPoliciesForUser retrieves all effective policies for a user within a tenant.
 // Combines direct and role-inherited permissions, filtering expired policies.
-func GetPoliciesForUser(ctx *gin.Context, userCode string) ([]Policy, error) {
-	if enforcer == nil {
+func GetPoliciesForUser(ctx *gin.Context, tenantCode, userCode string) ([]Policy, error) {
+	if enforcer.Load() == nil {
 		logger.Errorf(ctx, "casbin: get policies for user failed: enforcer not initialized")
 		return nil, ErrEnforcerNotInitialized
 	}
 
+	if err := validateCode(tenantCode, EntityTenant); err != nil {
+		logger.Errorf(ctx, "casbin: get policies for user validation failed: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+
 	if err := validateCode(userCode, EntityUser); err != nil {
 		logger.Errorf(ctx, "casbin: get policies for user validation failed: user_code=%s, error=%v", userCode, err)
 		return nil, err
 	}
 
-	userWithPrefix := addPrefix(userCode, EntityUser)
-	logger.Debugf(ctx, "casbin: getting policies for user: user=%s", userWithPrefix)
+	userWithPrefix := AddPrefix(userCode, EntityUser)
+	tenantWithPrefix := AddPrefix(tenantCode, EntityTenant)
+	logger.Debugf(ctx, "casbin: getting policies for user: user=%s, domain=%s", userWithPrefix, tenantWithPrefix)
+
+	// p rules carry no domain, so collect them per subject the user reaches in the tenant
+	release, err := ensureEntityTenantsLoaded(ctx, tenantWithPrefix, userWithPrefix)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: get policies for user failed to load tenant policies: user=%s, domain=%s, error=%v", userWithPrefix, tenantWithPrefix, err)
+		return nil, err
+	}
+	defer release()
 
-	policyFields, err := enforcer.GetImplicitPermissionsForUser(userWithPrefix)
+	rolesWithPrefix, err := enforcer.Load().GetImplicitRolesForUser(userWithPrefix, tenantWithPrefix)
 	if err != nil {
-		logger.Errorf(ctx, "casbin: failed to get policies for user: user=%s, error=%v", userWithPrefix, err)
-		return nil, fmt.Errorf("failed to get policies for user %s: %w", userWithPrefix, err)
+		logger.Errorf(ctx, "casbin: failed to get roles for user: user=%s, domain=%s, error=%v", userWithPrefix, tenantWithPrefix, err)
+		return nil, fmt.Errorf("failed to get roles for user %s in %s: %w", userWithPrefix, tenantWithPrefix, err)
+	}
+
+	policyFields := make([][]string, 0)
+	for _, subject := range append([]string{userWithPrefix}, rolesWithPrefix...) {
+		subjectPolicies, err := enforcer.Load().GetFilteredPolicy(0, subject)
+		if err != nil {
+			logger.Errorf(ctx, "casbin: failed to get policies for subject: subject=%s, error=%v", subject, err)
+			return nil, fmt.Errorf("failed to get policies for %s: %w", subject, err)
+		}
+		policyFields = append(policyFields, subjectPolicies...)
 	}
 
 	policies := make([]Policy, 0, len(policyFields))
@@ -966,24 +1797,12 @@ func GetPoliciesForUser(ctx *gin.Context, userCode string) ([]Policy, error) {
 			continue
 		}
 
-		beginTime, err := time.Parse(time.RFC3339, fields[3])
+		policy, err := policyFromFields(fields)
 		if err != nil {
-			logger.Errorf(ctx, "casbin: failed to parse begin time: time=%s, error=%v", fields[3], err)
-			return nil, fmt.Errorf("failed to parse begin time %s: %w", fields[3], err)
+			logger.Errorf(ctx, "casbin: failed to parse policy: fields=%v, error=%v", fields, err)
+			return nil, err
 		}
-
-		endTime, err := time.Parse(time.RFC3339, fields[4])
-		if err != nil {
-			logger.Errorf(ctx, "casbin: failed to parse end time: time=%s, error=%v", fields[4], err)
-			return nil, fmt.Errorf("failed to parse end time %s: %w", fields[4], err)
-		}
-
-		policies = append(policies, Policy{
-			Object:    fields[1],
-			Action:    fields[2],
-			BeginTime: beginTime,
-			EndTime:   endTime,
-		})
+		policies = append(policies, policy)
 	}
 
 	// Filter out expired policies
@@ -1003,7 +1822,7 @@ func GetPoliciesForUser(ctx *gin.Context, userCode string) ([]Policy, error) {
 		return policies[i].EndTime.Before(policies[j].EndTime)
 	})
 
-	logger.Debugf(ctx, "casbin: retrieved policies for user: user=%s, policy_count=%d", userWithPrefix, len(policies))
+	logger.Debugf(ctx, "casbin: retrieved policies for user: user=%s, domain=%s, policy_count=%d", userWithPrefix, tenantWithPrefix, len(policies))
 	return policies, nil
 }
 
@@ -1020,12 +1839,15 @@ func filterExpiredPolicies(policies []Policy, currentTime time.Time) []Policy {
 }
 
 // Policy defines time-bound access control rules.
-// Supports temporal permissions with automatic expiration.
+// Supports temporal permissions with automatic expiration, optional weekly recurrence and attribute conditions.
 type Policy struct {
-	Object    string    // Resource identifier
-	Action    string    // Permission type (read, write, etc.)
-	BeginTime time.Time // Policy start time (UTC)
-	EndTime   time.Time // Policy end time (UTC)
+	Object     string    // Object code without Casbin prefix
+	Action     string    // Permission type (read, write, etc.)
+	BeginTime  time.Time // Policy start time (UTC)
+	EndTime    time.Time // Policy end time (UTC)
+	Recurrence string    // Weekly window inside BeginTime/EndTime, see ParseRecurrence; empty means always
+	Condition  string    // Expression over request attributes, see ValidateCondition; empty means always
+	Effect     string    // EffectAllow or EffectDeny, empty means allow
 }
 
 // Validate checks policy integrity and time constraints.
@@ -1037,5 +1859,50 @@ func (p Policy) Validate() error {
 	if !p.EndTime.After(p.BeginTime) {
 		return ErrInvalidTimeRange
 	}
+	if p.Effect != "" && p.Effect != EffectAllow && p.Effect != EffectDeny {
+		return ErrInvalidEffect
+	}
+	if p.Recurrence != "" {
+		if _, err := ParseRecurrence(p.Recurrence); err != nil {
+			return err
+		}
+	}
+	if err := ValidateCondition(p.Condition); err != nil {
+		return err
+	}
 	return nil
 }
+
+// EffectOrDefault returns the policy effect, treating an empty effect as allow.
+func (p Policy) EffectOrDefault() string {
+	if p.Effect == "" {
+		return EffectAllow
+	}
+	return p.Effect
+}
+
+// effectOf reads the effect from raw policy fields, defaulting to allow.
+func effectOf(fields []string) string {
+	if len(fields) < 6 || fields[5] == "" {
+		return EffectAllow
+	}
+	return fields[5]
+}
+
+// policyFromFields converts raw policy fields into a Policy, dropping the subject.
+func policyFromFields(fields []string) (Policy, error) {
+	beginTime, endTime, recurrence, err := ParseWindow(fields[3])
+	if err != nil {
+		return Policy{}, err
+	}
+
+	return Policy{
+		Object:     RemovePrefix(fields[1], EntityObject),
+		Action:     fields[2],
+		BeginTime:  beginTime,
+		EndTime:    endTime,
+		Recurrence: recurrence,
+		Condition:  fields[4],
+		Effect:     effectOf(fields),
+	}, nil
+}
@@ -0,0 +1,151 @@
+package casbin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"ac/bootstrap/bootstraptest"
+	"ac/bootstrap/database"
+	"ac/model"
+	"ac/util"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(bootstraptest.Main(m, Initialize))
+}
+
+// newTestContext returns a request context for calls made outside a handler.
+func newTestContext() *gin.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	return ctx
+}
+
+// newTenant stores an enabled tenant and returns its code.
+func newTenant(t *testing.T) string {
+	t.Helper()
+
+	now := time.Now()
+	tenant := &model.TblTenant{Code: util.GenerateCode(), Name: "tenant", Status: model.StatusEnabled.Int64(), CreatedAt: now, UpdatedAt: now}
+	if err := database.DB.Create(tenant).Error; err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	return tenant.Code
+}
+
+// newSubject stores an enabled user or role of a tenant and returns its code.
+func newSubject(t *testing.T, tenantCode string, subjectType model.SubjectType) string {
+	t.Helper()
+
+	now := time.Now()
+	subject := &model.TblSubject{
+		Type:       subjectType,
+		Code:       util.GenerateCode(),
+		TenantCode: tenantCode,
+		Name:       "subject",
+		Status:     model.StatusEnabled.Int64(),
+		Deleted:    model.NotDeleted,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := database.DB.Create(subject).Error; err != nil {
+		t.Fatalf("create subject: %v", err)
+	}
+	return subject.Code
+}
+
+// newObject stores an enabled object under parentCode, or under the tenant root when empty,
+// links it the way object create does and returns its code.
+func newObject(t *testing.T, ctx *gin.Context, tenantCode, parentCode string) string {
+	t.Helper()
+
+	now := time.Now()
+	object := &model.TblObject{
+		Type:       model.ObjectTypeMenu,
+		Code:       util.GenerateCode(),
+		TenantCode: tenantCode,
+		Name:       "object",
+		ParentCode: parentCode,
+		Status:     model.StatusEnabled.Int64(),
+		Deleted:    model.NotDeleted,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := database.DB.Create(object).Error; err != nil {
+		t.Fatalf("create object: %v", err)
+	}
+	changed, err := SetObjectParent(ctx, database.DB, tenantCode, object.Code, parentCode)
+	if err != nil {
+		t.Fatalf("link object: %v", err)
+	}
+	if err := SyncChangedRules(ctx, changed); err != nil {
+		t.Fatalf("sync object link: %v", err)
+	}
+	return object.Code
+}
+
+// activePolicy returns an allow policy on objectCode for action that is in effect for an hour around now.
+func activePolicy(objectCode, action string) Policy {
+	now := time.Now().UTC().Truncate(time.Second)
+	return Policy{Object: objectCode, Action: action, BeginTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour)}
+}
+
+func TestUpdatePolicy(t *testing.T) {
+	ctx := newTestContext()
+	tenantCode := newTenant(t)
+	userCode := newSubject(t, tenantCode, model.SubjectTypeUser)
+	objectCode := newObject(t, ctx, tenantCode, "")
+	if err := AssignPoliciesToUser(ctx, userCode, []Policy{activePolicy(objectCode, "read"), activePolicy(objectCode, "write")}); err != nil {
+		t.Fatalf("assign policies: %v", err)
+	}
+	allowed := func(action string) bool {
+		t.Helper()
+		allowed, _, err := CheckPermission(newTestContext(), tenantCode, userCode, objectCode, action, nil, time.Now())
+		if err != nil {
+			t.Fatalf("check %s: %v", action, err)
+		}
+		return allowed
+	}
+
+	if err := UpdatePolicy(ctx, userCode, EntityUser, activePolicy(objectCode, "read"), activePolicy(objectCode, "list")); err != nil {
+		t.Fatalf("update policy: %v", err)
+	}
+	if allowed("read") || !allowed("list") {
+		t.Fatalf("read allowed = %v, list allowed = %v after update, want false and true", allowed("read"), allowed("list"))
+	}
+
+	// Updating into a policy the user already holds keeps the old one
+	err := UpdatePolicy(ctx, userCode, EntityUser, activePolicy(objectCode, "list"), activePolicy(objectCode, "write"))
+	if !errors.Is(err, ErrPolicyAlreadyExists) {
+		t.Fatalf("update into an existing policy: err = %v, want %v", err, ErrPolicyAlreadyExists)
+	}
+	if !allowed("list") {
+		t.Fatalf("list denied after a rejected update")
+	}
+
+	// A failing commit hook rolls back both the removal and the addition
+	OnCommit(ctx, func(tx *gorm.DB) error { return errors.New("hook failed") })
+	if err := UpdatePolicy(ctx, userCode, EntityUser, activePolicy(objectCode, "list"), activePolicy(objectCode, "delete")); err == nil {
+		t.Fatalf("update with a failing hook succeeded")
+	}
+	if !allowed("list") || allowed("delete") {
+		t.Fatalf("list allowed = %v, delete allowed = %v after a rolled back update, want true and false", allowed("list"), allowed("delete"))
+	}
+	var stored int64
+	if err := database.DB.Model(&model.TblCasbinRule{}).
+		Where("ptype = ? AND v0 = ? AND v2 = ?", "p", AddPrefix(userCode, EntityUser), "list").
+		Count(&stored).Error; err != nil {
+		t.Fatalf("count stored rules: %v", err)
+	}
+	if stored != 1 {
+		t.Fatalf("stored list rules after a rolled back update = %d, want 1", stored)
+	}
+}
@@ -0,0 +1,98 @@
+package casbin
+
+import (
+	"fmt"
+	"strings"
+
+	"ac/bootstrap/logger"
+	"ac/model"
+
+	casbin "github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ChangedRules holds the rules a change wrote within a caller's database transaction,
+// so the rules commit or roll back together with the caller's other writes.
+// Call SyncChangedRules once the transaction has committed to apply them to the enforcer.
+type ChangedRules struct {
+	added   []model.TblCasbinRule
+	removed []model.TblCasbinRule
+}
+
+// add inserts rules within tx and records them as added.
+func (c *ChangedRules) add(ctx *gin.Context, tx *gorm.DB, rules ...model.TblCasbinRule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	if err := tx.WithContext(ctx).Create(&rules).Error; err != nil {
+		return fmt.Errorf("failed to insert rules: %w", err)
+	}
+	c.added = append(c.added, rules...)
+	return nil
+}
+
+// remove deletes stored rules within tx and records them as removed.
+func (c *ChangedRules) remove(ctx *gin.Context, tx *gorm.DB, rules ...model.TblCasbinRule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(rules))
+	for _, rule := range rules {
+		ids = append(ids, rule.Id)
+	}
+	if err := tx.WithContext(ctx).Where("id IN ?", ids).Delete(&model.TblCasbinRule{}).Error; err != nil {
+		return fmt.Errorf("failed to delete rules: %w", err)
+	}
+	c.removed = append(c.removed, rules...)
+	return nil
+}
+
+// SyncChangedRules applies rules written within a committed transaction to the in-memory enforcer
+// without writing to the database again. Falls back to a full reload if that fails.
+func SyncChangedRules(ctx *gin.Context, changes ...*ChangedRules) error {
+	if enforcer.Load() == nil {
+		logger.Errorf(ctx, "casbin: sync changed rules failed: enforcer not initialized")
+		return ErrEnforcerNotInitialized
+	}
+	defer notifyPeers(ctx)
+
+	return syncEnforcer(ctx, func(e *casbin.TransactionalEnforcer) error {
+		for _, changed := range changes {
+			if changed == nil {
+				continue
+			}
+			for _, rule := range changed.removed {
+				if _, err := e.SelfRemovePolicy(sectionOf(rule.Ptype), rule.Ptype, ruleFields(rule)); err != nil {
+					logger.Warnf(ctx, "casbin: failed to sync removed rule, reloading: ptype=%s, rule=%v, error=%v", rule.Ptype, ruleFields(rule), err)
+					return err
+				}
+			}
+			for _, rule := range changed.added {
+				if _, err := e.SelfAddPolicy(sectionOf(rule.Ptype), rule.Ptype, ruleFields(rule)); err != nil {
+					logger.Warnf(ctx, "casbin: failed to sync added rule, reloading: ptype=%s, rule=%v, error=%v", rule.Ptype, ruleFields(rule), err)
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// ruleFields converts a stored rule into Casbin policy fields, dropping trailing empty fields
+// the way the adapter does when loading policies.
+func ruleFields(rule model.TblCasbinRule) []string {
+	fields := []string{rule.V0, rule.V1, rule.V2, rule.V3, rule.V4, rule.V5}
+	for len(fields) > 0 && fields[len(fields)-1] == "" {
+		fields = fields[:len(fields)-1]
+	}
+	return fields
+}
+
+// sectionOf returns the model section of a ptype: "p" for policies, "g" for groupings.
+func sectionOf(ptype string) string {
+	if strings.HasPrefix(ptype, "p") {
+		return "p"
+	}
+	return "g"
+}
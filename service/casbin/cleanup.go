@@ -0,0 +1,361 @@
+package casbin
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ac/bootstrap/logger"
+	"ac/model"
+
+	casbin "github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RemovedRules counts the rules removed along with a deleted user, role or object.
+type RemovedRules struct {
+	RoleLinks   int64 // g rules linking the entity to roles or members
+	ObjectLinks int64 // g2 rules linking the entity to parents, groups or members
+	Policies    int64 // p rules granted to or on the entity
+
+	entity string // Prefixed identifier the removed rules referenced
+}
+
+// RestoredRules counts the rules re-created for a restored user, role or object.
+type RestoredRules struct {
+	RoleLinks   int64 // g rules linking the entity to roles or members
+	ObjectLinks int64 // g2 rules linking the entity to parents, groups or members
+	Policies    int64 // p rules granted to or on the entity
+	Skipped     int64 // Archived rules left out because they reference a deleted entity or already exist
+
+	tenantCode string                // Tenant of the restored entity, loaded by SyncRestoredRules
+	rules      []model.TblCasbinRule // Re-created rules, applied to the enforcer by SyncRestoredRules
+}
+
+// ruleReference is one rule field that may reference a deleted entity.
+type ruleReference struct {
+	ptype      string
+	fieldIndex int
+}
+
+// entityReferences lists where rules reference each entity type.
+var entityReferences = map[string][]ruleReference{
+	EntityUser:   {{GroupingUserRole, 0}, {"p", 0}},
+	EntityRole:   {{GroupingUserRole, 0}, {GroupingUserRole, 1}, {"p", 0}},
+	EntityObject: {{GroupingObjectGroup, 0}, {GroupingObjectGroup, 1}, {"p", 1}},
+}
+
+// RemoveEntityRules deletes every rule referencing a user, role or object within tx,
+// so the removal commits or rolls back together with the soft delete of the entity.
+// The rules are archived first so RestoreEntityRules can re-create them.
+// Call SyncRemovedRules once tx has committed to drop the rules from the enforcer.
+func RemoveEntityRules(ctx *gin.Context, tx *gorm.DB, code, entityType string) (*RemovedRules, error) {
+	if err := validateCode(code, entityType); err != nil {
+		logger.Errorf(ctx, "casbin: remove entity rules validation failed: entity_type=%s, code=%s, error=%v", entityType, code, err)
+		return nil, err
+	}
+
+	references, ok := entityReferences[entityType]
+	if !ok {
+		logger.Errorf(ctx, "casbin: remove entity rules failed: invalid entity type=%s", entityType)
+		return nil, fmt.Errorf("rules cannot be removed for entity type %s", entityType)
+	}
+
+	removed := &RemovedRules{entity: AddPrefix(code, entityType)}
+	now := time.Now()
+	for _, reference := range references {
+		column := fmt.Sprintf("v%d", reference.fieldIndex)
+
+		var rules []model.TblCasbinRule
+		if err := tx.WithContext(ctx).
+			Where("ptype = ? AND "+column+" = ?", reference.ptype, removed.entity).
+			Find(&rules).Error; err != nil {
+			logger.Errorf(ctx, "casbin: failed to query entity rules: entity=%s, ptype=%s, field=%s, error=%v", removed.entity, reference.ptype, column, err)
+			return nil, fmt.Errorf("failed to query rules (entity=%s, ptype=%s, field=%s): %w", removed.entity, reference.ptype, column, err)
+		}
+		if len(rules) == 0 {
+			continue
+		}
+
+		ids := make([]int64, 0, len(rules))
+		archives := make([]model.TblCasbinRuleArchive, 0, len(rules))
+		for _, rule := range rules {
+			ids = append(ids, rule.Id)
+			archives = append(archives, model.TblCasbinRuleArchive{
+				Entity:    removed.entity,
+				Ptype:     rule.Ptype,
+				V0:        rule.V0,
+				V1:        rule.V1,
+				V2:        rule.V2,
+				V3:        rule.V3,
+				V4:        rule.V4,
+				V5:        rule.V5,
+				CreatedAt: now,
+			})
+		}
+		if err := tx.WithContext(ctx).Create(&archives).Error; err != nil {
+			logger.Errorf(ctx, "casbin: failed to archive entity rules: entity=%s, ptype=%s, error=%v", removed.entity, reference.ptype, err)
+			return nil, fmt.Errorf("failed to archive rules (entity=%s, ptype=%s): %w", removed.entity, reference.ptype, err)
+		}
+		if err := tx.WithContext(ctx).Where("id IN ?", ids).Delete(&model.TblCasbinRule{}).Error; err != nil {
+			logger.Errorf(ctx, "casbin: failed to remove entity rules: entity=%s, ptype=%s, field=%s, error=%v", removed.entity, reference.ptype, column, err)
+			return nil, fmt.Errorf("failed to remove rules (entity=%s, ptype=%s, field=%s): %w", removed.entity, reference.ptype, column, err)
+		}
+
+		switch reference.ptype {
+		case GroupingUserRole:
+			removed.RoleLinks += int64(len(rules))
+		case GroupingObjectGroup:
+			removed.ObjectLinks += int64(len(rules))
+		default:
+			removed.Policies += int64(len(rules))
+		}
+	}
+
+	logger.Infof(ctx, "casbin: entity rules removed: entity=%s, role_links=%d, object_links=%d, policies=%d",
+		removed.entity, removed.RoleLinks, removed.ObjectLinks, removed.Policies)
+	return removed, nil
+}
+
+// SyncRemovedRules drops rules deleted by RemoveEntityRules from the in-memory enforcer
+// without writing to the database again. Falls back to a full reload if that fails.
+func SyncRemovedRules(ctx *gin.Context, removed *RemovedRules) error {
+	if enforcer.Load() == nil {
+		logger.Errorf(ctx, "casbin: sync removed rules failed: enforcer not initialized")
+		return ErrEnforcerNotInitialized
+	}
+	defer notifyPeers(ctx)
+
+	return syncEnforcer(ctx, func(e *casbin.TransactionalEnforcer) error {
+		for _, reference := range entityReferences[EntityTypeOf(removed.entity)] {
+			if _, err := e.SelfRemoveFilteredPolicy(sectionOf(reference.ptype), reference.ptype, reference.fieldIndex, removed.entity); err != nil {
+				logger.Warnf(ctx, "casbin: failed to sync removed rules, reloading: entity=%s, ptype=%s, error=%v", removed.entity, reference.ptype, err)
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RestoreEntityRules re-creates within tx the structural link of a restored user, role or object
+// and, when withRules is set, the rules archived when it was deleted. The structural link joins a
+// role to its parent role, or an object to its parent object or tenant root; parentCode may be empty.
+// Archived rules referencing a user, role or object that is deleted by now, or already stored
+// again, are skipped. The archive is cleared either way. Call SyncRestoredRules once tx has committed.
+func RestoreEntityRules(ctx *gin.Context, tx *gorm.DB, tenantCode, code, entityType, parentCode string, withRules bool) (*RestoredRules, error) {
+	if enforcer.Load() == nil {
+		logger.Errorf(ctx, "casbin: restore entity rules failed: enforcer not initialized")
+		return nil, ErrEnforcerNotInitialized
+	}
+
+	if err := validateCode(tenantCode, EntityTenant); err != nil {
+		logger.Errorf(ctx, "casbin: restore entity rules validation failed: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+
+	if err := validateCode(code, entityType); err != nil {
+		logger.Errorf(ctx, "casbin: restore entity rules validation failed: entity_type=%s, code=%s, error=%v", entityType, code, err)
+		return nil, err
+	}
+
+	entity := AddPrefix(code, entityType)
+	candidates := make([]model.TblCasbinRule, 0)
+	switch entityType {
+	case EntityRole:
+		if parentCode != "" {
+			candidates = append(candidates, model.TblCasbinRule{
+				Ptype: GroupingUserRole,
+				V0:    entity,
+				V1:    AddPrefix(parentCode, EntityRole),
+				V2:    AddPrefix(tenantCode, EntityTenant),
+			})
+		}
+	case EntityObject:
+		parent := AddPrefix(tenantCode, EntityTenant)
+		if parentCode != "" {
+			parent = AddPrefix(parentCode, EntityObject)
+		}
+		candidates = append(candidates, model.TblCasbinRule{Ptype: GroupingObjectGroup, V0: entity, V1: parent})
+	}
+
+	var archives []model.TblCasbinRuleArchive
+	if err := tx.WithContext(ctx).Where("entity = ?", entity).Order("id").Find(&archives).Error; err != nil {
+		logger.Errorf(ctx, "casbin: failed to query archived rules: entity=%s, error=%v", entity, err)
+		return nil, fmt.Errorf("failed to query archived rules (entity=%s): %w", entity, err)
+	}
+	if withRules {
+		for _, archive := range archives {
+			candidates = append(candidates, model.TblCasbinRule{
+				Ptype: archive.Ptype,
+				V0:    archive.V0,
+				V1:    archive.V1,
+				V2:    archive.V2,
+				V3:    archive.V3,
+				V4:    archive.V4,
+				V5:    archive.V5,
+			})
+		}
+	}
+
+	live, err := liveEntities(ctx, tx, candidates, entity)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := &RestoredRules{tenantCode: tenantCode}
+	seen := make(map[string]struct{}, len(candidates))
+	for _, rule := range candidates {
+		fields := ruleFields(rule)
+		key := rule.Ptype + "," + strings.Join(fields, ",")
+		if _, duplicate := seen[key]; duplicate {
+			// The archived structural link repeats the one built above
+			continue
+		}
+		seen[key] = struct{}{}
+		if !referencesLive(rule, live) {
+			restored.Skipped++
+			continue
+		}
+		// Check the database rather than the enforcer, which may not hold the tenant's rules
+		stored, err := storedRule(ctx, tx, rule)
+		if err != nil {
+			logger.Errorf(ctx, "casbin: failed to query stored rule: entity=%s, ptype=%s, rule=%v, error=%v", entity, rule.Ptype, fields, err)
+			return nil, err
+		}
+		if stored {
+			restored.Skipped++
+			continue
+		}
+
+		restored.rules = append(restored.rules, rule)
+		switch rule.Ptype {
+		case GroupingUserRole:
+			restored.RoleLinks++
+		case GroupingObjectGroup:
+			restored.ObjectLinks++
+		default:
+			restored.Policies++
+		}
+	}
+	if len(restored.rules) > 0 {
+		if err := tx.WithContext(ctx).Create(&restored.rules).Error; err != nil {
+			logger.Errorf(ctx, "casbin: failed to restore entity rules: entity=%s, error=%v", entity, err)
+			return nil, fmt.Errorf("failed to restore rules (entity=%s): %w", entity, err)
+		}
+	}
+	if len(archives) > 0 {
+		if err := tx.WithContext(ctx).Where("entity = ?", entity).Delete(&model.TblCasbinRuleArchive{}).Error; err != nil {
+			logger.Errorf(ctx, "casbin: failed to clear archived rules: entity=%s, error=%v", entity, err)
+			return nil, fmt.Errorf("failed to clear archived rules (entity=%s): %w", entity, err)
+		}
+	}
+
+	logger.Infof(ctx, "casbin: entity rules restored: entity=%s, role_links=%d, object_links=%d, policies=%d, skipped=%d",
+		entity, restored.RoleLinks, restored.ObjectLinks, restored.Policies, restored.Skipped)
+	return restored, nil
+}
+
+// SyncRestoredRules adds rules re-created by RestoreEntityRules to the in-memory enforcer
+// without writing to the database again. Falls back to a full reload if that fails.
+func SyncRestoredRules(ctx *gin.Context, restored *RestoredRules) error {
+	if enforcer.Load() == nil {
+		logger.Errorf(ctx, "casbin: sync restored rules failed: enforcer not initialized")
+		return ErrEnforcerNotInitialized
+	}
+	defer notifyPeers(ctx)
+
+	// A tenant not in memory yet loads from the committed rules, restored ones included
+	release, err := ensureTenantsLoaded(ctx, restored.tenantCode)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: sync restored rules failed to load tenant policies: tenant_code=%s, error=%v", restored.tenantCode, err)
+		return err
+	}
+	defer release()
+
+	rulesByPtype := make(map[string][][]string)
+	for _, rule := range restored.rules {
+		rulesByPtype[rule.Ptype] = append(rulesByPtype[rule.Ptype], ruleFields(rule))
+	}
+	return syncEnforcer(ctx, func(e *casbin.TransactionalEnforcer) error {
+		for ptype, rules := range rulesByPtype {
+			if _, err := e.SelfAddPolicies(sectionOf(ptype), ptype, rules); err != nil {
+				logger.Warnf(ctx, "casbin: failed to sync restored rules, reloading: ptype=%s, error=%v", ptype, err)
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// liveEntities returns the users, roles and objects referenced by rules that exist and are not deleted.
+// self is the entity being restored and counts as live.
+func liveEntities(ctx *gin.Context, tx *gorm.DB, rules []model.TblCasbinRule, self string) (map[string]struct{}, error) {
+	subjectCodes := make([]string, 0)
+	objectCodes := make([]string, 0)
+	for _, rule := range rules {
+		for _, field := range []string{rule.V0, rule.V1} {
+			switch EntityTypeOf(field) {
+			case EntityUser, EntityRole:
+				subjectCodes = append(subjectCodes, RemovePrefix(field, EntityTypeOf(field)))
+			case EntityObject:
+				objectCodes = append(objectCodes, RemovePrefix(field, EntityObject))
+			}
+		}
+	}
+
+	live := map[string]struct{}{self: {}}
+	if len(subjectCodes) > 0 {
+		var subjects []model.TblSubject
+		if err := tx.WithContext(ctx).Select("code", "type").
+			Where("code IN ? AND deleted = ?", subjectCodes, model.NotDeleted).
+			Find(&subjects).Error; err != nil {
+			return nil, fmt.Errorf("failed to query live subjects: %w", err)
+		}
+		for _, subject := range subjects {
+			entityType := EntityUser
+			if subject.Type == model.SubjectTypeRole {
+				entityType = EntityRole
+			}
+			live[AddPrefix(subject.Code, entityType)] = struct{}{}
+		}
+	}
+	if len(objectCodes) > 0 {
+		var objects []model.TblObject
+		if err := tx.WithContext(ctx).Select("code").
+			Where("code IN ? AND deleted = ?", objectCodes, model.NotDeleted).
+			Find(&objects).Error; err != nil {
+			return nil, fmt.Errorf("failed to query live objects: %w", err)
+		}
+		for _, object := range objects {
+			live[AddPrefix(object.Code, EntityObject)] = struct{}{}
+		}
+	}
+	return live, nil
+}
+
+// referencesLive reports whether every user, role or object a rule references is live.
+// Tenants are never deleted and always count as live.
+func referencesLive(rule model.TblCasbinRule, live map[string]struct{}) bool {
+	for _, field := range []string{rule.V0, rule.V1} {
+		switch EntityTypeOf(field) {
+		case EntityUser, EntityRole, EntityObject:
+			if _, ok := live[field]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// storedRule reports whether tx already holds a rule.
+func storedRule(ctx *gin.Context, tx *gorm.DB, rule model.TblCasbinRule) (bool, error) {
+	var count int64
+	if err := tx.WithContext(ctx).Model(&model.TblCasbinRule{}).
+		Where("ptype = ? AND v0 = ? AND v1 = ? AND v2 = ? AND v3 = ? AND v4 = ? AND v5 = ?",
+			rule.Ptype, rule.V0, rule.V1, rule.V2, rule.V3, rule.V4, rule.V5).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to query stored rule: %w", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,78 @@
+package casbin
+
+import (
+	"context"
+	"fmt"
+
+	"ac/bootstrap/database"
+
+	"github.com/casbin/casbin/v2/persist"
+	gormAdapter "github.com/casbin/gorm-adapter/v3"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// commitHooksKey is the gin context key holding the hooks registered with OnCommit.
+const commitHooksKey = "casbin.commit_hooks"
+
+// CommitHook writes alongside a policy change, within the database transaction storing it.
+type CommitHook func(tx *gorm.DB) error
+
+// OnCommit registers fn to run within the database transaction of the next policy change
+// made with ctx, after the rules are written and before the transaction commits. An error
+// from fn rolls the change back. Hooks run once; a change that fails discards them.
+func OnCommit(ctx *gin.Context, fn CommitHook) {
+	hooks, _ := ctx.Value(commitHooksKey).([]CommitHook)
+	ctx.Set(commitHooksKey, append(hooks, fn))
+}
+
+// takeCommitHooks returns the hooks registered on ctx and clears them.
+func takeCommitHooks(ctx context.Context) []CommitHook {
+	ginCtx, ok := ctx.(*gin.Context)
+	if !ok {
+		return nil
+	}
+	hooks, _ := ginCtx.Value(commitHooksKey).([]CommitHook)
+	if len(hooks) > 0 {
+		ginCtx.Set(commitHooksKey, []CommitHook(nil))
+	}
+	return hooks
+}
+
+// hookedAdapter is the policy adapter handed to enforcers. Its transactions run the hooks
+// registered with OnCommit before committing, so those writes share the fate of the rules.
+type hookedAdapter struct {
+	*gormAdapter.Adapter
+}
+
+// BeginTransaction starts a database transaction that runs the commit hooks of ctx.
+func (a *hookedAdapter) BeginTransaction(ctx context.Context) (persist.TransactionContext, error) {
+	txContext, err := a.Adapter.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &hookedTransaction{TransactionContext: txContext, ctx: ctx, hooks: takeCommitHooks(ctx)}, nil
+}
+
+// hookedTransaction runs its hooks on the transaction connection right before committing.
+type hookedTransaction struct {
+	persist.TransactionContext
+	ctx   context.Context
+	hooks []CommitHook
+}
+
+// Commit runs the hooks and commits, rolling back instead if a hook fails.
+func (t *hookedTransaction) Commit() error {
+	if len(t.hooks) > 0 {
+		// The adapter session is scoped to the rule table, so hooks get a fresh one on its connection
+		tx := database.DB.Session(&gorm.Session{NewDB: true, Context: t.ctx})
+		tx.Statement.ConnPool = t.GetAdapter().(*gormAdapter.Adapter).GetDb().Statement.ConnPool
+		for _, hook := range t.hooks {
+			if err := hook(tx); err != nil {
+				_ = t.Rollback()
+				return fmt.Errorf("failed to run commit hook: %w", err)
+			}
+		}
+	}
+	return t.TransactionContext.Commit()
+}
@@ -0,0 +1,315 @@
+package casbin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"ac/bootstrap/database"
+	"ac/model"
+
+	casbinModel "github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/util"
+	"github.com/casbin/govaluate"
+	"github.com/gin-gonic/gin"
+)
+
+// Request attribute roots a condition may read, as in r.user.department.
+const (
+	AttributeRootUser   = "user"   // Stored attributes of the checked user
+	AttributeRootObject = "object" // Stored attributes of the checked object
+	AttributeRootEnv    = "env"    // Attributes passed with the check request
+)
+
+// FunctionConditionHolds is the custom matcher function evaluating policy conditions:
+// conditionHolds(p.cond, r.act, r.time, r.user, r.object, r.env).
+const FunctionConditionHolds = "conditionHolds"
+
+// conditionVariables lists the plain request fields a condition may read besides attribute roots.
+var conditionVariables = map[string]struct{}{
+	"r_act":  {},
+	"r_time": {},
+}
+
+// MaxAttributesLength caps the encoded size of stored user and object attributes.
+const MaxAttributesLength = 2000
+
+var (
+	ErrInvalidCondition   = fmt.Errorf("invalid condition expression")
+	ErrAttributesTooLarge = fmt.Errorf("attributes exceed %d bytes when encoded", MaxAttributesLength)
+)
+
+// Attributes holds named values a condition expression is evaluated against.
+type Attributes map[string]interface{}
+
+// RequestAttributes groups the attributes of one permission check.
+type RequestAttributes struct {
+	User   Attributes // Stored user attributes, loaded by the check
+	Object Attributes // Stored object attributes, loaded by the check
+	Env    Attributes // Caller-supplied request attributes
+}
+
+// rvals returns the attribute request values in model order, never nil so conditions can index them.
+func (a RequestAttributes) rvals() []interface{} {
+	return []interface{}{nonNil(a.User), nonNil(a.Object), nonNil(a.Env)}
+}
+
+// nonNil replaces a nil attribute map with an empty one.
+func nonNil(attributes Attributes) map[string]interface{} {
+	if attributes == nil {
+		return map[string]interface{}{}
+	}
+	return attributes
+}
+
+// ValidateCondition checks that a condition compiles and only reads request attributes,
+// e.g. r.object.owner_department == r.user.department. An empty condition is valid.
+func ValidateCondition(condition string) error {
+	if strings.TrimSpace(condition) == "" {
+		return nil
+	}
+
+	expression, err := compileCondition(condition)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCondition, err)
+	}
+
+	for _, token := range expression.Tokens() {
+		switch token.Kind {
+		case govaluate.VARIABLE:
+			name, _ := token.Value.(string)
+			if _, ok := conditionVariables[name]; !ok {
+				return fmt.Errorf("%w: unknown variable %s", ErrInvalidCondition, name)
+			}
+		case govaluate.ACCESSOR:
+			path, _ := token.Value.([]string)
+			if len(path) != 2 || !isAttributeRoot(path[0]) {
+				return fmt.Errorf("%w: unknown attribute %s", ErrInvalidCondition, strings.Join(path, "."))
+			}
+		}
+	}
+	return nil
+}
+
+// isAttributeRoot reports whether an escaped request field is one of the attribute roots.
+func isAttributeRoot(name string) bool {
+	switch name {
+	case "r_" + AttributeRootUser, "r_" + AttributeRootObject, "r_" + AttributeRootEnv:
+		return true
+	default:
+		return false
+	}
+}
+
+// compileCondition parses a condition with the built-in Casbin functions available, escaping r.user.x
+// and the like the way Casbin escapes matchers.
+func compileCondition(condition string) (*govaluate.EvaluableExpression, error) {
+	functions := casbinModel.LoadFunctionMap()
+	return govaluate.NewEvaluableExpressionWithFunctions(util.EscapeAssertion(condition), functions.GetFunctions())
+}
+
+// compiledConditions caches parsed conditions by their text. Stored policies bound the set,
+// and a compiled expression may be evaluated concurrently.
+var compiledConditions sync.Map
+
+// cachedCondition returns the compiled form of a condition, compiling it on first use.
+func cachedCondition(condition string) (*govaluate.EvaluableExpression, error) {
+	if expression, ok := compiledConditions.Load(condition); ok {
+		return expression.(*govaluate.EvaluableExpression), nil
+	}
+	expression, err := compileCondition(condition)
+	if err != nil {
+		return nil, err
+	}
+	compiledConditions.Store(condition, expression)
+	return expression, nil
+}
+
+// conditionHolds is the matcher function behind FunctionConditionHolds. It evaluates conditions
+// like evaluateCondition, so a condition reading an attribute the request lacks denies instead
+// of failing the check, and checks agree with explanations.
+func conditionHolds(args ...interface{}) (interface{}, error) {
+	if len(args) != 6 {
+		return false, fmt.Errorf("%s expects 6 arguments, got %d", FunctionConditionHolds, len(args))
+	}
+	condition, ok1 := args[0].(string)
+	action, ok2 := args[1].(string)
+	timeStr, ok3 := args[2].(string)
+	if !ok1 || !ok2 || !ok3 {
+		return false, fmt.Errorf("%s expects string condition, action and time", FunctionConditionHolds)
+	}
+	user, _ := args[3].(map[string]interface{})
+	object, _ := args[4].(map[string]interface{})
+	env, _ := args[5].(map[string]interface{})
+	return evaluateCondition(condition, action, timeStr, RequestAttributes{User: user, Object: object, Env: env}), nil
+}
+
+// evaluateCondition evaluates a condition against the attributes of a request.
+// An empty condition holds; one that fails to evaluate, such as by reading an
+// attribute the request does not carry, does not.
+func evaluateCondition(condition, action, timeStr string, attributes RequestAttributes) bool {
+	if condition == "" {
+		return true
+	}
+
+	expression, err := cachedCondition(condition)
+	if err != nil {
+		return false
+	}
+
+	values := attributes.rvals()
+	result, err := expression.Evaluate(map[string]interface{}{
+		"r_act":                    action,
+		"r_time":                   timeStr,
+		"r_" + AttributeRootUser:   values[0],
+		"r_" + AttributeRootObject: values[1],
+		"r_" + AttributeRootEnv:    values[2],
+	})
+	if err != nil {
+		return false
+	}
+	matched, ok := result.(bool)
+	return ok && matched
+}
+
+// policyConditions remembers whether any loaded policy carries a condition. Every change to the
+// in-memory policies resets it through invalidateDecisions and the next check looks again;
+// generation keeps a result found before a change from being kept after it.
+var policyConditions struct {
+	sync.Mutex
+	generation uint64
+	known      bool
+	present    bool
+}
+
+// resetConditions forgets whether any loaded policy carries a condition.
+func resetConditions() {
+	policyConditions.Lock()
+	defer policyConditions.Unlock()
+
+	policyConditions.generation++
+	policyConditions.known = false
+}
+
+// hasConditions reports whether any loaded policy carries a condition,
+// so checks can skip loading stored attributes when none will be read.
+// The policies are only scanned on the first check after a change.
+func hasConditions() bool {
+	policyConditions.Lock()
+	if policyConditions.known {
+		present := policyConditions.present
+		policyConditions.Unlock()
+		return present
+	}
+	generation := policyConditions.generation
+	policyConditions.Unlock()
+
+	present := false
+	for _, rule := range enforcer.Load().GetModel()["p"]["p"].Policy {
+		if len(rule) > 4 && rule[4] != "" {
+			present = true
+			break
+		}
+	}
+
+	policyConditions.Lock()
+	defer policyConditions.Unlock()
+	if generation == policyConditions.generation {
+		policyConditions.known = true
+		policyConditions.present = present
+	}
+	return present
+}
+
+// hasPolicies reports whether any p rule is loaded. Checks skip the enforcer while none is,
+// since no policies always means deny.
+func hasPolicies() bool {
+	return len(enforcer.Load().GetModel()["p"]["p"].Policy) > 0
+}
+
+// loadAttributes reads the stored attributes of the users and objects of a check, keyed by code.
+// Nothing is read while no policy carries a condition.
+func loadAttributes(ctx *gin.Context, tenantCode string, userCodes, objectCodes []string) (map[string]Attributes, map[string]Attributes, error) {
+	if !hasConditions() {
+		return nil, nil, nil
+	}
+
+	userAttributes, err := loadUserAttributes(ctx, tenantCode, userCodes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	objectAttributes, err := loadObjectAttributes(ctx, tenantCode, objectCodes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return userAttributes, objectAttributes, nil
+}
+
+// loadUserAttributes reads the stored attributes of users in a tenant, keyed by user code.
+func loadUserAttributes(ctx *gin.Context, tenantCode string, userCodes []string) (map[string]Attributes, error) {
+	var users []model.TblSubject
+	if err := database.DB.WithContext(ctx).Select("code", "attributes").
+		Where("tenant_code = ? AND type = ? AND code IN ? AND deleted = ?", tenantCode, model.SubjectTypeUser, userCodes, model.NotDeleted).
+		Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to query user attributes: %w", err)
+	}
+
+	result := make(map[string]Attributes, len(users))
+	for _, user := range users {
+		attributes, err := ParseAttributes(user.Attributes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid attributes of user %s: %w", user.Code, err)
+		}
+		result[user.Code] = attributes
+	}
+	return result, nil
+}
+
+// loadObjectAttributes reads the stored attributes of objects in a tenant, keyed by object code.
+func loadObjectAttributes(ctx *gin.Context, tenantCode string, objectCodes []string) (map[string]Attributes, error) {
+	var objects []model.TblObject
+	if err := database.DB.WithContext(ctx).Select("code", "attributes").
+		Where("tenant_code = ? AND code IN ? AND deleted = ?", tenantCode, objectCodes, model.NotDeleted).
+		Find(&objects).Error; err != nil {
+		return nil, fmt.Errorf("failed to query object attributes: %w", err)
+	}
+
+	result := make(map[string]Attributes, len(objects))
+	for _, object := range objects {
+		attributes, err := ParseAttributes(object.Attributes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid attributes of object %s: %w", object.Code, err)
+		}
+		result[object.Code] = attributes
+	}
+	return result, nil
+}
+
+// ParseAttributes decodes attributes stored as a JSON object. An empty string yields no attributes.
+func ParseAttributes(raw string) (Attributes, error) {
+	attributes := Attributes{}
+	if strings.TrimSpace(raw) == "" {
+		return attributes, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &attributes); err != nil {
+		return nil, err
+	}
+	return attributes, nil
+}
+
+// FormatAttributes encodes attributes for storage as a JSON object of at most MaxAttributesLength bytes.
+func FormatAttributes(attributes Attributes) (string, error) {
+	if attributes == nil {
+		attributes = Attributes{}
+	}
+	raw, err := json.Marshal(attributes)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) > MaxAttributesLength {
+		return "", ErrAttributesTooLarge
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,184 @@
+package casbin
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ac/model"
+)
+
+func TestCheckPermissionMissingAttributeDenies(t *testing.T) {
+	ctx := newTestContext()
+	tenantCode := newTenant(t)
+	userCode := newSubject(t, tenantCode, model.SubjectTypeUser)
+	objectCode := newObject(t, ctx, tenantCode, "")
+
+	policy := activePolicy(objectCode, "read")
+	policy.Condition = "r.env.ip == '10.0.0.1'"
+	if err := AssignPoliciesToUser(ctx, userCode, []Policy{policy}); err != nil {
+		t.Fatalf("assign policy: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		env  Attributes
+		want bool
+	}{
+		{"attribute missing", nil, false},
+		{"attribute differs", Attributes{"ip": "10.0.0.2"}, false},
+		{"attribute matches", Attributes{"ip": "10.0.0.1"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			allowed, _, err := CheckPermission(ctx, tenantCode, userCode, objectCode, "read", tt.env, now)
+			if err != nil {
+				t.Fatalf("check: %v", err)
+			}
+			if allowed != tt.want {
+				t.Fatalf("check allowed = %v, want %v", allowed, tt.want)
+			}
+
+			explanation, err := ExplainPermission(ctx, tenantCode, userCode, objectCode, "read", tt.env, now)
+			if err != nil {
+				t.Fatalf("explain: %v", err)
+			}
+			if explanation.Allowed != allowed {
+				t.Fatalf("explain allowed = %v, check allowed = %v", explanation.Allowed, allowed)
+			}
+		})
+	}
+}
+
+func TestHasConditionsFollowsPolicyChanges(t *testing.T) {
+	ctx := newTestContext()
+	tenantCode := newTenant(t)
+	userCode := newSubject(t, tenantCode, model.SubjectTypeUser)
+	objectCode := newObject(t, ctx, tenantCode, "")
+
+	// scan is what hasConditions remembers between changes
+	scan := func() bool {
+		for _, rule := range enforcer.Load().GetModel()["p"]["p"].Policy {
+			if len(rule) > 4 && rule[4] != "" {
+				return true
+			}
+		}
+		return false
+	}
+	if got, want := hasConditions(), scan(); got != want {
+		t.Fatalf("hasConditions() = %v before changes, want %v", got, want)
+	}
+
+	policy := activePolicy(objectCode, "flagged")
+	policy.Condition = "r.env.flag == true"
+	if err := AssignPoliciesToUser(ctx, userCode, []Policy{policy}); err != nil {
+		t.Fatalf("assign policy: %v", err)
+	}
+	if !hasConditions() {
+		t.Fatalf("hasConditions() = false after adding a conditional policy")
+	}
+
+	if err := RemovePoliciesFromUser(ctx, userCode, []Policy{policy}); err != nil {
+		t.Fatalf("remove policy: %v", err)
+	}
+	if got, want := hasConditions(), scan(); got != want {
+		t.Fatalf("hasConditions() = %v after removing the policy, want %v", got, want)
+	}
+
+	if err := AssignPoliciesToUser(ctx, userCode, []Policy{policy}); err != nil {
+		t.Fatalf("assign policy: %v", err)
+	}
+	if err := LoadPolicy(ctx); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if !hasConditions() {
+		t.Fatalf("hasConditions() = false after reloading a conditional policy")
+	}
+}
+
+func TestValidateCondition(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition string
+		wantErr   bool
+	}{
+		{"empty", "", false},
+		{"blank", "   ", false},
+		{"attribute comparison", "r.object.owner_department == r.user.department", false},
+		{"env attribute", "r.env.ip == '10.0.0.1'", false},
+		{"action and time", "r.act == 'read' && r.time >= '2026-01-01T00:00:00Z'", false},
+		{"built-in function", "keyMatch(r.env.path, '/api/*')", false},
+		{"unknown root", "r.tenant.name == 'x'", true},
+		{"nested attribute", "r.user.address.city == 'x'", true},
+		{"unknown request field", "r.sub == 'u:x'", true},
+		{"unknown variable", "department == 'x'", true},
+		{"syntax error", "r.env.ip ==", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCondition(tt.condition)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("ValidateCondition(%q) error = %v, want error %v", tt.condition, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidCondition) {
+				t.Fatalf("ValidateCondition(%q) error = %v, want %v", tt.condition, err, ErrInvalidCondition)
+			}
+		})
+	}
+}
+
+func TestEvaluateCondition(t *testing.T) {
+	attributes := RequestAttributes{
+		User:   Attributes{"department": "sales", "level": float64(3)},
+		Object: Attributes{"owner_department": "sales"},
+		Env:    Attributes{"ip": "10.0.0.1"},
+	}
+	tests := []struct {
+		name      string
+		condition string
+		want      bool
+	}{
+		{"empty", "", true},
+		{"attributes match", "r.object.owner_department == r.user.department", true},
+		{"attributes differ", "r.user.department == 'hr'", false},
+		{"numeric comparison", "r.user.level >= 3", true},
+		{"action", "r.act == 'read'", true},
+		{"time", "r.time != ''", true},
+		{"missing attribute", "r.env.device == 'laptop'", false},
+		{"missing attribute negated", "r.env.device != 'laptop'", false},
+		{"unknown root", "r.tenant.name == 'x'", false},
+		{"unknown variable", "department == 'sales'", false},
+		{"non-bool result", "r.user.department", false},
+		{"numeric result", "r.user.level + 1", false},
+		{"does not compile", "r.env.ip ==", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evaluateCondition(tt.condition, "read", "2026-10-19T10:00:00Z", attributes); got != tt.want {
+				t.Fatalf("evaluateCondition(%q) = %v, want %v", tt.condition, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionHolds(t *testing.T) {
+	env := map[string]interface{}{"ip": "10.0.0.1"}
+	got, err := conditionHolds("r.env.ip == '10.0.0.1'", "read", "2026-10-19T10:00:00Z", map[string]interface{}{}, map[string]interface{}{}, env)
+	if err != nil || got != true {
+		t.Fatalf("conditionHolds() = %v, %v, want true", got, err)
+	}
+
+	// A missing attribute denies instead of failing the enforce call
+	got, err = conditionHolds("r.user.department == 'sales'", "read", "2026-10-19T10:00:00Z", map[string]interface{}{}, map[string]interface{}{}, env)
+	if err != nil || got != false {
+		t.Fatalf("conditionHolds() with a missing attribute = %v, %v, want false", got, err)
+	}
+
+	if _, err := conditionHolds("r.env.ip == '10.0.0.1'", "read"); err == nil {
+		t.Fatalf("conditionHolds() with 2 arguments succeeded, want an error")
+	}
+	if _, err := conditionHolds(1, "read", "2026-10-19T10:00:00Z", nil, nil, nil); err == nil {
+		t.Fatalf("conditionHolds() with a non-string condition succeeded, want an error")
+	}
+}
@@ -0,0 +1,234 @@
+package casbin
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"ac/bootstrap/config"
+	"ac/bootstrap/database"
+	"ac/bootstrap/logger"
+	"ac/model"
+
+	casbin "github.com/casbin/casbin/v2"
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/onnttf/kit/dal"
+)
+
+// Decision log destinations
+const (
+	DecisionLogFile     = "file"     // JSON lines appended to decision.log in the log directory
+	DecisionLogDatabase = "database" // Rows inserted into tbl_decision_log
+	DecisionLogBoth     = "both"     // Both of the above
+)
+
+const (
+	decisionLogFileName  = "decision.log"
+	decisionLogQueueSize = 4096            // Records waiting to be written; more are dropped
+	decisionLogBatchSize = 100             // Rows inserted per statement
+	decisionLogFlushTime = 1 * time.Second // Longest a record waits for its batch
+)
+
+// DecisionRecord is one authorization decision as written to the decision log.
+type DecisionRecord struct {
+	RequestId   string    `json:"request_id"`
+	Subject     string    `json:"subject"`
+	Domain      string    `json:"domain"`
+	Object      string    `json:"object"`
+	Action      string    `json:"action"`
+	Time        time.Time `json:"time"`
+	Allowed     bool      `json:"allowed"`
+	MatchedRule []string  `json:"matched_rule"` // Policy that decided the request, empty when none matched
+}
+
+// decisionLog samples Enforce results into a queue drained by the writer StartDecisionLog runs,
+// so checks never wait for the file or the database.
+var decisionLog = struct {
+	enabled           bool
+	toFile            bool
+	toDatabase        bool
+	sampleRate        float64
+	objectSampleRates map[string]float64 // Prefixed object to its sample rate
+	alwaysLogDenies   bool
+
+	records chan DecisionRecord
+	dropped atomic.Int64
+}{}
+
+// configureDecisionLog enables the decision log as configured. Call before the enforcer serves checks.
+func configureDecisionLog(cfg config.DecisionLogConfig) {
+	if cfg.Destination == "" {
+		return
+	}
+
+	decisionLog.enabled = true
+	decisionLog.toFile = cfg.Destination == DecisionLogFile || cfg.Destination == DecisionLogBoth
+	decisionLog.toDatabase = cfg.Destination == DecisionLogDatabase || cfg.Destination == DecisionLogBoth
+	decisionLog.sampleRate = cfg.SampleRate
+	decisionLog.objectSampleRates = make(map[string]float64, len(cfg.ObjectSampleRates))
+	for objectCode, rate := range cfg.ObjectSampleRates {
+		decisionLog.objectSampleRates[AddPrefix(objectCode, EntityObject)] = rate
+	}
+	decisionLog.alwaysLogDenies = cfg.AlwaysLogDenies
+	decisionLog.records = make(chan DecisionRecord, decisionLogQueueSize)
+}
+
+// recordDecision queues the decision on rvals for the decision log if it is sampled.
+// The rule that matched is resolved with e only for sampled decisions.
+func recordDecision(ctx *gin.Context, e *casbin.TransactionalEnforcer, rvals []interface{}, allowed bool) {
+	if !decisionLog.enabled {
+		return
+	}
+
+	object, _ := rvals[2].(string)
+	if !sampleDecision(object, allowed) {
+		return
+	}
+
+	var explain []string
+	if _, matched, err := e.EnforceEx(rvals...); err == nil {
+		explain = matched
+	}
+	subject, _ := rvals[0].(string)
+	domain, _ := rvals[1].(string)
+	action, _ := rvals[3].(string)
+	checkTime, _ := time.Parse(time.RFC3339, rvals[4].(string))
+
+	record := DecisionRecord{
+		RequestId:   requestid.Get(ctx),
+		Subject:     subject,
+		Domain:      domain,
+		Object:      object,
+		Action:      action,
+		Time:        checkTime,
+		Allowed:     allowed,
+		MatchedRule: explain,
+	}
+	select {
+	case decisionLog.records <- record:
+	default:
+		if dropped := decisionLog.dropped.Add(1); dropped%decisionLogQueueSize == 1 {
+			logger.Warnf(ctx, "casbin: decision log queue full, records dropped: dropped=%d", dropped)
+		}
+	}
+}
+
+// sampleDecision decides whether a decision on object is recorded.
+func sampleDecision(object string, allowed bool) bool {
+	if !allowed && decisionLog.alwaysLogDenies {
+		return true
+	}
+	rate, ok := decisionLog.objectSampleRates[object]
+	if !ok {
+		rate = decisionLog.sampleRate
+	}
+	return rate >= 1 || rate > 0 && rand.Float64() < rate
+}
+
+// StartDecisionLog writes queued decisions to the configured destinations until ctx is done,
+// then writes what is left. It does nothing unless a decision log destination is configured.
+func StartDecisionLog(ctx context.Context) {
+	if !decisionLog.enabled {
+		return
+	}
+
+	var file *os.File
+	if decisionLog.toFile {
+		var err error
+		if file, err = logger.OpenFile(decisionLogFileName); err != nil {
+			logger.Errorf(ctx, "casbin: decision log: open file failed, error=%v", err)
+			if !decisionLog.toDatabase {
+				return
+			}
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(decisionLogFlushTime)
+		defer ticker.Stop()
+
+		batch := make([]DecisionRecord, 0, decisionLogBatchSize)
+		for {
+			select {
+			case <-ctx.Done():
+				for drained := false; !drained; {
+					select {
+					case record := <-decisionLog.records:
+						batch = append(batch, record)
+					default:
+						drained = true
+					}
+				}
+				writeDecisions(context.Background(), file, batch)
+				if file != nil {
+					_ = file.Close()
+				}
+				return
+			case record := <-decisionLog.records:
+				if batch = append(batch, record); len(batch) >= decisionLogBatchSize {
+					writeDecisions(ctx, file, batch)
+					batch = batch[:0]
+				}
+			case <-ticker.C:
+				if len(batch) > 0 {
+					writeDecisions(ctx, file, batch)
+					batch = batch[:0]
+				}
+			}
+		}
+	}()
+	logger.Infof(ctx, "casbin: decision log started: file=%v, database=%v, sample_rate=%v, always_log_denies=%v",
+		file != nil, decisionLog.toDatabase, decisionLog.sampleRate, decisionLog.alwaysLogDenies)
+}
+
+// writeDecisions appends records to file, when there is one, and to tbl_decision_log when configured.
+func writeDecisions(ctx context.Context, file *os.File, records []DecisionRecord) {
+	if len(records) == 0 {
+		return
+	}
+
+	if file != nil {
+		var lines strings.Builder
+		for _, record := range records {
+			line, err := json.Marshal(record)
+			if err != nil {
+				continue
+			}
+			lines.Write(line)
+			lines.WriteByte('\n')
+		}
+		if _, err := file.WriteString(lines.String()); err != nil {
+			logger.Errorf(ctx, "casbin: decision log: write file failed, records=%d, error=%v", len(records), err)
+		}
+	}
+
+	if decisionLog.toDatabase {
+		now := time.Now()
+		rows := make([]*model.TblDecisionLog, 0, len(records))
+		for _, record := range records {
+			matchedRule := strings.Join(record.MatchedRule, ", ")
+			row := &model.TblDecisionLog{
+				RequestId:   record.RequestId,
+				Subject:     record.Subject,
+				Domain:      record.Domain,
+				Object:      record.Object,
+				Action:      record.Action,
+				MatchedRule: matchedRule[:min(len(matchedRule), 1000)],
+				CheckTime:   record.Time,
+				CreatedAt:   now,
+			}
+			if record.Allowed {
+				row.Allowed = 1
+			}
+			rows = append(rows, row)
+		}
+		if err := dal.NewRepo[model.TblDecisionLog]().BatchInsert(ctx, database.DB, rows, decisionLogBatchSize); err != nil {
+			logger.Errorf(ctx, "casbin: decision log: insert failed, records=%d, error=%v", len(records), err)
+		}
+	}
+}
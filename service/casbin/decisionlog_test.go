@@ -0,0 +1,79 @@
+package casbin
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"ac/util"
+)
+
+// enableDecisionLog queues every decision for the duration of a test and returns the queue.
+func enableDecisionLog(t *testing.T) chan DecisionRecord {
+	t.Helper()
+
+	records := make(chan DecisionRecord, decisionLogQueueSize)
+	enabled, sampleRate, queue := decisionLog.enabled, decisionLog.sampleRate, decisionLog.records
+	decisionLog.enabled, decisionLog.sampleRate, decisionLog.records = true, 1, records
+	t.Cleanup(func() {
+		decisionLog.enabled, decisionLog.sampleRate, decisionLog.records = enabled, sampleRate, queue
+	})
+	return records
+}
+
+// queuedObjects drains records and returns the objects of the decisions, failing on any allowed one.
+func queuedObjects(t *testing.T, records chan DecisionRecord) []string {
+	t.Helper()
+
+	objects := make([]string, 0)
+	for {
+		select {
+		case record := <-records:
+			if record.Allowed {
+				t.Fatalf("decision on %s recorded as allowed", record.Object)
+			}
+			objects = append(objects, record.Object)
+		default:
+			slices.Sort(objects)
+			return objects
+		}
+	}
+}
+
+func TestDecisionLogWithoutPolicies(t *testing.T) {
+	enableLazyPolicies(t, 0)
+	records := enableDecisionLog(t)
+
+	ctx := newTestContext()
+	tenantCode := newTenant(t)
+	userCode := util.GenerateCode()
+	objectCodes := []string{util.GenerateCode(), util.GenerateCode()}
+	want := []string{AddPrefix(objectCodes[0], EntityObject), AddPrefix(objectCodes[1], EntityObject)}
+	slices.Sort(want)
+
+	requests := []PermissionRequest{
+		{UserCode: userCode, ObjectCode: objectCodes[0], Action: "read"},
+		{UserCode: userCode, ObjectCode: objectCodes[1], Action: "read"},
+	}
+	results, err := BatchCheckPermission(ctx, tenantCode, requests, time.Now())
+	if err != nil {
+		t.Fatalf("batch check: %v", err)
+	}
+	if !slices.Equal(results, []bool{false, false}) {
+		t.Fatalf("batch check results = %v, want all denied", results)
+	}
+	if objects := queuedObjects(t, records); !slices.Equal(objects, want) {
+		t.Fatalf("batch check recorded decisions on %v, want %v", objects, want)
+	}
+
+	permitted, err := FilterPermittedObjects(ctx, tenantCode, userCode, objectCodes, "read", nil, time.Now())
+	if err != nil {
+		t.Fatalf("filter permitted objects: %v", err)
+	}
+	if len(permitted) != 0 {
+		t.Fatalf("permitted objects = %v, want none", permitted)
+	}
+	if objects := queuedObjects(t, records); !slices.Equal(objects, want) {
+		t.Fatalf("filter recorded decisions on %v, want %v", objects, want)
+	}
+}
@@ -0,0 +1,329 @@
+package casbin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/bootstrap/logger"
+	"ac/model"
+
+	"github.com/casbin/casbin/v2/rbac"
+	"github.com/gin-gonic/gin"
+	"github.com/onnttf/kit/dal"
+	"gorm.io/gorm"
+)
+
+// Near-miss reasons reported when a policy did not grant access
+const (
+	MissReasonMissingRole       = "missing_role"        // Subject does not reach the policy subject via g
+	MissReasonObjectNotInGroup  = "object_not_in_group" // Object does not reach the policy object via g2
+	MissReasonActionMismatch    = "action_mismatch"     // Policy grants a different action
+	MissReasonNotYetActive      = "not_yet_active"      // Check time is before the policy begin time
+	MissReasonExpired           = "expired"             // Check time is after the policy end time
+	MissReasonOutsideRecurrence = "outside_recurrence"  // Check time is outside the policy recurring window
+	MissReasonConditionNotMet   = "condition_not_met"   // Policy condition does not hold for the request attributes
+	MissReasonUserDisabled      = "user_disabled"       // Request user is disabled
+	MissReasonRoleDisabled      = "role_disabled"       // Every role path to the policy subject passes a disabled role
+	MissReasonObjectDisabled    = "object_disabled"     // Every group path to the policy object passes a disabled object
+)
+
+// MaxExplainNearMisses caps the number of near-miss policies returned on a deny.
+const MaxExplainNearMisses = 20
+
+// Explanation describes how an authorization decision was reached.
+type Explanation struct {
+	Allowed    bool          // Final decision
+	Reason     string        // One of the DenyReason values when a disabled entity caused the denial
+	Matched    *PolicyMatch  // Policy that decided the result: the granting allow or the overriding deny
+	NearMisses []PolicyMatch // Closest non-matching allow policies, empty when a policy matched
+}
+
+// PolicyMatch links a stored policy rule to the request that was evaluated.
+type PolicyMatch struct {
+	Id           int64    // tbl_casbin_rule id, zero if the row could not be found
+	Subject      string   // Policy subject with Casbin prefix
+	Policy       Policy   // Policy object, action, time window and effect
+	SubjectChain []string // g chain from request subject to policy subject, prefixed
+	ObjectChain  []string // g2 chain from request object to policy object, prefixed
+	Reasons      []string // Near-miss reasons, empty for the matched policy
+}
+
+// ExplainPermission evaluates a permission check within a tenant and reports the policy that decided it,
+// or when nothing matched the allow policies that came closest to granting it. env carries the request attributes.
+func ExplainPermission(ctx *gin.Context, tenantCode, userCode, objectCode, action string, env Attributes, currentTime time.Time) (*Explanation, error) {
+	if enforcer.Load() == nil {
+		logger.Errorf(ctx, "casbin: explain permission failed: enforcer not initialized")
+		return nil, ErrEnforcerNotInitialized
+	}
+
+	if err := validateCode(tenantCode, EntityTenant); err != nil {
+		logger.Errorf(ctx, "casbin: explain permission validation failed: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+
+	if err := validateCode(userCode, EntityUser); err != nil {
+		logger.Errorf(ctx, "casbin: explain permission validation failed: user_code=%s, error=%v", userCode, err)
+		return nil, err
+	}
+
+	if err := validateCode(objectCode, EntityObject); err != nil {
+		logger.Errorf(ctx, "casbin: explain permission validation failed: object_code=%s, error=%v", objectCode, err)
+		return nil, err
+	}
+
+	action = strings.TrimSpace(action)
+	if action == "" {
+		logger.Errorf(ctx, "casbin: explain permission validation failed: empty action")
+		return nil, ErrInvalidPolicyFields
+	}
+
+	subject := AddPrefix(userCode, EntityUser)
+	domain := AddPrefix(tenantCode, EntityTenant)
+	object := AddPrefix(objectCode, EntityObject)
+	timeStr := formatTime(currentTime)
+
+	release, err := ensureTenantsLoaded(ctx, tenantCode)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: explain permission failed to load tenant policies: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+	defer release()
+
+	userAttributes, objectAttributes, err := loadAttributes(ctx, tenantCode, []string{userCode}, []string{objectCode})
+	if err != nil {
+		logger.Errorf(ctx, "casbin: explain check failed to load attributes: user_code=%s, object_code=%s, error=%v", userCode, objectCode, err)
+		return nil, err
+	}
+	attributes := RequestAttributes{User: userAttributes[userCode], Object: objectAttributes[objectCode], Env: env}
+	rvals := append([]interface{}{subject, domain, object, action, timeStr}, attributes.rvals()...)
+
+	logger.Debugf(ctx, "casbin: explain check: subject=%s, domain=%s, object=%s, action=%s, time=%s", subject, domain, object, action, timeStr)
+	allowed, explain := false, []string(nil)
+	if hasPolicies() {
+		allowed, explain, err = enforcer.Load().EnforceEx(rvals...)
+	}
+	if err != nil {
+		logger.Errorf(ctx, "casbin: explain check failed: subject=%s, domain=%s, object=%s, action=%s, time=%s, error=%v", subject, domain, object, action, timeStr, err)
+		return nil, fmt.Errorf("explain check failed (subject=%s, domain=%s, object=%s, action=%s, time=%s): %w",
+			subject, domain, object, action, timeStr, err)
+	}
+
+	roleManager := enforcer.Load().GetRoleManager()
+	objectManager := enforcer.Load().GetNamedRoleManager(GroupingObjectGroup)
+
+	result := &Explanation{Allowed: allowed}
+	if allowed && len(explain) < 5 {
+		return nil, fmt.Errorf("explain check returned incomplete policy: %v", explain)
+	}
+	if len(explain) >= 5 {
+		match, err := newPolicyMatch(explain)
+		if err != nil {
+			return nil, err
+		}
+		match.SubjectChain = findChain(roleManager, subject, explain[0], domain)
+		match.ObjectChain = findChain(objectManager, object, explain[1])
+		result.Matched = &match
+	} else {
+		policyFields, err := enforcer.Load().GetPolicy()
+		if err != nil {
+			logger.Errorf(ctx, "casbin: explain check failed to list policies: error=%v", err)
+			return nil, fmt.Errorf("failed to list policies: %w", err)
+		}
+		result.NearMisses = collectNearMisses(roleManager, objectManager, policyFields, subject, domain, object, action, timeStr, attributes)
+	}
+	if !allowed {
+		if result.Reason, err = disabledDenyReason(subject, domain, object, rvals); err != nil {
+			logger.Errorf(ctx, "casbin: explain check failed to resolve deny reason: subject=%s, object=%s, action=%s, error=%v", subject, object, action, err)
+			return nil, fmt.Errorf("failed to resolve deny reason (subject=%s, object=%s, action=%s): %w", subject, object, action, err)
+		}
+	}
+
+	if err := fillPolicyMatchIds(ctx, result); err != nil {
+		return nil, err
+	}
+
+	logger.Debugf(ctx, "casbin: explain result: allowed=%v, subject=%s, domain=%s, object=%s, action=%s, near_miss_count=%d",
+		allowed, subject, domain, object, action, len(result.NearMisses))
+	return result, nil
+}
+
+// collectNearMisses lists allow policies that fail on at least one but not all of subject and object,
+// ordered by how few conditions they miss. Subject chains are resolved within domain.
+func collectNearMisses(roleManager, objectManager rbac.RoleManager, policyFields [][]string, subject, domain, object, action, timeStr string, attributes RequestAttributes) []PolicyMatch {
+	nearMisses := make([]PolicyMatch, 0)
+	checkTime, _ := time.Parse(time.RFC3339, timeStr)
+	for _, fields := range policyFields {
+		if len(fields) < 5 || effectOf(fields) != EffectAllow {
+			continue
+		}
+
+		subjectChain := findChain(roleManager, subject, fields[0], domain)
+		objectChain := findChain(objectManager, object, fields[1])
+		if subjectChain == nil && objectChain == nil {
+			continue
+		}
+
+		reasons := make([]string, 0)
+		if subjectChain == nil {
+			reasons = append(reasons, MissReasonMissingRole)
+		}
+		if objectChain == nil {
+			reasons = append(reasons, MissReasonObjectNotInGroup)
+		}
+		if subjectChain != nil && !reachableWhileEnabled(roleManager, subject, fields[0], domain) {
+			if isDisabled(subject) {
+				reasons = append(reasons, MissReasonUserDisabled)
+			} else {
+				reasons = append(reasons, MissReasonRoleDisabled)
+			}
+		}
+		if objectChain != nil && !reachableWhileEnabled(objectManager, object, fields[1]) {
+			reasons = append(reasons, MissReasonObjectDisabled)
+		}
+		if fields[2] != action {
+			reasons = append(reasons, MissReasonActionMismatch)
+		}
+
+		match, err := newPolicyMatch(fields)
+		if err != nil {
+			continue
+		}
+		if checkTime.Before(match.Policy.BeginTime) {
+			reasons = append(reasons, MissReasonNotYetActive)
+		}
+		if checkTime.After(match.Policy.EndTime) {
+			reasons = append(reasons, MissReasonExpired)
+		}
+		if !recurrenceContains(match.Policy.Recurrence, checkTime) {
+			reasons = append(reasons, MissReasonOutsideRecurrence)
+		}
+		if !evaluateCondition(match.Policy.Condition, action, timeStr, attributes) {
+			reasons = append(reasons, MissReasonConditionNotMet)
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+
+		match.SubjectChain = subjectChain
+		match.ObjectChain = objectChain
+		match.Reasons = reasons
+		nearMisses = append(nearMisses, match)
+	}
+
+	sort.SliceStable(nearMisses, func(i, j int) bool {
+		return len(nearMisses[i].Reasons) < len(nearMisses[j].Reasons)
+	})
+	if len(nearMisses) > MaxExplainNearMisses {
+		nearMisses = nearMisses[:MaxExplainNearMisses]
+	}
+	return nearMisses
+}
+
+// newPolicyMatch converts raw policy fields into a PolicyMatch without chains.
+func newPolicyMatch(fields []string) (PolicyMatch, error) {
+	policy, err := policyFromFields(fields)
+	if err != nil {
+		return PolicyMatch{}, err
+	}
+
+	return PolicyMatch{
+		Subject: fields[0],
+		Policy:  policy,
+	}, nil
+}
+
+// findChain returns the shortest inheritance path from name to target, both included.
+// Returns nil when target is not reachable. domain is only given for domain-aware role managers.
+func findChain(roleManager rbac.RoleManager, name, target string, domain ...string) []string {
+	if name == target {
+		return []string{name}
+	}
+	if roleManager == nil {
+		return nil
+	}
+
+	parents := map[string]string{name: ""}
+	queue := []string{name}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		roles, err := roleManager.GetRoles(current, domain...)
+		if err != nil {
+			return nil
+		}
+		for _, role := range roles {
+			if _, seen := parents[role]; seen {
+				continue
+			}
+			parents[role] = current
+			if role == target {
+				chain := []string{role}
+				for node := current; node != ""; node = parents[node] {
+					chain = append([]string{node}, chain...)
+				}
+				return chain
+			}
+			queue = append(queue, role)
+		}
+	}
+	return nil
+}
+
+// fillPolicyMatchIds resolves tbl_casbin_rule ids for every policy in the explanation.
+func fillPolicyMatchIds(ctx *gin.Context, result *Explanation) error {
+	matches := make([]*PolicyMatch, 0, len(result.NearMisses)+1)
+	if result.Matched != nil {
+		matches = append(matches, result.Matched)
+	}
+	for i := range result.NearMisses {
+		matches = append(matches, &result.NearMisses[i])
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	subjects := make([]string, 0, len(matches))
+	objects := make([]string, 0, len(matches))
+	for _, match := range matches {
+		subjects = append(subjects, match.Subject)
+		objects = append(objects, policyObjectWithPrefix(match.Policy.Object))
+	}
+
+	rules, err := dal.NewRepo[model.TblCasbinRule]().Query(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+		return db.Where("ptype = ? AND v0 IN ? AND v1 IN ?", "p", subjects, objects)
+	})
+	if err != nil {
+		logger.Errorf(ctx, "casbin: explain failed to query policy rules: error=%v", err)
+		return fmt.Errorf("failed to query policy rules: %w", err)
+	}
+
+	ids := make(map[string]int64, len(rules))
+	for _, rule := range rules {
+		ids[strings.Join([]string{rule.V0, rule.V1, rule.V2, rule.V3, rule.V4, rule.V5}, ",")] = rule.Id
+	}
+	for _, match := range matches {
+		key := strings.Join([]string{
+			match.Subject,
+			policyObjectWithPrefix(match.Policy.Object),
+			match.Policy.Action,
+			FormatWindow(match.Policy.BeginTime, match.Policy.EndTime, match.Policy.Recurrence),
+			match.Policy.Condition,
+			match.Policy.EffectOrDefault(),
+		}, ",")
+		match.Id = ids[key]
+	}
+	return nil
+}
+
+// policyObjectWithPrefix restores the Casbin identifier of a policy object.
+// Tenant-wide grants keep their tenant prefix in Policy.Object.
+func policyObjectWithPrefix(object string) string {
+	if EntityTypeOf(object) == EntityTenant {
+		return object
+	}
+	return AddPrefix(object, EntityObject)
+}
@@ -0,0 +1,216 @@
+package casbin
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/model"
+
+	defaultrolemanager "github.com/casbin/casbin/v2/rbac/default-role-manager"
+)
+
+// explainFixture links u:alice to r:parent through r:child in t:one, and o:leaf to t:one through o:folder.
+func explainFixture(t *testing.T) (*defaultrolemanager.DomainManager, *defaultrolemanager.RoleManagerImpl) {
+	t.Helper()
+
+	roleManager := defaultrolemanager.NewDomainManager(10)
+	for _, link := range [][3]string{
+		{"u:alice", "r:child", "t:one"},
+		{"r:child", "r:parent", "t:one"},
+		{"u:alice", "r:other", "t:two"},
+	} {
+		if err := roleManager.AddLink(link[0], link[1], link[2]); err != nil {
+			t.Fatalf("add role link: %v", err)
+		}
+	}
+
+	objectManager := defaultrolemanager.NewRoleManagerImpl(10)
+	for _, link := range [][2]string{
+		{"o:leaf", "o:folder"},
+		{"o:folder", "t:one"},
+	} {
+		if err := objectManager.AddLink(link[0], link[1]); err != nil {
+			t.Fatalf("add object link: %v", err)
+		}
+	}
+	return roleManager, objectManager
+}
+
+func TestFindChain(t *testing.T) {
+	roleManager, objectManager := explainFixture(t)
+
+	tests := []struct {
+		name  string
+		chain func() []string
+		want  []string
+	}{
+		{"same subject", func() []string { return findChain(roleManager, "u:alice", "u:alice", "t:one") }, []string{"u:alice"}},
+		{"direct role", func() []string { return findChain(roleManager, "u:alice", "r:child", "t:one") }, []string{"u:alice", "r:child"}},
+		{"inherited role", func() []string { return findChain(roleManager, "u:alice", "r:parent", "t:one") }, []string{"u:alice", "r:child", "r:parent"}},
+		{"role of another tenant", func() []string { return findChain(roleManager, "u:alice", "r:other", "t:one") }, nil},
+		{"role in its own tenant", func() []string { return findChain(roleManager, "u:alice", "r:other", "t:two") }, []string{"u:alice", "r:other"}},
+		{"unreachable role", func() []string { return findChain(roleManager, "r:parent", "r:child", "t:one") }, nil},
+		{"parent object", func() []string { return findChain(objectManager, "o:leaf", "o:folder") }, []string{"o:leaf", "o:folder"}},
+		{"tenant root", func() []string { return findChain(objectManager, "o:leaf", "t:one") }, []string{"o:leaf", "o:folder", "t:one"}},
+		{"no role manager", func() []string { return findChain(nil, "o:leaf", "o:folder") }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.chain(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("findChain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectNearMisses(t *testing.T) {
+	roleManager, objectManager := explainFixture(t)
+
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC) // A Monday
+	current := FormatWindow(now.Add(-time.Hour), now.Add(time.Hour), "")
+	policies := [][]string{
+		{"r:parent", "o:folder", "read", current, "", EffectAllow},                                                      // Matches
+		{"r:parent", "o:folder", "read", current, "", EffectDeny},                                                       // Deny, never a near miss
+		{"r:stranger", "o:elsewhere", "read", current, "", EffectAllow},                                                 // Unrelated
+		{"r:parent", "o:folder", "read", FormatWindow(now.Add(-2*time.Hour), now.Add(-time.Hour), ""), "", EffectAllow}, // Expired
+		{"r:child", "t:one", "write", FormatWindow(now.Add(time.Hour), now.Add(2*time.Hour), ""), "", EffectAllow},      // Wrong action, not yet active
+		{"r:stranger", "o:leaf", "read", current, "", EffectAllow},                                                      // Missing role
+		{"r:child", "o:elsewhere", "read", current, "", EffectAllow},                                                    // Object not in group
+		{"u:alice", "o:leaf", "read", FormatWindow(now.Add(-time.Hour), now.Add(time.Hour), "SAT,SUN 09:00-18:00"), "r.env.ip == '10.0.0.1'", EffectAllow},
+	}
+
+	got := collectNearMisses(roleManager, objectManager, policies, "u:alice", "t:one", "o:leaf", "read", formatTime(now), RequestAttributes{})
+
+	type nearMiss struct {
+		subject string
+		reasons []string
+	}
+	want := []nearMiss{
+		{"r:parent", []string{MissReasonExpired}},
+		{"r:stranger", []string{MissReasonMissingRole}},
+		{"r:child", []string{MissReasonObjectNotInGroup}},
+		{"r:child", []string{MissReasonActionMismatch, MissReasonNotYetActive}},
+		{"u:alice", []string{MissReasonOutsideRecurrence, MissReasonConditionNotMet}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("collectNearMisses() returned %d near misses, want %d: %+v", len(got), len(want), got)
+	}
+	for i, match := range got {
+		if match.Subject != want[i].subject || !reflect.DeepEqual(match.Reasons, want[i].reasons) {
+			t.Errorf("near miss %d = %s %v, want %s %v", i, match.Subject, match.Reasons, want[i].subject, want[i].reasons)
+		}
+	}
+
+	// Chains show how far the request got towards the policy
+	if chain := got[0].SubjectChain; !reflect.DeepEqual(chain, []string{"u:alice", "r:child", "r:parent"}) {
+		t.Errorf("subject chain = %v", chain)
+	}
+	if chain := got[0].ObjectChain; !reflect.DeepEqual(chain, []string{"o:leaf", "o:folder"}) {
+		t.Errorf("object chain = %v", chain)
+	}
+	if got[1].SubjectChain != nil {
+		t.Errorf("subject chain of a missing role = %v, want nil", got[1].SubjectChain)
+	}
+	if chain := got[3].ObjectChain; !reflect.DeepEqual(chain, []string{"o:leaf", "o:folder", "t:one"}) {
+		t.Errorf("object chain to the tenant root = %v", chain)
+	}
+}
+
+func TestCollectNearMissesDisabledEntities(t *testing.T) {
+	roleManager, objectManager := explainFixture(t)
+
+	disabledEntities.Lock()
+	disabledEntities.codes["r:child"] = struct{}{}
+	disabledEntities.codes["o:folder"] = struct{}{}
+	disabledEntities.Unlock()
+	t.Cleanup(func() {
+		disabledEntities.Lock()
+		delete(disabledEntities.codes, "r:child")
+		delete(disabledEntities.codes, "o:folder")
+		disabledEntities.Unlock()
+	})
+
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	policies := [][]string{{"r:parent", "t:one", "read", FormatWindow(now.Add(-time.Hour), now.Add(time.Hour), ""), "", EffectAllow}}
+
+	got := collectNearMisses(roleManager, objectManager, policies, "u:alice", "t:one", "o:leaf", "read", formatTime(now), RequestAttributes{})
+	if len(got) != 1 {
+		t.Fatalf("collectNearMisses() returned %d near misses, want 1", len(got))
+	}
+	if want := []string{MissReasonRoleDisabled, MissReasonObjectDisabled}; !reflect.DeepEqual(got[0].Reasons, want) {
+		t.Fatalf("reasons = %v, want %v", got[0].Reasons, want)
+	}
+}
+
+func TestCollectNearMissesLimit(t *testing.T) {
+	roleManager, objectManager := explainFixture(t)
+
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	window := FormatWindow(now.Add(-time.Hour), now.Add(time.Hour), "")
+	policies := make([][]string, 0, MaxExplainNearMisses+5)
+	for i := range MaxExplainNearMisses + 5 {
+		policies = append(policies, []string{"r:child", "o:leaf", fmt.Sprintf("action-%d", i), window, "", EffectAllow})
+	}
+
+	got := collectNearMisses(roleManager, objectManager, policies, "u:alice", "t:one", "o:leaf", "read", formatTime(now), RequestAttributes{})
+	if len(got) != MaxExplainNearMisses {
+		t.Fatalf("collectNearMisses() returned %d near misses, want %d", len(got), MaxExplainNearMisses)
+	}
+}
+
+func TestExplainPermissionThroughInheritance(t *testing.T) {
+	ctx := newTestContext()
+	tenantCode := newTenant(t)
+	userCode := newSubject(t, tenantCode, model.SubjectTypeUser)
+	childRole := newSubject(t, tenantCode, model.SubjectTypeRole)
+	parentRole := newSubject(t, tenantCode, model.SubjectTypeRole)
+	folder := newObject(t, ctx, tenantCode, "")
+	leaf := newObject(t, ctx, tenantCode, folder)
+
+	changed, err := SetRoleParent(ctx, database.DB, tenantCode, childRole, parentRole)
+	if err != nil {
+		t.Fatalf("set role parent: %v", err)
+	}
+	if err := SyncChangedRules(ctx, changed); err != nil {
+		t.Fatalf("sync role parent: %v", err)
+	}
+	if err := AssignRolesToUser(ctx, tenantCode, userCode, []string{childRole}); err != nil {
+		t.Fatalf("assign role: %v", err)
+	}
+	if err := AssignPoliciesToRole(ctx, parentRole, []Policy{activePolicy(folder, "read")}); err != nil {
+		t.Fatalf("assign policy: %v", err)
+	}
+
+	explanation, err := ExplainPermission(ctx, tenantCode, userCode, leaf, "read", nil, time.Now())
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	if !explanation.Allowed || explanation.Matched == nil {
+		t.Fatalf("explain = %+v, want allowed by a matched policy", explanation)
+	}
+	wantSubjects := []string{AddPrefix(userCode, EntityUser), AddPrefix(childRole, EntityRole), AddPrefix(parentRole, EntityRole)}
+	if !reflect.DeepEqual(explanation.Matched.SubjectChain, wantSubjects) {
+		t.Errorf("subject chain = %v, want %v", explanation.Matched.SubjectChain, wantSubjects)
+	}
+	wantObjects := []string{AddPrefix(leaf, EntityObject), AddPrefix(folder, EntityObject)}
+	if !reflect.DeepEqual(explanation.Matched.ObjectChain, wantObjects) {
+		t.Errorf("object chain = %v, want %v", explanation.Matched.ObjectChain, wantObjects)
+	}
+	if explanation.Matched.Id == 0 {
+		t.Errorf("matched policy id not resolved")
+	}
+
+	explanation, err = ExplainPermission(ctx, tenantCode, userCode, leaf, "write", nil, time.Now())
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	if explanation.Allowed || len(explanation.NearMisses) != 1 {
+		t.Fatalf("explain = %+v, want denied with one near miss", explanation)
+	}
+	if nearMiss := explanation.NearMisses[0]; !reflect.DeepEqual(nearMiss.Reasons, []string{MissReasonActionMismatch}) || nearMiss.Id == 0 {
+		t.Errorf("near miss = %+v, want an action mismatch with its id", nearMiss)
+	}
+}
@@ -0,0 +1,367 @@
+package casbin
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/bootstrap/logger"
+	"ac/model"
+
+	casbin "github.com/casbin/casbin/v2"
+	casbinModel "github.com/casbin/casbin/v2/model"
+	gormAdapter "github.com/casbin/gorm-adapter/v3"
+)
+
+// Policy load modes
+const (
+	PolicyLoadFull   = "full"   // Every rule is loaded at startup
+	PolicyLoadTenant = "tenant" // The rules of a tenant are loaded on first use
+)
+
+// lazyPolicies tracks the tenants whose rules are in memory when policies load per tenant.
+// A tenant's rules are its role assignments, the hierarchy of its objects, the policies of
+// its users and roles and the policies on its objects.
+var lazyPolicies = struct {
+	sync.RWMutex
+	enabled    bool
+	maxTenants int                      // 0 keeps every loaded tenant
+	tenants    map[string]*atomic.Int64 // Tenant code to when it was last used, in Unix nanoseconds
+	pins       map[string]int           // Tenant code to the number of calls using its rules, never evicted
+
+	loadMu sync.Mutex // Serializes loads and rebuilds, so each tenant is read once
+}{tenants: make(map[string]*atomic.Int64), pins: make(map[string]int)}
+
+// configureLazyPolicies makes the enforcer load the rules of a tenant on first use and keep at
+// most maxTenants tenants in memory, evicting the least recently used. Call before the enforcer is created.
+func configureLazyPolicies(maxTenants int) {
+	lazyPolicies.Lock()
+	defer lazyPolicies.Unlock()
+
+	lazyPolicies.enabled = true
+	lazyPolicies.maxTenants = maxTenants
+}
+
+// ensureTenantsLoaded loads the rules of the given tenants that are not in memory yet and keeps
+// them from being evicted until release is called, so callers can read and change them.
+// It does nothing unless policies load per tenant.
+func ensureTenantsLoaded(ctx context.Context, tenantCodes ...string) (release func(), err error) {
+	if !lazyPolicies.enabled {
+		return releaseNothing, nil
+	}
+
+	release = pinTenants(tenantCodes)
+	if err := loadTenants(ctx, tenantCodes); err != nil {
+		release()
+		return releaseNothing, err
+	}
+	return release, nil
+}
+
+// releaseNothing is the release of calls that pinned no tenant.
+func releaseNothing() {}
+
+// pinTenants keeps the given tenants from being evicted until the returned function is called.
+func pinTenants(tenantCodes []string) func() {
+	lazyPolicies.Lock()
+	for _, tenantCode := range tenantCodes {
+		lazyPolicies.pins[tenantCode]++
+	}
+	lazyPolicies.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			lazyPolicies.Lock()
+			defer lazyPolicies.Unlock()
+			for _, tenantCode := range tenantCodes {
+				if lazyPolicies.pins[tenantCode]--; lazyPolicies.pins[tenantCode] <= 0 {
+					delete(lazyPolicies.pins, tenantCode)
+				}
+			}
+		})
+	}
+}
+
+// loadTenants loads the rules of the given tenants that are not in memory yet.
+func loadTenants(ctx context.Context, tenantCodes []string) error {
+	missing := missingTenants(tenantCodes)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	lazyPolicies.loadMu.Lock()
+	defer lazyPolicies.loadMu.Unlock()
+
+	// Another caller may have loaded them while this one waited
+	if missing = missingTenants(missing); len(missing) == 0 {
+		return nil
+	}
+
+	lazyPolicies.RLock()
+	loaded := len(lazyPolicies.tenants)
+	lazyPolicies.RUnlock()
+	if max := lazyPolicies.maxTenants; max > 0 && loaded+len(missing) > max {
+		return evictTenants(ctx, missing)
+	}
+
+	for _, tenantCode := range missing {
+		rules, err := fetchTenantRules(ctx, tenantCode)
+		if err != nil {
+			return err
+		}
+
+		policyMu.RLock()
+		err = addRules(enforcer.Load(), rules)
+		if err == nil {
+			markTenantLoaded(tenantCode)
+		}
+		invalidateDecisions()
+		policyMu.RUnlock()
+		if err != nil {
+			return fmt.Errorf("failed to load policies of tenant %s: %w", tenantCode, err)
+		}
+		logger.Infof(ctx, "casbin: tenant policies loaded: tenant_code=%s, rules=%d", tenantCode, countPolicies(rules))
+	}
+	return nil
+}
+
+// ensureEntityTenantsLoaded loads and pins the rules of the tenants owning the given prefixed users,
+// roles, objects or tenants like ensureTenantsLoaded. It does nothing unless policies load per tenant.
+func ensureEntityTenantsLoaded(ctx context.Context, entities ...string) (release func(), err error) {
+	if !lazyPolicies.enabled {
+		return releaseNothing, nil
+	}
+
+	tenantCodes := make([]string, 0, len(entities))
+	subjectCodes := make([]string, 0, len(entities))
+	objectCodes := make([]string, 0, len(entities))
+	for _, entity := range entities {
+		switch entityType := EntityTypeOf(entity); entityType {
+		case EntityUser, EntityRole:
+			subjectCodes = append(subjectCodes, RemovePrefix(entity, entityType))
+		case EntityObject:
+			objectCodes = append(objectCodes, RemovePrefix(entity, entityType))
+		case EntityTenant:
+			tenantCodes = append(tenantCodes, RemovePrefix(entity, entityType))
+		}
+	}
+
+	if len(subjectCodes) > 0 {
+		var owners []string
+		if err := database.DB.WithContext(ctx).Model(&model.TblSubject{}).
+			Where("code IN ?", subjectCodes).Distinct().Pluck("tenant_code", &owners).Error; err != nil {
+			return releaseNothing, fmt.Errorf("failed to query subject tenants: %w", err)
+		}
+		tenantCodes = append(tenantCodes, owners...)
+	}
+	if len(objectCodes) > 0 {
+		var owners []string
+		if err := database.DB.WithContext(ctx).Model(&model.TblObject{}).
+			Where("code IN ?", objectCodes).Distinct().Pluck("tenant_code", &owners).Error; err != nil {
+			return releaseNothing, fmt.Errorf("failed to query object tenants: %w", err)
+		}
+		tenantCodes = append(tenantCodes, owners...)
+	}
+	return ensureTenantsLoaded(ctx, tenantCodes...)
+}
+
+// missingTenants returns the tenants not in memory and marks the others as just used.
+func missingTenants(tenantCodes []string) []string {
+	lazyPolicies.RLock()
+	defer lazyPolicies.RUnlock()
+
+	now := time.Now().UnixNano()
+	missing := make([]string, 0)
+	for _, tenantCode := range tenantCodes {
+		if lastUsed, ok := lazyPolicies.tenants[tenantCode]; ok {
+			lastUsed.Store(now)
+		} else if !slices.Contains(missing, tenantCode) {
+			missing = append(missing, tenantCode)
+		}
+	}
+	return missing
+}
+
+// markTenantLoaded records that the rules of a tenant are in memory.
+func markTenantLoaded(tenantCode string) {
+	lastUsed := &atomic.Int64{}
+	lastUsed.Store(time.Now().UnixNano())
+
+	lazyPolicies.Lock()
+	lazyPolicies.tenants[tenantCode] = lastUsed
+	lazyPolicies.Unlock()
+}
+
+// loadedTenants lists the tenants whose rules are in memory.
+func loadedTenants() []string {
+	lazyPolicies.RLock()
+	defer lazyPolicies.RUnlock()
+
+	tenantCodes := make([]string, 0, len(lazyPolicies.tenants))
+	for tenantCode := range lazyPolicies.tenants {
+		tenantCodes = append(tenantCodes, tenantCode)
+	}
+	return tenantCodes
+}
+
+// evictTenants makes room for the missing tenants by dropping the least recently used ones,
+// then swaps in an enforcer rebuilt from the tenants kept. Rules may be shared between
+// tenants, so rebuilding is the only way to drop a tenant without breaking another.
+// Pinned tenants are kept even when that leaves more than the maximum in memory.
+// Callers hold lazyPolicies.loadMu.
+func evictTenants(ctx context.Context, missing []string) error {
+	lazyPolicies.RLock()
+	type candidate struct {
+		tenantCode string
+		lastUsed   int64
+	}
+	candidates := make([]candidate, 0, len(lazyPolicies.tenants))
+	kept := make([]string, 0, lazyPolicies.maxTenants+len(missing))
+	for tenantCode, lastUsed := range lazyPolicies.tenants {
+		if lazyPolicies.pins[tenantCode] > 0 {
+			kept = append(kept, tenantCode)
+		} else {
+			candidates = append(candidates, candidate{tenantCode, lastUsed.Load()})
+		}
+	}
+	lazyPolicies.RUnlock()
+
+	// Keep the most recently used tenants that fit next to the pinned and missing ones
+	keep := max(lazyPolicies.maxTenants-len(kept)-len(missing), 0)
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].lastUsed > candidates[j].lastUsed })
+	for i := 0; i < len(candidates) && i < keep; i++ {
+		kept = append(kept, candidates[i].tenantCode)
+	}
+	evicted := len(candidates) - min(keep, len(candidates))
+	kept = append(kept, missing...)
+
+	policyMu.Lock()
+	defer policyMu.Unlock()
+
+	e, err := rebuildEnforcer(ctx, kept)
+	if err != nil {
+		return err
+	}
+	enforcer.Store(e)
+	invalidateDecisions()
+
+	logger.Infof(ctx, "casbin: tenant policies evicted: evicted=%d, loaded=%d, rules=%d", evicted, len(kept), countRules(e))
+	return nil
+}
+
+// rebuildEnforcer builds an enforcer holding the rules of the given tenants, or every rule
+// unless policies load per tenant, and records those tenants as loaded. Callers hold
+// lazyPolicies.loadMu and policyMu, and swap the enforcer in.
+func rebuildEnforcer(ctx context.Context, tenantCodes []string) (*casbin.TransactionalEnforcer, error) {
+	e, err := newEnforcer()
+	if err != nil {
+		return nil, err
+	}
+	if !lazyPolicies.enabled {
+		return e, nil
+	}
+
+	tenants := make(map[string]*atomic.Int64, len(tenantCodes))
+	for _, tenantCode := range tenantCodes {
+		rules, err := fetchTenantRules(ctx, tenantCode)
+		if err != nil {
+			return nil, err
+		}
+		if err := addRules(e, rules); err != nil {
+			return nil, fmt.Errorf("failed to load policies of tenant %s: %w", tenantCode, err)
+		}
+		lastUsed := &atomic.Int64{}
+		lastUsed.Store(time.Now().UnixNano())
+		tenants[tenantCode] = lastUsed
+	}
+
+	lazyPolicies.Lock()
+	lazyPolicies.tenants = tenants
+	lazyPolicies.Unlock()
+	return e, nil
+}
+
+// fetchTenantRules reads the rules of a tenant into a scratch model through the filtered
+// loading of the adapter. Callers hold lazyPolicies.loadMu.
+func fetchTenantRules(ctx context.Context, tenantCode string) (casbinModel.Model, error) {
+	var subjects []model.TblSubject
+	if err := database.DB.WithContext(ctx).Select("code", "type").
+		Where("tenant_code = ?", tenantCode).Find(&subjects).Error; err != nil {
+		return nil, fmt.Errorf("failed to query subjects of tenant %s: %w", tenantCode, err)
+	}
+	var objectCodes []string
+	if err := database.DB.WithContext(ctx).Model(&model.TblObject{}).
+		Where("tenant_code = ?", tenantCode).Pluck("code", &objectCodes).Error; err != nil {
+		return nil, fmt.Errorf("failed to query objects of tenant %s: %w", tenantCode, err)
+	}
+
+	tenantWithPrefix := AddPrefix(tenantCode, EntityTenant)
+	filters := []gormAdapter.Filter{
+		{Ptype: []string{GroupingUserRole}, V2: []string{tenantWithPrefix}},
+		{Ptype: []string{"p"}, V1: []string{tenantWithPrefix}},
+	}
+
+	subjectsWithPrefix := make([]string, 0, len(subjects))
+	for _, subject := range subjects {
+		entityType := EntityUser
+		if subject.Type == model.SubjectTypeRole {
+			entityType = EntityRole
+		}
+		subjectsWithPrefix = append(subjectsWithPrefix, AddPrefix(subject.Code, entityType))
+	}
+	for start := 0; start < len(subjectsWithPrefix); start += purgeBatchSize {
+		chunk := subjectsWithPrefix[start:min(start+purgeBatchSize, len(subjectsWithPrefix))]
+		filters = append(filters, gormAdapter.Filter{Ptype: []string{"p"}, V0: chunk})
+	}
+
+	objectsWithPrefix := make([]string, 0, len(objectCodes))
+	for _, objectCode := range objectCodes {
+		objectsWithPrefix = append(objectsWithPrefix, AddPrefix(objectCode, EntityObject))
+	}
+	for start := 0; start < len(objectsWithPrefix); start += purgeBatchSize {
+		chunk := objectsWithPrefix[start:min(start+purgeBatchSize, len(objectsWithPrefix))]
+		filters = append(filters,
+			gormAdapter.Filter{Ptype: []string{GroupingObjectGroup}, V0: chunk},
+			gormAdapter.Filter{Ptype: []string{"p"}, V1: chunk},
+		)
+	}
+
+	m, err := newModel()
+	if err != nil {
+		return nil, err
+	}
+	if err := policyAdapter.LoadFilteredPolicy(m, filters); err != nil {
+		return nil, fmt.Errorf("failed to load policies of tenant %s: %w", tenantCode, err)
+	}
+	return m, nil
+}
+
+// addRules adds the rules of a scratch model to e without writing them to the database.
+// Rules e already holds are skipped, since casbin adds none of a batch holding one of them.
+func addRules(e *casbin.TransactionalEnforcer, m casbinModel.Model) error {
+	for _, sec := range []string{"p", "g"} {
+		for ptype, assertion := range m[sec] {
+			rules := make([][]string, 0, len(assertion.Policy))
+			for _, rule := range assertion.Policy {
+				if has, err := e.GetModel().HasPolicyEx(sec, ptype, rule); err != nil {
+					return err
+				} else if !has {
+					rules = append(rules, rule)
+				}
+			}
+			if len(rules) == 0 {
+				continue
+			}
+			if _, err := e.SelfAddPolicies(sec, ptype, rules); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package casbin
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/model"
+
+	gormAdapter "github.com/casbin/gorm-adapter/v3"
+	"gorm.io/gorm"
+)
+
+// enableLazyPolicies switches to per-tenant loading with an empty enforcer for the duration of a test.
+func enableLazyPolicies(t *testing.T, maxTenants int) {
+	t.Helper()
+
+	lazyPolicies.Lock()
+	lazyPolicies.enabled = true
+	lazyPolicies.maxTenants = maxTenants
+	lazyPolicies.tenants = make(map[string]*atomic.Int64)
+	lazyPolicies.Unlock()
+
+	// Filtered loads mark the adapter as filtered, which would keep later enforcers from loading
+	adapter := policyAdapter
+	filteredAdapter, err := gormAdapter.NewAdapterByDBWithCustomTable(database.DB, &model.TblCasbinRule{}, model.TableNameTblCasbinRule)
+	if err != nil {
+		t.Fatalf("create adapter: %v", err)
+	}
+	policyAdapter = filteredAdapter
+
+	e, err := newEnforcer()
+	if err != nil {
+		t.Fatalf("create enforcer: %v", err)
+	}
+	enforcer.Store(e)
+	invalidateDecisions()
+
+	t.Cleanup(func() {
+		lazyPolicies.Lock()
+		lazyPolicies.enabled = false
+		lazyPolicies.maxTenants = 0
+		lazyPolicies.tenants = make(map[string]*atomic.Int64)
+		lazyPolicies.Unlock()
+		policyAdapter = adapter
+
+		if err := LoadPolicy(newTestContext()); err != nil {
+			t.Errorf("reload: %v", err)
+		}
+	})
+}
+
+// lazyTenant is a tenant with a user granted read on one of its objects.
+type lazyTenant struct {
+	tenantCode string
+	userCode   string
+	objectCode string
+}
+
+// newLazyTenant stores a tenant with a user allowed to read one of its objects.
+func newLazyTenant(t *testing.T) lazyTenant {
+	t.Helper()
+
+	ctx := newTestContext()
+	tenant := lazyTenant{tenantCode: newTenant(t)}
+	tenant.userCode = newSubject(t, tenant.tenantCode, model.SubjectTypeUser)
+	tenant.objectCode = newObject(t, ctx, tenant.tenantCode, "")
+	if err := AssignPoliciesToUser(ctx, tenant.userCode, []Policy{activePolicy(tenant.objectCode, "read")}); err != nil {
+		t.Fatalf("assign policy: %v", err)
+	}
+	return tenant
+}
+
+// check reports whether the user of the tenant may perform action on its object.
+func (tenant lazyTenant) check(t *testing.T, action string) bool {
+	t.Helper()
+
+	allowed, _, err := CheckPermission(newTestContext(), tenant.tenantCode, tenant.userCode, tenant.objectCode, action, nil, time.Now())
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	return allowed
+}
+
+// assertLoadedTenants fails unless exactly the given tenants are in memory.
+func assertLoadedTenants(t *testing.T, want ...lazyTenant) {
+	t.Helper()
+
+	got := loadedTenants()
+	wantCodes := make([]string, 0, len(want))
+	for _, tenant := range want {
+		wantCodes = append(wantCodes, tenant.tenantCode)
+	}
+	slices.Sort(got)
+	slices.Sort(wantCodes)
+	if !slices.Equal(got, wantCodes) {
+		t.Fatalf("loaded tenants = %v, want %v", got, wantCodes)
+	}
+}
+
+func TestLazyTenantLoadedOnFirstUse(t *testing.T) {
+	first, second := newLazyTenant(t), newLazyTenant(t)
+	enableLazyPolicies(t, 0)
+
+	assertLoadedTenants(t)
+	if policies, _ := enforcer.Load().GetFilteredPolicy(0, AddPrefix(first.userCode, EntityUser)); len(policies) != 0 {
+		t.Fatalf("enforcer holds %d policies of a tenant not used yet", len(policies))
+	}
+
+	if !first.check(t, "read") {
+		t.Fatalf("check denied on first use of the tenant")
+	}
+	assertLoadedTenants(t, first)
+
+	if !second.check(t, "read") || !first.check(t, "read") {
+		t.Fatalf("check denied after loading a second tenant")
+	}
+	assertLoadedTenants(t, first, second)
+}
+
+func TestLazyTenantEviction(t *testing.T) {
+	first, second, third := newLazyTenant(t), newLazyTenant(t), newLazyTenant(t)
+	enableLazyPolicies(t, 2)
+
+	for _, tenant := range []lazyTenant{first, second, first} {
+		if !tenant.check(t, "read") {
+			t.Fatalf("check denied for tenant %s", tenant.tenantCode)
+		}
+	}
+	assertLoadedTenants(t, first, second)
+
+	// second is the least recently used
+	if !third.check(t, "read") {
+		t.Fatalf("check denied for the tenant loaded by eviction")
+	}
+	assertLoadedTenants(t, first, third)
+	if policies, _ := enforcer.Load().GetFilteredPolicy(0, AddPrefix(second.userCode, EntityUser)); len(policies) != 0 {
+		t.Fatalf("enforcer holds %d policies of an evicted tenant", len(policies))
+	}
+
+	// An evicted tenant loads again on its next use, evicting first in turn
+	if !second.check(t, "read") {
+		t.Fatalf("check denied for an evicted tenant")
+	}
+	assertLoadedTenants(t, third, second)
+}
+
+func TestLazyTenantEvictionRacingWrite(t *testing.T) {
+	first, second := newLazyTenant(t), newLazyTenant(t)
+	enableLazyPolicies(t, 1)
+
+	const writes, checkers = 40, 4
+	ctx := newTestContext()
+	var wg sync.WaitGroup
+	errs := make(chan error, checkers+1)
+
+	// Alternating tenants evicts one on every check
+	for range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range writes {
+				for _, tenant := range []lazyTenant{first, second} {
+					if _, _, err := CheckPermission(newTestContext(), tenant.tenantCode, tenant.userCode, tenant.objectCode, "read", nil, time.Now()); err != nil {
+						errs <- fmt.Errorf("check: %w", err)
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	// Grants every action, then revokes the even ones
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range writes {
+			if err := AssignPoliciesToUser(ctx, first.userCode, []Policy{activePolicy(first.objectCode, fmt.Sprintf("race-%d", i))}); err != nil {
+				errs <- fmt.Errorf("assign policy %d: %w", i, err)
+				return
+			}
+		}
+		for i := 0; i < writes; i += 2 {
+			if err := RemovePoliciesFromUser(ctx, first.userCode, []Policy{activePolicy(first.objectCode, fmt.Sprintf("race-%d", i))}); err != nil {
+				errs <- fmt.Errorf("remove policy %d: %w", i, err)
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	for i := range writes {
+		if got, want := first.check(t, fmt.Sprintf("race-%d", i)), i%2 == 1; got != want {
+			t.Errorf("check race-%d allowed = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestLazyTenantRestore(t *testing.T) {
+	tenant := newLazyTenant(t)
+	enableLazyPolicies(t, 0)
+
+	// Archive the stored read grant and a write grant, as if the user had been deleted and re-granted read
+	user := AddPrefix(tenant.userCode, EntityUser)
+	var grant model.TblCasbinRule
+	if err := database.DB.Where("ptype = ? AND v0 = ?", "p", user).First(&grant).Error; err != nil {
+		t.Fatalf("query grant: %v", err)
+	}
+	read := model.TblCasbinRuleArchive{Entity: user, Ptype: grant.Ptype, V0: grant.V0, V1: grant.V1, V2: grant.V2, V3: grant.V3, V4: grant.V4, V5: grant.V5}
+	write := read
+	write.V2 = "write"
+	if err := database.DB.Create([]model.TblCasbinRuleArchive{read, write}).Error; err != nil {
+		t.Fatalf("archive rules: %v", err)
+	}
+
+	ctx := newTestContext()
+	var restored *RestoredRules
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		restored, err = RestoreEntityRules(ctx, tx, tenant.tenantCode, tenant.userCode, EntityUser, "", true)
+		return err
+	}); err != nil {
+		t.Fatalf("restore rules of a tenant not loaded: %v", err)
+	}
+	if restored.Policies != 1 || restored.Skipped != 1 {
+		t.Fatalf("restored policies = %d, skipped = %d, want 1 and 1", restored.Policies, restored.Skipped)
+	}
+	assertLoadedTenants(t)
+
+	if err := SyncRestoredRules(ctx, restored); err != nil {
+		t.Fatalf("sync restored rules: %v", err)
+	}
+	assertLoadedTenants(t, tenant)
+	if policies, _ := enforcer.Load().GetFilteredPolicy(0, user); len(policies) != 2 {
+		t.Fatalf("enforcer holds %d policies of the restored user, want 2", len(policies))
+	}
+	if !tenant.check(t, "read") || !tenant.check(t, "write") {
+		t.Fatalf("check denied after restore")
+	}
+}
@@ -0,0 +1,166 @@
+package casbin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/bootstrap/logger"
+	"ac/model"
+
+	casbin "github.com/casbin/casbin/v2"
+	"gorm.io/gorm"
+)
+
+// purgeBatchSize caps the number of rows read or ids deleted by one statement.
+const purgeBatchSize = 500
+
+// PurgeEntityRules hard-deletes within tx every rule still referencing the given prefixed
+// entities, along with the rules archived when they were deleted. Returns how many live
+// and archived rules were, or in dry-run mode would be, removed.
+// Call SyncPurgedEntities once tx has committed.
+func PurgeEntityRules(ctx context.Context, tx *gorm.DB, entities []string, dryRun bool) (int64, int64, error) {
+	if len(entities) == 0 {
+		return 0, 0, nil
+	}
+
+	var rules int64
+	for entityType, references := range entityReferences {
+		typed := make([]string, 0, len(entities))
+		for _, entity := range entities {
+			if EntityTypeOf(entity) == entityType {
+				typed = append(typed, entity)
+			}
+		}
+		if len(typed) == 0 {
+			continue
+		}
+
+		for _, reference := range references {
+			column := fmt.Sprintf("v%d", reference.fieldIndex)
+			query := tx.WithContext(ctx).Model(&model.TblCasbinRule{}).
+				Where("ptype = ? AND "+column+" IN ?", reference.ptype, typed)
+
+			var affected int64
+			if dryRun {
+				if err := query.Count(&affected).Error; err != nil {
+					return 0, 0, fmt.Errorf("failed to count rules (ptype=%s, field=%s): %w", reference.ptype, column, err)
+				}
+			} else {
+				result := query.Delete(&model.TblCasbinRule{})
+				if result.Error != nil {
+					return 0, 0, fmt.Errorf("failed to purge rules (ptype=%s, field=%s): %w", reference.ptype, column, result.Error)
+				}
+				affected = result.RowsAffected
+			}
+			rules += affected
+		}
+	}
+
+	var archived int64
+	query := tx.WithContext(ctx).Model(&model.TblCasbinRuleArchive{}).Where("entity IN ?", entities)
+	if dryRun {
+		if err := query.Count(&archived).Error; err != nil {
+			return 0, 0, fmt.Errorf("failed to count archived rules: %w", err)
+		}
+	} else {
+		result := query.Delete(&model.TblCasbinRuleArchive{})
+		if result.Error != nil {
+			return 0, 0, fmt.Errorf("failed to purge archived rules: %w", result.Error)
+		}
+		archived = result.RowsAffected
+	}
+
+	logger.Infof(ctx, "casbin: entity rules purged: entity_count=%d, rules=%d, archived_rules=%d, dry_run=%v", len(entities), rules, archived, dryRun)
+	return rules, archived, nil
+}
+
+// SyncPurgedEntities drops rules purged by PurgeEntityRules from the in-memory enforcer
+// without writing to the database again. Falls back to a full reload if that fails.
+func SyncPurgedEntities(ctx context.Context, entities []string) error {
+	if enforcer.Load() == nil {
+		logger.Errorf(ctx, "casbin: sync purged entities failed: enforcer not initialized")
+		return ErrEnforcerNotInitialized
+	}
+	defer notifyPeers(ctx)
+
+	return syncEnforcer(ctx, func(e *casbin.TransactionalEnforcer) error {
+		for _, entity := range entities {
+			for _, reference := range entityReferences[EntityTypeOf(entity)] {
+				if _, err := e.SelfRemoveFilteredPolicy(sectionOf(reference.ptype), reference.ptype, reference.fieldIndex, entity); err != nil {
+					logger.Warnf(ctx, "casbin: failed to sync purged entities, reloading: entity=%s, ptype=%s, error=%v", entity, reference.ptype, err)
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// PurgeExpiredPolicies hard-deletes p rules whose end time is before endedBefore from the
+// database and the enforcer. Policies with a window that cannot be parsed are left alone.
+// Returns how many policies were, or in dry-run mode would be, removed.
+func PurgeExpiredPolicies(ctx context.Context, endedBefore time.Time, dryRun bool) (int64, error) {
+	if enforcer.Load() == nil {
+		logger.Errorf(ctx, "casbin: purge expired policies failed: enforcer not initialized")
+		return 0, ErrEnforcerNotInitialized
+	}
+
+	// Policies are read a batch at a time so only the expired ones are held in memory
+	var batch []model.TblCasbinRule
+	expired := make([]model.TblCasbinRule, 0)
+	if err := database.DB.WithContext(ctx).Where("ptype = ?", "p").FindInBatches(&batch, purgeBatchSize, func(*gorm.DB, int) error {
+		for _, rule := range batch {
+			_, endTime, _, err := ParseWindow(rule.V3)
+			if err != nil {
+				logger.Warnf(ctx, "casbin: purge skipped policy with invalid window: id=%d, window=%s, error=%v", rule.Id, rule.V3, err)
+				continue
+			}
+			if endTime.Before(endedBefore) {
+				expired = append(expired, rule)
+			}
+		}
+		return nil
+	}).Error; err != nil {
+		logger.Errorf(ctx, "casbin: purge expired policies failed to query policies: error=%v", err)
+		return 0, fmt.Errorf("failed to query policies: %w", err)
+	}
+	if dryRun || len(expired) == 0 {
+		logger.Infof(ctx, "casbin: expired policies found: count=%d, ended_before=%s, dry_run=%v", len(expired), formatTime(endedBefore), dryRun)
+		return int64(len(expired)), nil
+	}
+
+	if err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(expired); start += purgeBatchSize {
+			end := min(start+purgeBatchSize, len(expired))
+			ids := make([]int64, 0, end-start)
+			for _, rule := range expired[start:end] {
+				ids = append(ids, rule.Id)
+			}
+			if err := tx.Where("id IN ?", ids).Delete(&model.TblCasbinRule{}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		logger.Errorf(ctx, "casbin: purge expired policies failed: count=%d, error=%v", len(expired), err)
+		return 0, fmt.Errorf("failed to purge expired policies: %w", err)
+	}
+	defer notifyPeers(ctx)
+
+	if err := syncEnforcer(ctx, func(e *casbin.TransactionalEnforcer) error {
+		for _, rule := range expired {
+			if _, err := e.SelfRemovePolicy("p", "p", ruleFields(rule)); err != nil {
+				logger.Warnf(ctx, "casbin: failed to sync purged policies, reloading: id=%d, error=%v", rule.Id, err)
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	logger.Infof(ctx, "casbin: expired policies purged: count=%d, ended_before=%s", len(expired), formatTime(endedBefore))
+	return int64(len(expired)), nil
+}
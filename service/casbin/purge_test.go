@@ -0,0 +1,49 @@
+package casbin
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/model"
+)
+
+func TestPurgeExpiredPoliciesAcrossBatches(t *testing.T) {
+	ctx := newTestContext()
+	tenantCode := newTenant(t)
+	userCode := AddPrefix(newSubject(t, tenantCode, model.SubjectTypeUser), EntityUser)
+
+	ended := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	expired := 2*purgeBatchSize + 1
+	rules := make([]model.TblCasbinRule, 0, expired+1)
+	for i := range expired {
+		rules = append(rules, model.TblCasbinRule{Ptype: "p", V0: userCode, V1: AddPrefix(fmt.Sprintf("expired-%d", i), EntityObject), V2: "purge", V3: FormatWindow(ended.Add(-time.Hour), ended, ""), V5: EffectAllow})
+	}
+	rules = append(rules, model.TblCasbinRule{Ptype: "p", V0: userCode, V1: AddPrefix("current", EntityObject), V2: "purge", V3: FormatWindow(ended, time.Now().Add(time.Hour), ""), V5: EffectAllow})
+	if err := database.DB.CreateInBatches(&rules, purgeBatchSize).Error; err != nil {
+		t.Fatalf("create policies: %v", err)
+	}
+	if err := LoadPolicy(ctx); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	endedBefore := ended.Add(time.Second)
+	if count, err := PurgeExpiredPolicies(ctx, endedBefore, true); err != nil || count != int64(expired) {
+		t.Fatalf("dry run = %d, %v, want %d", count, err, expired)
+	}
+	if count, err := PurgeExpiredPolicies(ctx, endedBefore, false); err != nil || count != int64(expired) {
+		t.Fatalf("purge = %d, %v, want %d", count, err, expired)
+	}
+
+	var left int64
+	if err := database.DB.Model(&model.TblCasbinRule{}).Where("ptype = ? AND v0 = ?", "p", userCode).Count(&left).Error; err != nil {
+		t.Fatalf("count policies: %v", err)
+	}
+	if left != 1 {
+		t.Fatalf("%d policies left, want the current one", left)
+	}
+	if policies, _ := enforcer.Load().GetFilteredPolicy(0, userCode); len(policies) != 1 {
+		t.Fatalf("enforcer holds %d policies, want the current one", len(policies))
+	}
+}
@@ -0,0 +1,316 @@
+package casbin
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/bootstrap/logger"
+	"ac/model"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of inconsistency found by Reconcile
+const (
+	IssueMissingEntity   = "missing_entity"   // The rule references a code that does not exist
+	IssueDeletedEntity   = "deleted_entity"   // The rule references a soft-deleted user, role or object
+	IssueMalformedPrefix = "malformed_prefix" // A field lacks the prefix its position requires
+	IssueInvalidWindow   = "invalid_window"   // The time window of a policy cannot be parsed
+)
+
+// Repairs applied by Reconcile in repair mode
+const (
+	RepairFixed    = "fixed"    // Malformed prefixes were rewritten in place
+	RepairArchived = "archived" // The rule was archived under its deleted entity and removed, so a restore brings it back
+	RepairDeleted  = "deleted"  // The rule was removed
+)
+
+// ReconcileIssue is one inconsistency between a stored rule and the entity tables.
+type ReconcileIssue struct {
+	RuleId int64
+	Ptype  string
+	Rule   []string
+	Field  string // Column holding the offending value, such as "v1"
+	Kind   string
+	Detail string
+	Repair string // Set in repair mode
+}
+
+// ReconcileReport lists the inconsistencies Reconcile found and, in repair mode, how many rules it changed.
+type ReconcileReport struct {
+	Repair   bool
+	Scanned  int64
+	Issues   []ReconcileIssue
+	Fixed    int64
+	Archived int64
+	Deleted  int64
+}
+
+// entityFields lists the entity types allowed in each field of each ptype.
+var entityFields = map[string]map[int][]string{
+	"p":                 {0: {EntityUser, EntityRole}, 1: {EntityObject, EntityTenant}},
+	GroupingUserRole:    {0: {EntityUser, EntityRole}, 1: {EntityRole}, 2: {EntityTenant}},
+	GroupingObjectGroup: {0: {EntityObject}, 1: {EntityObject, EntityTenant}},
+}
+
+// reconcileRule is a stored rule under inspection along with the outcome for it.
+type reconcileRule struct {
+	rule    model.TblCasbinRule
+	fields  [6]string // Fields with malformed prefixes fixed where the intended entity is known
+	issues  []ReconcileIssue
+	fixable bool   // Every issue is a malformed prefix that could be fixed
+	intact  bool   // The rule holds once fixed and its deleted entities restored, so archiving it loses nothing
+	deleted string // Deleted entity the rule should be archived under, if any
+}
+
+// Reconcile checks every stored rule against the user, role, object and tenant tables. It reports
+// rules pointing at missing or deleted codes, fields with a malformed prefix and unparsable windows.
+// In repair mode, fixable prefixes are rewritten, rules on deleted entities are archived the way
+// deleting the entity would have, and every other broken rule is removed; the enforcer is then reloaded.
+func Reconcile(ctx context.Context, repair bool) (*ReconcileReport, error) {
+	if enforcer.Load() == nil {
+		logger.Errorf(ctx, "casbin: reconcile failed: enforcer not initialized")
+		return nil, ErrEnforcerNotInitialized
+	}
+
+	entities, err := loadEntityStates(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: reconcile failed to load entities: error=%v", err)
+		return nil, err
+	}
+
+	var rules []model.TblCasbinRule
+	if err := database.DB.WithContext(ctx).Order("id").Find(&rules).Error; err != nil {
+		logger.Errorf(ctx, "casbin: reconcile failed to query rules: error=%v", err)
+		return nil, fmt.Errorf("failed to query rules: %w", err)
+	}
+
+	report := &ReconcileReport{Repair: repair, Scanned: int64(len(rules)), Issues: make([]ReconcileIssue, 0)}
+	broken := make([]*reconcileRule, 0)
+	for _, rule := range rules {
+		if checked := checkRule(rule, entities); len(checked.issues) > 0 {
+			broken = append(broken, checked)
+		}
+	}
+
+	if repair && len(broken) > 0 {
+		if err := repairRules(ctx, rules, broken, report); err != nil {
+			logger.Errorf(ctx, "casbin: reconcile repair failed: count=%d, error=%v", len(broken), err)
+			return nil, err
+		}
+		notifyPeers(ctx)
+		if err := LoadPolicy(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, checked := range broken {
+		report.Issues = append(report.Issues, checked.issues...)
+	}
+
+	logger.Infof(ctx, "casbin: reconcile finished: repair=%v, scanned=%d, issues=%d, fixed=%d, archived=%d, deleted=%d",
+		repair, report.Scanned, len(report.Issues), report.Fixed, report.Archived, report.Deleted)
+	return report, nil
+}
+
+// loadEntityStates maps every prefixed user, role, object and tenant code to whether it is deleted.
+func loadEntityStates(ctx context.Context) (map[string]bool, error) {
+	entities := make(map[string]bool)
+
+	var subjects []model.TblSubject
+	if err := database.DB.WithContext(ctx).Select("code", "type", "deleted").Find(&subjects).Error; err != nil {
+		return nil, fmt.Errorf("failed to query subjects: %w", err)
+	}
+	for _, subject := range subjects {
+		entityType := EntityUser
+		if subject.Type == model.SubjectTypeRole {
+			entityType = EntityRole
+		}
+		entities[AddPrefix(subject.Code, entityType)] = subject.Deleted == model.Deleted
+	}
+
+	var objects []model.TblObject
+	if err := database.DB.WithContext(ctx).Select("code", "deleted").Find(&objects).Error; err != nil {
+		return nil, fmt.Errorf("failed to query objects: %w", err)
+	}
+	for _, object := range objects {
+		entities[AddPrefix(object.Code, EntityObject)] = object.Deleted == model.Deleted
+	}
+
+	var tenants []model.TblTenant
+	if err := database.DB.WithContext(ctx).Select("code").Find(&tenants).Error; err != nil {
+		return nil, fmt.Errorf("failed to query tenants: %w", err)
+	}
+	for _, tenant := range tenants {
+		// Tenants are never deleted, only users, roles and objects are restorable
+		entities[AddPrefix(tenant.Code, EntityTenant)] = false
+	}
+	return entities, nil
+}
+
+// checkRule collects the issues of one rule.
+func checkRule(rule model.TblCasbinRule, entities map[string]bool) *reconcileRule {
+	checked := &reconcileRule{
+		rule:    rule,
+		fields:  [6]string{rule.V0, rule.V1, rule.V2, rule.V3, rule.V4, rule.V5},
+		fixable: true,
+		intact:  true,
+	}
+	addIssue := func(index int, kind, detail string) {
+		checked.issues = append(checked.issues, ReconcileIssue{
+			RuleId: rule.Id,
+			Ptype:  rule.Ptype,
+			Rule:   ruleFields(rule),
+			Field:  fmt.Sprintf("v%d", index),
+			Kind:   kind,
+			Detail: detail,
+		})
+		if kind != IssueMalformedPrefix {
+			checked.fixable = false
+		}
+		if kind != IssueMalformedPrefix && kind != IssueDeletedEntity {
+			checked.intact = false
+		}
+	}
+
+	for index := range checked.fields {
+		allowed, ok := entityFields[rule.Ptype][index]
+		if !ok {
+			continue
+		}
+		value := checked.fields[index]
+		entity := value
+		if !slices.Contains(allowed, EntityTypeOf(value)) {
+			entity = intendedEntity(value, allowed, entities)
+			if entity == "" {
+				addIssue(index, IssueMalformedPrefix, fmt.Sprintf("%q is not a %s", value, strings.Join(allowed, " or ")))
+				checked.fixable = false
+				checked.intact = false
+				continue
+			}
+			addIssue(index, IssueMalformedPrefix, fmt.Sprintf("%q should be %q", value, entity))
+			checked.fields[index] = entity
+		}
+
+		deleted, exists := entities[entity]
+		switch {
+		case !exists:
+			addIssue(index, IssueMissingEntity, fmt.Sprintf("%s does not exist", entity))
+		case deleted:
+			addIssue(index, IssueDeletedEntity, fmt.Sprintf("%s is deleted", entity))
+			if checked.deleted == "" {
+				checked.deleted = entity
+			}
+		}
+	}
+
+	if rule.Ptype == "p" {
+		if _, _, _, err := ParseWindow(rule.V3); err != nil {
+			addIssue(3, IssueInvalidWindow, err.Error())
+		}
+	}
+	return checked
+}
+
+// intendedEntity guesses the prefixed entity a malformed field meant, such as "u:<code>" for a
+// value written without the separator as "u<code>", a bare "<code>", or a code carrying the prefix
+// of another entity type. Returns an empty string unless exactly one allowed entity matches.
+func intendedEntity(value string, allowed []string, entities map[string]bool) string {
+	raw := value
+	if entityType := EntityTypeOf(value); entityType != "" {
+		raw = RemovePrefix(value, entityType)
+	}
+
+	match := ""
+	for _, entityType := range allowed {
+		prefix := AddPrefix("", entityType)
+		for _, code := range []string{raw, strings.TrimPrefix(raw, strings.TrimSuffix(prefix, PrefixSeparator))} {
+			candidate := AddPrefix(code, entityType)
+			if _, exists := entities[candidate]; !exists || candidate == match {
+				continue
+			}
+			if match != "" {
+				return ""
+			}
+			match = candidate
+		}
+	}
+	return match
+}
+
+// repairRules fixes, archives or removes broken rules in one transaction and records the
+// repair on each issue. rules are all stored rules, used to detect fixes that duplicate a rule.
+func repairRules(ctx context.Context, rules []model.TblCasbinRule, broken []*reconcileRule, report *ReconcileReport) error {
+	existing := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		existing[rule.Ptype+","+strings.Join(ruleFields(rule), ",")] = struct{}{}
+	}
+
+	now := time.Now()
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, checked := range broken {
+			fixed := model.TblCasbinRule{
+				Ptype: checked.rule.Ptype,
+				V0:    checked.fields[0],
+				V1:    checked.fields[1],
+				V2:    checked.fields[2],
+				V3:    checked.fields[3],
+				V4:    checked.fields[4],
+				V5:    checked.fields[5],
+			}
+			key := fixed.Ptype + "," + strings.Join(ruleFields(fixed), ",")
+			_, duplicate := existing[key]
+
+			repairment := RepairDeleted
+			switch {
+			case checked.fixable && !duplicate:
+				if err := tx.Model(&model.TblCasbinRule{}).Where("id = ?", checked.rule.Id).Updates(map[string]any{
+					"v0": fixed.V0, "v1": fixed.V1, "v2": fixed.V2, "v3": fixed.V3, "v4": fixed.V4, "v5": fixed.V5,
+				}).Error; err != nil {
+					return fmt.Errorf("failed to fix rule (id=%d): %w", checked.rule.Id, err)
+				}
+				existing[key] = struct{}{}
+				repairment = RepairFixed
+			case checked.deleted != "" && checked.intact:
+				archive := model.TblCasbinRuleArchive{
+					Entity:    checked.deleted,
+					Ptype:     fixed.Ptype,
+					V0:        fixed.V0,
+					V1:        fixed.V1,
+					V2:        fixed.V2,
+					V3:        fixed.V3,
+					V4:        fixed.V4,
+					V5:        fixed.V5,
+					CreatedAt: now,
+				}
+				if err := tx.Create(&archive).Error; err != nil {
+					return fmt.Errorf("failed to archive rule (id=%d): %w", checked.rule.Id, err)
+				}
+				repairment = RepairArchived
+			}
+
+			if repairment != RepairFixed {
+				if err := tx.Where("id = ?", checked.rule.Id).Delete(&model.TblCasbinRule{}).Error; err != nil {
+					return fmt.Errorf("failed to remove rule (id=%d): %w", checked.rule.Id, err)
+				}
+			}
+
+			switch repairment {
+			case RepairFixed:
+				report.Fixed++
+			case RepairArchived:
+				report.Archived++
+			default:
+				report.Deleted++
+			}
+			for i := range checked.issues {
+				checked.issues[i].Repair = repairment
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,247 @@
+package casbin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // Recurrences name IANA time zones, which minimal images may lack
+)
+
+// Separators of the policy window field. The adapter stores at most six policy fields,
+// so the begin time, end time and optional recurrence share one field:
+// "<begin>/<end>" or "<begin>/<end> <recurrence>".
+const (
+	WindowSeparator     = "/" // Joins begin and end time, as in ISO 8601 intervals
+	RecurrenceSeparator = " " // Precedes the recurrence
+)
+
+// FunctionWithinWindow is the custom matcher function evaluating policy windows: withinWindow(r.time, p.window).
+const FunctionWithinWindow = "withinWindow"
+
+// ErrInvalidRecurrence reports a recurrence that cannot be parsed.
+var ErrInvalidRecurrence = fmt.Errorf("recurrence must look like MON-FRI 09:00-18:00 Asia/Shanghai")
+
+// weekdayNames maps recurrence day names to weekdays.
+var weekdayNames = map[string]time.Weekday{
+	"SUN": time.Sunday,
+	"MON": time.Monday,
+	"TUE": time.Tuesday,
+	"WED": time.Wednesday,
+	"THU": time.Thursday,
+	"FRI": time.Friday,
+	"SAT": time.Saturday,
+}
+
+// Recurrence is a weekly repeating time window in a time zone.
+// Windows whose end is before their start run past midnight into the next day.
+type Recurrence struct {
+	Weekdays [7]bool        // Days on which the window opens, indexed by time.Weekday
+	Start    int            // Window start in minutes after local midnight, inclusive
+	End      int            // Window end in minutes after local midnight, exclusive
+	Location *time.Location // Time zone the days and hours are read in
+}
+
+// ParseRecurrence parses a recurrence of the form "<days> <HH:MM>-<HH:MM> [time zone]".
+// Days are a comma separated list of names or ranges such as MON-FRI or SAT,SUN, or * for
+// every day. The time zone is an IANA name and defaults to UTC.
+func ParseRecurrence(spec string) (*Recurrence, error) {
+	parts := strings.Fields(spec)
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, ErrInvalidRecurrence
+	}
+
+	recurrence := &Recurrence{Location: time.UTC}
+	if err := recurrence.parseWeekdays(parts[0]); err != nil {
+		return nil, err
+	}
+
+	startStr, endStr, ok := strings.Cut(parts[1], "-")
+	if !ok {
+		return nil, ErrInvalidRecurrence
+	}
+	var err error
+	if recurrence.Start, err = parseClock(startStr); err != nil {
+		return nil, err
+	}
+	if recurrence.End, err = parseClock(endStr); err != nil {
+		return nil, err
+	}
+	if recurrence.Start == recurrence.End {
+		return nil, fmt.Errorf("%w: empty hours %s", ErrInvalidRecurrence, parts[1])
+	}
+
+	if len(parts) == 3 {
+		location, err := time.LoadLocation(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("%w: unknown time zone %s", ErrInvalidRecurrence, parts[2])
+		}
+		recurrence.Location = location
+	}
+	return recurrence, nil
+}
+
+// parseWeekdays fills Weekdays from a day list.
+func (r *Recurrence) parseWeekdays(days string) error {
+	if days == "*" {
+		for i := range r.Weekdays {
+			r.Weekdays[i] = true
+		}
+		return nil
+	}
+
+	for _, item := range strings.Split(strings.ToUpper(days), ",") {
+		fromStr, toStr, isRange := strings.Cut(item, "-")
+		from, ok := weekdayNames[fromStr]
+		if !ok {
+			return fmt.Errorf("%w: unknown day %s", ErrInvalidRecurrence, fromStr)
+		}
+		to := from
+		if isRange {
+			if to, ok = weekdayNames[toStr]; !ok {
+				return fmt.Errorf("%w: unknown day %s", ErrInvalidRecurrence, toStr)
+			}
+		}
+		// Ranges may wrap around the week, as in FRI-MON
+		for day := from; ; day = (day + 1) % 7 {
+			r.Weekdays[day] = true
+			if day == to {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// parseClock converts HH:MM into minutes after midnight. 24:00 is accepted as a window end.
+func parseClock(clock string) (int, error) {
+	hourStr, minuteStr, ok := strings.Cut(clock, ":")
+	if !ok || len(hourStr) != 2 || len(minuteStr) != 2 {
+		return 0, fmt.Errorf("%w: invalid time %s", ErrInvalidRecurrence, clock)
+	}
+	hour, err1 := strconv.Atoi(hourStr)
+	minute, err2 := strconv.Atoi(minuteStr)
+	if err1 != nil || err2 != nil || hour < 0 || minute < 0 || minute > 59 || hour*60+minute > 24*60 {
+		return 0, fmt.Errorf("%w: invalid time %s", ErrInvalidRecurrence, clock)
+	}
+	return hour*60 + minute, nil
+}
+
+// Contains reports whether t falls inside one of the recurring windows.
+func (r *Recurrence) Contains(t time.Time) bool {
+	local := t.In(r.Location)
+	minute := local.Hour()*60 + local.Minute()
+	day := local.Weekday()
+
+	if r.Start < r.End {
+		return r.Weekdays[day] && minute >= r.Start && minute < r.End
+	}
+	// Overnight window: the evening part opens on a listed day, the morning part closes the day after
+	if minute >= r.Start {
+		return r.Weekdays[day]
+	}
+	return minute < r.End && r.Weekdays[(day+6)%7]
+}
+
+// nextChange returns the first instant after t at which one of the windows may open or close.
+// Every day counts, listed or not, so the result may be a day on which nothing changes.
+func (r *Recurrence) nextChange(t time.Time) time.Time {
+	local := t.In(r.Location)
+	var next time.Time
+	for offset := 0; offset <= 1; offset++ {
+		for _, minute := range []int{r.Start, r.End} {
+			candidate := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, minute, 0, 0, r.Location)
+			if candidate.After(t) && (next.IsZero() || candidate.Before(next)) {
+				next = candidate
+			}
+		}
+	}
+	return next
+}
+
+// NormalizeRecurrence trims a recurrence and collapses inner whitespace so equal
+// recurrences are stored identically. Returns an empty string for no recurrence.
+func NormalizeRecurrence(spec string) string {
+	return strings.Join(strings.Fields(spec), " ")
+}
+
+// FormatWindow encodes a policy window with begin and end time in RFC3339 UTC,
+// followed by the normalized recurrence when there is one.
+func FormatWindow(beginTime, endTime time.Time, recurrence string) string {
+	window := formatTime(beginTime) + WindowSeparator + formatTime(endTime)
+	if recurrence = NormalizeRecurrence(recurrence); recurrence != "" {
+		window += RecurrenceSeparator + recurrence
+	}
+	return window
+}
+
+// ParseWindow decodes a policy window written by FormatWindow.
+func ParseWindow(window string) (beginTime, endTime time.Time, recurrence string, err error) {
+	interval, recurrence, _ := strings.Cut(window, RecurrenceSeparator)
+	beginStr, endStr, ok := strings.Cut(interval, WindowSeparator)
+	if !ok {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("invalid time window %s", window)
+	}
+
+	beginTime, err = time.Parse(time.RFC3339, beginStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("failed to parse begin time %s: %w", beginStr, err)
+	}
+
+	endTime, err = time.Parse(time.RFC3339, endStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", fmt.Errorf("failed to parse end time %s: %w", endStr, err)
+	}
+	return beginTime, endTime, recurrence, nil
+}
+
+// withinWindow is the matcher function behind FunctionWithinWindow.
+// Both bounds are inclusive. Times are RFC3339 UTC, so they compare as strings;
+// the recurrence is only evaluated inside the bounds.
+func withinWindow(args ...interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		return false, fmt.Errorf("%s expects 2 arguments, got %d", FunctionWithinWindow, len(args))
+	}
+	timeStr, ok1 := args[0].(string)
+	window, ok2 := args[1].(string)
+	if !ok1 || !ok2 {
+		return false, fmt.Errorf("%s expects string arguments", FunctionWithinWindow)
+	}
+
+	interval, recurrence, _ := strings.Cut(window, RecurrenceSeparator)
+	beginStr, endStr, ok := strings.Cut(interval, WindowSeparator)
+	if !ok || timeStr < beginStr || timeStr > endStr {
+		return false, nil
+	}
+	if recurrence == "" {
+		return true, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, timeStr)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse request time %s: %w", timeStr, err)
+	}
+	return recurrenceContains(recurrence, t), nil
+}
+
+// parsedRecurrences caches recurrences by spec, since every enforce evaluates them.
+var parsedRecurrences sync.Map
+
+// recurrenceContains reports whether t falls inside the recurrence spec, caching parsed specs.
+// An empty spec always matches; one that cannot be parsed never does.
+func recurrenceContains(spec string, t time.Time) bool {
+	if spec == "" {
+		return true
+	}
+
+	cached, ok := parsedRecurrences.Load(spec)
+	if !ok {
+		recurrence, err := ParseRecurrence(spec)
+		if err != nil {
+			return false
+		}
+		cached, _ = parsedRecurrences.LoadOrStore(spec, recurrence)
+	}
+	return cached.(*Recurrence).Contains(t)
+}
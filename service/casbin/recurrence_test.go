@@ -0,0 +1,167 @@
+package casbin
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		spec     string
+		weekdays []time.Weekday
+		start    int
+		end      int
+		location string
+		wantErr  bool
+	}{
+		{spec: "MON-FRI 09:00-18:00", weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, start: 540, end: 1080, location: "UTC"},
+		{spec: "sat,sun 10:30-12:00 Asia/Shanghai", weekdays: []time.Weekday{time.Saturday, time.Sunday}, start: 630, end: 720, location: "Asia/Shanghai"},
+		{spec: "FRI-MON 22:00-06:00", weekdays: []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Monday}, start: 1320, end: 360, location: "UTC"},
+		{spec: "* 00:00-24:00", weekdays: []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}, start: 0, end: 1440, location: "UTC"},
+		{spec: "MON 09:00", wantErr: true},
+		{spec: "MON 09:00-18:00 UTC extra", wantErr: true},
+		{spec: "MOO 09:00-18:00", wantErr: true},
+		{spec: "MON-FUN 09:00-18:00", wantErr: true},
+		{spec: "MON 9:00-18:00", wantErr: true},
+		{spec: "MON 09:60-18:00", wantErr: true},
+		{spec: "MON 09:00-24:01", wantErr: true},
+		{spec: "MON 09:00-09:00", wantErr: true},
+		{spec: "MON 09:00-18:00 Mars/Olympus", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			recurrence, err := ParseRecurrence(tt.spec)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRecurrence) {
+					t.Fatalf("ParseRecurrence() error = %v, want %v", err, ErrInvalidRecurrence)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRecurrence() error = %v", err)
+			}
+
+			var weekdays [7]bool
+			for _, day := range tt.weekdays {
+				weekdays[day] = true
+			}
+			if recurrence.Weekdays != weekdays {
+				t.Errorf("Weekdays = %v, want %v", recurrence.Weekdays, weekdays)
+			}
+			if recurrence.Start != tt.start || recurrence.End != tt.end {
+				t.Errorf("hours = %d-%d, want %d-%d", recurrence.Start, recurrence.End, tt.start, tt.end)
+			}
+			if recurrence.Location.String() != tt.location {
+				t.Errorf("Location = %s, want %s", recurrence.Location, tt.location)
+			}
+		})
+	}
+}
+
+func TestRecurrenceContains(t *testing.T) {
+	// 2026-10-19 is a Monday
+	tests := []struct {
+		name string
+		spec string
+		at   string
+		want bool
+	}{
+		{"inside working hours", "MON-FRI 09:00-18:00", "2026-10-19T09:00:00Z", true},
+		{"end is exclusive", "MON-FRI 09:00-18:00", "2026-10-19T18:00:00Z", false},
+		{"before start", "MON-FRI 09:00-18:00", "2026-10-19T08:59:59Z", false},
+		{"unlisted day", "MON-FRI 09:00-18:00", "2026-10-24T10:00:00Z", false},
+		{"last listed day", "MON-FRI 09:00-18:00", "2026-10-23T17:59:00Z", true},
+
+		{"zone ahead moves the day forward", "MON 08:00-10:00 Asia/Shanghai", "2026-10-19T00:30:00Z", true},
+		{"zone ahead leaves the day behind", "MON 08:00-10:00 Asia/Shanghai", "2026-10-19T08:30:00Z", false},
+		{"zone ahead at sunday evening utc", "MON 00:00-02:00 Asia/Shanghai", "2026-10-18T16:30:00Z", true},
+		{"zone behind keeps the day back", "SUN 20:00-23:00 America/New_York", "2026-10-19T01:00:00Z", true},
+		{"zone behind outside hours", "SUN 20:00-23:00 America/New_York", "2026-10-19T03:30:00Z", false},
+
+		{"overnight evening part", "FRI 22:00-06:00", "2026-10-23T23:00:00Z", true},
+		{"overnight morning part on the next day", "FRI 22:00-06:00", "2026-10-24T05:59:00Z", true},
+		{"overnight morning part closes", "FRI 22:00-06:00", "2026-10-24T06:00:00Z", false},
+		{"overnight morning part needs the previous day listed", "FRI 22:00-06:00", "2026-10-23T05:00:00Z", false},
+		{"overnight evening part needs its day listed", "FRI 22:00-06:00", "2026-10-24T23:00:00Z", false},
+		{"overnight across the week", "SUN 23:00-01:00", "2026-10-19T00:30:00Z", true},
+		{"overnight in a zone", "MON 22:00-02:00 Asia/Shanghai", "2026-10-19T17:00:00Z", true},
+
+		{"whole day", "* 00:00-24:00", "2026-10-25T23:59:59Z", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recurrence, err := ParseRecurrence(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseRecurrence(%q) error = %v", tt.spec, err)
+			}
+			at, _ := time.Parse(time.RFC3339, tt.at)
+			if got := recurrence.Contains(at); got != tt.want {
+				t.Fatalf("Contains(%s) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceNextChange(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		from string
+		want string
+	}{
+		{"opens later today", "MON-FRI 09:00-18:00", "2026-10-19T07:00:00Z", "2026-10-19T09:00:00Z"},
+		{"closes later today", "MON-FRI 09:00-18:00", "2026-10-19T09:00:00Z", "2026-10-19T18:00:00Z"},
+		{"opens tomorrow", "MON-FRI 09:00-18:00", "2026-10-19T18:00:00Z", "2026-10-20T09:00:00Z"},
+		{"overnight closes in the morning", "FRI 22:00-06:00", "2026-10-23T23:00:00Z", "2026-10-24T06:00:00Z"},
+		{"in a zone", "MON 08:00-10:00 Asia/Shanghai", "2026-10-19T00:30:00Z", "2026-10-19T02:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recurrence, err := ParseRecurrence(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseRecurrence(%q) error = %v", tt.spec, err)
+			}
+			from, _ := time.Parse(time.RFC3339, tt.from)
+			want, _ := time.Parse(time.RFC3339, tt.want)
+			if got := recurrence.nextChange(from); !got.Equal(want) {
+				t.Fatalf("nextChange(%s) = %s, want %s", tt.from, got.UTC().Format(time.RFC3339), tt.want)
+			}
+		})
+	}
+}
+
+func TestWithinWindow(t *testing.T) {
+	window := FormatWindow(
+		time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 25, 23, 59, 59, 0, time.UTC),
+		"  MON-FRI   09:00-18:00  ",
+	)
+	if want := "2026-10-19T00:00:00Z/2026-10-25T23:59:59Z MON-FRI 09:00-18:00"; window != want {
+		t.Fatalf("FormatWindow() = %q, want %q", window, want)
+	}
+
+	tests := []struct {
+		at   string
+		want bool
+	}{
+		{"2026-10-18T10:00:00Z", false},
+		{"2026-10-19T10:00:00Z", true},
+		{"2026-10-19T20:00:00Z", false},
+		{"2026-10-24T10:00:00Z", false},
+		{"2026-10-26T10:00:00Z", false},
+	}
+	for _, tt := range tests {
+		got, err := withinWindow(tt.at, window)
+		if err != nil {
+			t.Fatalf("withinWindow(%s) error = %v", tt.at, err)
+		}
+		if got != tt.want {
+			t.Errorf("withinWindow(%s) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+
+	if got, _ := withinWindow("2026-10-19T10:00:00Z", "2026-10-19T00:00:00Z/2026-10-25T23:59:59Z MON 9-18"); got != false {
+		t.Errorf("withinWindow() with an invalid recurrence = %v, want false", got)
+	}
+}
@@ -0,0 +1,108 @@
+package casbin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ac/bootstrap/config"
+	"ac/bootstrap/logger"
+
+	casbin "github.com/casbin/casbin/v2"
+	casbinModel "github.com/casbin/casbin/v2/model"
+)
+
+// ReloadPolicy rebuilds the enforcer from the stored rules and entity statuses and swaps it in.
+// Enforce keeps using the previous enforcer until the swap, so checks neither wait for the
+// reload nor see a partly loaded policy. Returns how many rules were held before and after.
+func ReloadPolicy(ctx context.Context) (int, int, error) {
+	if enforcer.Load() == nil {
+		logger.Errorf(ctx, "casbin: reload policy failed: enforcer not initialized")
+		return 0, 0, ErrEnforcerNotInitialized
+	}
+
+	lazyPolicies.loadMu.Lock()
+	defer lazyPolicies.loadMu.Unlock()
+	policyMu.Lock()
+	defer policyMu.Unlock()
+
+	before := countRules(enforcer.Load())
+	logger.Infof(ctx, "casbin: reloading policies from database: rules=%d", before)
+
+	// When policies load per tenant, only the tenants in memory are read again
+	e, err := rebuildEnforcer(ctx, loadedTenants())
+	if err != nil {
+		logger.Errorf(ctx, "casbin: reload policy failed: error=%v", err)
+		return 0, 0, fmt.Errorf("failed to load policies: %w", err)
+	}
+	if err := loadDisabledEntities(); err != nil {
+		logger.Errorf(ctx, "casbin: load disabled entities failed: error=%v", err)
+		return 0, 0, err
+	}
+	enforcer.Store(e)
+	invalidateDecisions()
+
+	after := countRules(e)
+	logger.Infof(ctx, "casbin: policies reloaded: rules_before=%d, rules_after=%d", before, after)
+	return before, after, nil
+}
+
+// StartAutoReload reloads the policies every configured interval until ctx is done.
+// It does nothing unless a reload interval is configured.
+func StartAutoReload(ctx context.Context) {
+	interval, err := time.ParseDuration(config.Config().Policy.ReloadInterval)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: auto reload: invalid interval, interval=%s, error=%v", config.Config().Policy.ReloadInterval, err)
+		return
+	}
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, _, err := ReloadPolicy(ctx); err != nil {
+					logger.Errorf(ctx, "casbin: scheduled reload failed: error=%v", err)
+				}
+			}
+		}
+	}()
+	logger.Infof(ctx, "casbin: auto reload started: interval=%s", interval)
+}
+
+// syncEnforcer applies fn to the in-memory enforcer once no reload is swapping it, and
+// reloads everything when fn fails. Use it to mirror changes already stored in the database.
+func syncEnforcer(ctx context.Context, fn func(e *casbin.TransactionalEnforcer) error) error {
+	policyMu.RLock()
+	err := fn(enforcer.Load())
+	invalidateDecisions()
+	policyMu.RUnlock()
+
+	if err != nil {
+		return LoadPolicy(ctx)
+	}
+	return nil
+}
+
+// countRules returns how many policy and grouping rules e holds.
+func countRules(e *casbin.TransactionalEnforcer) int {
+	return countPolicies(e.GetModel())
+}
+
+// countPolicies returns how many policy and grouping rules m holds.
+func countPolicies(m casbinModel.Model) int {
+	count := 0
+	for _, sec := range []string{"p", "g"} {
+		for _, assertion := range m[sec] {
+			count += len(assertion.Policy)
+		}
+	}
+	return count
+}
@@ -0,0 +1,329 @@
+package casbin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/bootstrap/logger"
+	"ac/model"
+
+	casbin "github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// LiveRules stands for the rules currently stored, in place of a snapshot id.
+const LiveRules int64 = 0
+
+// ErrSnapshotNotFound is returned for a snapshot id that does not exist.
+var ErrSnapshotNotFound = fmt.Errorf("snapshot not found")
+
+// SnapshotRule is a rule held by a snapshot or by the live policy.
+type SnapshotRule struct {
+	Ptype string
+	Rule  []string // Policy fields, without trailing empty ones
+}
+
+// EntityChange lists the rules referencing one user, role or object that differ between two rule sets.
+type EntityChange struct {
+	Code    string // Code without Casbin prefix
+	Added   []SnapshotRule
+	Removed []SnapshotRule
+}
+
+// SnapshotDiff describes what changes from one rule set to another, as a whole and per
+// user, role and object. A rule linking two entities is listed under both.
+type SnapshotDiff struct {
+	Added   []SnapshotRule // Rules only the target holds
+	Removed []SnapshotRule // Rules only the source holds
+	Users   []EntityChange
+	Roles   []EntityChange
+	Objects []EntityChange
+}
+
+// SnapshotRollback reports what a rollback changed.
+type SnapshotRollback struct {
+	Diff    *SnapshotDiff  // Changes applied, from the live rules to the snapshot
+	Skipped []SnapshotRule // Snapshot rules not re-created because a user, role or object they reference is gone
+}
+
+// CreateSnapshot copies every stored rule into a new snapshot labelled label.
+func CreateSnapshot(ctx context.Context, label, createdBy string) (*model.TblPolicySnapshot, error) {
+	snapshot := &model.TblPolicySnapshot{
+		Label:     label,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+	if err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(snapshot).Error; err != nil {
+			return fmt.Errorf("failed to create snapshot: %w", err)
+		}
+		result := tx.Exec("INSERT INTO "+model.TableNameTblPolicySnapshotRule+" (snapshot_id, ptype, v0, v1, v2, v3, v4, v5) "+
+			"SELECT ?, ptype, v0, v1, v2, v3, v4, v5 FROM "+model.TableNameTblCasbinRule, snapshot.Id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to copy rules: %w", result.Error)
+		}
+		snapshot.RuleCount = result.RowsAffected
+		return tx.Model(snapshot).Update("rule_count", snapshot.RuleCount).Error
+	}); err != nil {
+		logger.Errorf(ctx, "casbin: create snapshot failed: label=%s, error=%v", label, err)
+		return nil, err
+	}
+
+	logger.Infof(ctx, "casbin: snapshot created: id=%d, label=%s, rules=%d", snapshot.Id, label, snapshot.RuleCount)
+	return snapshot, nil
+}
+
+// DiffSnapshots compares the rules of snapshot fromId with those of snapshot toId.
+// Either id may be LiveRules to compare with the rules currently stored.
+func DiffSnapshots(ctx context.Context, fromId, toId int64) (*SnapshotDiff, error) {
+	from, err := snapshotRules(ctx, fromId)
+	if err != nil {
+		return nil, err
+	}
+	to, err := snapshotRules(ctx, toId)
+	if err != nil {
+		return nil, err
+	}
+	return diffRules(from, to), nil
+}
+
+// RollbackToSnapshot makes the stored and in-memory rules match snapshot snapshotId in a
+// single enforcer transaction. Snapshot rules referencing users, roles or objects deleted
+// since are left out, so the rollback never brings back rules for entities that are gone.
+func RollbackToSnapshot(ctx *gin.Context, snapshotId int64) (*SnapshotRollback, error) {
+	if enforcer.Load() == nil {
+		logger.Errorf(ctx, "casbin: rollback to snapshot failed: enforcer not initialized")
+		return nil, ErrEnforcerNotInitialized
+	}
+	if snapshotId == LiveRules {
+		return nil, ErrSnapshotNotFound
+	}
+
+	target, err := snapshotRules(ctx, snapshotId)
+	if err != nil {
+		return nil, err
+	}
+	live, err := snapshotRules(ctx, LiveRules)
+	if err != nil {
+		return nil, err
+	}
+
+	rollback := &SnapshotRollback{}
+	candidates := make([]model.TblCasbinRule, 0, len(target))
+	for _, rule := range target {
+		candidates = append(candidates, rule.stored())
+	}
+	liveEntitySet, err := liveEntities(ctx, database.DB, candidates, "")
+	if err != nil {
+		logger.Errorf(ctx, "casbin: rollback to snapshot failed to query live entities: snapshot_id=%d, error=%v", snapshotId, err)
+		return nil, err
+	}
+	kept := make([]SnapshotRule, 0, len(target))
+	for _, rule := range target {
+		if referencesLive(rule.stored(), liveEntitySet) {
+			kept = append(kept, rule)
+		} else {
+			rollback.Skipped = append(rollback.Skipped, rule)
+		}
+	}
+	rollback.Diff = diffRules(live, kept)
+
+	if len(rollback.Diff.Added) == 0 && len(rollback.Diff.Removed) == 0 {
+		logger.Infof(ctx, "casbin: rollback to snapshot changed nothing: snapshot_id=%d, skipped=%d", snapshotId, len(rollback.Skipped))
+		return rollback, nil
+	}
+
+	// When policies load per tenant, the enforcer must hold every rule the rollback touches
+	entities := make([]string, 0)
+	for _, rules := range [][]SnapshotRule{rollback.Diff.Added, rollback.Diff.Removed} {
+		for _, rule := range rules {
+			for _, field := range rule.Rule {
+				if EntityTypeOf(field) != "" {
+					entities = append(entities, field)
+				}
+			}
+		}
+	}
+	release, err := ensureEntityTenantsLoaded(ctx, entities...)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: rollback to snapshot failed to load tenant policies: snapshot_id=%d, error=%v", snapshotId, err)
+		return nil, err
+	}
+	defer release()
+
+	if err := withTransaction(ctx, func(tx *casbin.Transaction) error {
+		// The enforcer must agree with the database, or the transaction would skip changes
+		m, err := tx.GetBufferedModel()
+		if err != nil {
+			return err
+		}
+		for _, rule := range rollback.Diff.Removed {
+			if has, err := m.HasPolicy(sectionOf(rule.Ptype), rule.Ptype, rule.Rule); err != nil || !has {
+				return fmt.Errorf("rule %s %v is stored but not loaded, reload policies and retry", rule.Ptype, rule.Rule)
+			}
+		}
+		for _, rule := range rollback.Diff.Added {
+			if has, err := m.HasPolicy(sectionOf(rule.Ptype), rule.Ptype, rule.Rule); err != nil || has {
+				return fmt.Errorf("rule %s %v is loaded but not stored, reload policies and retry", rule.Ptype, rule.Rule)
+			}
+		}
+
+		removed := make(map[string][][]string)
+		for _, rule := range rollback.Diff.Removed {
+			if sectionOf(rule.Ptype) == "g" {
+				if _, err := tx.RemoveNamedGroupingPolicy(rule.Ptype, rule.Rule); err != nil {
+					return fmt.Errorf("failed to remove rule %s %v: %w", rule.Ptype, rule.Rule, err)
+				}
+				continue
+			}
+			removed[rule.Ptype] = append(removed[rule.Ptype], rule.Rule)
+		}
+		for ptype, rules := range removed {
+			if _, err := tx.RemoveNamedPolicies(ptype, rules); err != nil {
+				return fmt.Errorf("failed to remove %s rules: %w", ptype, err)
+			}
+		}
+
+		added := make(map[string][][]string)
+		for _, rule := range rollback.Diff.Added {
+			if sectionOf(rule.Ptype) == "g" {
+				if _, err := tx.AddNamedGroupingPolicy(rule.Ptype, rule.Rule); err != nil {
+					return fmt.Errorf("failed to add rule %s %v: %w", rule.Ptype, rule.Rule, err)
+				}
+				continue
+			}
+			added[rule.Ptype] = append(added[rule.Ptype], rule.Rule)
+		}
+		for ptype, rules := range added {
+			if _, err := tx.AddNamedPolicies(ptype, rules); err != nil {
+				return fmt.Errorf("failed to add %s rules: %w", ptype, err)
+			}
+		}
+		return nil
+	}); err != nil {
+		logger.Errorf(ctx, "casbin: rollback to snapshot failed: snapshot_id=%d, error=%v", snapshotId, err)
+		return nil, fmt.Errorf("rollback to snapshot failed (snapshot_id=%d): %w", snapshotId, err)
+	}
+
+	logger.Infof(ctx, "casbin: rolled back to snapshot: snapshot_id=%d, added=%d, removed=%d, skipped=%d",
+		snapshotId, len(rollback.Diff.Added), len(rollback.Diff.Removed), len(rollback.Skipped))
+	return rollback, nil
+}
+
+// snapshotRules reads the rules of a snapshot, or the stored rules for LiveRules, in id order.
+func snapshotRules(ctx context.Context, snapshotId int64) ([]SnapshotRule, error) {
+	var stored []model.TblCasbinRule
+	if snapshotId == LiveRules {
+		if err := database.DB.WithContext(ctx).Order("id").Find(&stored).Error; err != nil {
+			return nil, fmt.Errorf("failed to query rules: %w", err)
+		}
+	} else {
+		var count int64
+		if err := database.DB.WithContext(ctx).Model(&model.TblPolicySnapshot{}).Where("id = ?", snapshotId).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to query snapshot %d: %w", snapshotId, err)
+		}
+		if count == 0 {
+			return nil, ErrSnapshotNotFound
+		}
+
+		var rows []model.TblPolicySnapshotRule
+		if err := database.DB.WithContext(ctx).Where("snapshot_id = ?", snapshotId).Order("id").Find(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to query rules of snapshot %d: %w", snapshotId, err)
+		}
+		stored = make([]model.TblCasbinRule, len(rows))
+		for i, row := range rows {
+			stored[i] = model.TblCasbinRule{Ptype: row.Ptype, V0: row.V0, V1: row.V1, V2: row.V2, V3: row.V3, V4: row.V4, V5: row.V5}
+		}
+	}
+
+	rules := make([]SnapshotRule, len(stored))
+	for i, rule := range stored {
+		rules[i] = SnapshotRule{Ptype: rule.Ptype, Rule: ruleFields(rule)}
+	}
+	return rules, nil
+}
+
+// diffRules compares two rule sets and groups the differences by the users, roles and objects they reference.
+func diffRules(from, to []SnapshotRule) *SnapshotDiff {
+	fromKeys := make(map[string]struct{}, len(from))
+	for _, rule := range from {
+		fromKeys[rule.key()] = struct{}{}
+	}
+	toKeys := make(map[string]struct{}, len(to))
+	for _, rule := range to {
+		toKeys[rule.key()] = struct{}{}
+	}
+
+	diff := &SnapshotDiff{Added: make([]SnapshotRule, 0), Removed: make([]SnapshotRule, 0)}
+	changes := make(map[string]*EntityChange)
+	change := func(entity string) *EntityChange {
+		if _, ok := changes[entity]; !ok {
+			changes[entity] = &EntityChange{Code: RemovePrefix(entity, EntityTypeOf(entity)), Added: make([]SnapshotRule, 0), Removed: make([]SnapshotRule, 0)}
+		}
+		return changes[entity]
+	}
+	for _, rule := range to {
+		if _, ok := fromKeys[rule.key()]; !ok {
+			diff.Added = append(diff.Added, rule)
+			for _, entity := range rule.entities() {
+				change(entity).Added = append(change(entity).Added, rule)
+			}
+		}
+	}
+	for _, rule := range from {
+		if _, ok := toKeys[rule.key()]; !ok {
+			diff.Removed = append(diff.Removed, rule)
+			for _, entity := range rule.entities() {
+				change(entity).Removed = append(change(entity).Removed, rule)
+			}
+		}
+	}
+
+	entities := make([]string, 0, len(changes))
+	for entity := range changes {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+
+	diff.Users, diff.Roles, diff.Objects = make([]EntityChange, 0), make([]EntityChange, 0), make([]EntityChange, 0)
+	for _, entity := range entities {
+		switch EntityTypeOf(entity) {
+		case EntityUser:
+			diff.Users = append(diff.Users, *changes[entity])
+		case EntityRole:
+			diff.Roles = append(diff.Roles, *changes[entity])
+		case EntityObject:
+			diff.Objects = append(diff.Objects, *changes[entity])
+		}
+	}
+	return diff
+}
+
+// key identifies a rule within a rule set.
+func (r SnapshotRule) key() string {
+	return r.Ptype + "," + strings.Join(r.Rule, ",")
+}
+
+// entities returns the users, roles and objects a rule references.
+func (r SnapshotRule) entities() []string {
+	entities := make([]string, 0, 2)
+	for _, field := range r.Rule[:min(len(r.Rule), 2)] {
+		switch EntityTypeOf(field) {
+		case EntityUser, EntityRole, EntityObject:
+			entities = append(entities, field)
+		}
+	}
+	return entities
+}
+
+// stored converts a rule back into its stored form.
+func (r SnapshotRule) stored() model.TblCasbinRule {
+	fields := make([]string, 6)
+	copy(fields, r.Rule)
+	return model.TblCasbinRule{Ptype: r.Ptype, V0: fields[0], V1: fields[1], V2: fields[2], V3: fields[3], V4: fields[4], V5: fields[5]}
+}
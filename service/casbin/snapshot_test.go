@@ -0,0 +1,252 @@
+package casbin
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/model"
+
+	"gorm.io/gorm"
+)
+
+func TestDiffRules(t *testing.T) {
+	window := "2026-01-01T00:00:00Z/2027-01-01T00:00:00Z"
+	kept := SnapshotRule{Ptype: "p", Rule: []string{"u:alice", "o:doc", "read", window, "", EffectAllow}}
+	granted := SnapshotRule{Ptype: "p", Rule: []string{"r:editor", "o:doc", "write", window, "", EffectAllow}}
+	revoked := SnapshotRule{Ptype: "g", Rule: []string{"u:alice", "r:editor", "t:one"}}
+	tenantWide := SnapshotRule{Ptype: "p", Rule: []string{"r:admin", "t:one", "read", window, "", EffectAllow}}
+
+	diff := diffRules([]SnapshotRule{kept, revoked}, []SnapshotRule{kept, granted, tenantWide})
+
+	if want := []SnapshotRule{granted, tenantWide}; !reflect.DeepEqual(diff.Added, want) {
+		t.Errorf("Added = %v, want %v", diff.Added, want)
+	}
+	if want := []SnapshotRule{revoked}; !reflect.DeepEqual(diff.Removed, want) {
+		t.Errorf("Removed = %v, want %v", diff.Removed, want)
+	}
+
+	wantUsers := []EntityChange{{Code: "alice", Added: []SnapshotRule{}, Removed: []SnapshotRule{revoked}}}
+	if !reflect.DeepEqual(diff.Users, wantUsers) {
+		t.Errorf("Users = %+v, want %+v", diff.Users, wantUsers)
+	}
+	// The revoked grouping links alice and editor, so it is listed under both
+	wantRoles := []EntityChange{
+		{Code: "admin", Added: []SnapshotRule{tenantWide}, Removed: []SnapshotRule{}},
+		{Code: "editor", Added: []SnapshotRule{granted}, Removed: []SnapshotRule{revoked}},
+	}
+	if !reflect.DeepEqual(diff.Roles, wantRoles) {
+		t.Errorf("Roles = %+v, want %+v", diff.Roles, wantRoles)
+	}
+	wantObjects := []EntityChange{{Code: "doc", Added: []SnapshotRule{granted}, Removed: []SnapshotRule{}}}
+	if !reflect.DeepEqual(diff.Objects, wantObjects) {
+		t.Errorf("Objects = %+v, want %+v", diff.Objects, wantObjects)
+	}
+
+	if diff := diffRules([]SnapshotRule{kept}, []SnapshotRule{kept}); len(diff.Added) != 0 || len(diff.Removed) != 0 || len(diff.Users) != 0 {
+		t.Errorf("diff of equal rule sets = %+v, want empty", diff)
+	}
+}
+
+// snapshotFixture is a user holding a role of a tenant, with policies on one of its objects.
+type snapshotFixture struct {
+	tenantCode string
+	userCode   string
+	roleCode   string
+	objectCode string
+}
+
+// newSnapshotFixture stores a user granted read directly and write through a role.
+func newSnapshotFixture(t *testing.T) snapshotFixture {
+	t.Helper()
+
+	ctx := newTestContext()
+	fixture := snapshotFixture{tenantCode: newTenant(t)}
+	fixture.userCode = newSubject(t, fixture.tenantCode, model.SubjectTypeUser)
+	fixture.roleCode = newSubject(t, fixture.tenantCode, model.SubjectTypeRole)
+	fixture.objectCode = newObject(t, ctx, fixture.tenantCode, "")
+
+	if err := AssignRolesToUser(ctx, fixture.tenantCode, fixture.userCode, []string{fixture.roleCode}); err != nil {
+		t.Fatalf("assign role: %v", err)
+	}
+	if err := AssignPoliciesToUser(ctx, fixture.userCode, []Policy{activePolicy(fixture.objectCode, "read")}); err != nil {
+		t.Fatalf("assign user policy: %v", err)
+	}
+	if err := AssignPoliciesToRole(ctx, fixture.roleCode, []Policy{activePolicy(fixture.objectCode, "write")}); err != nil {
+		t.Fatalf("assign role policy: %v", err)
+	}
+	return fixture
+}
+
+// allowed reports which of the actions the user may perform on the object.
+func (fixture snapshotFixture) allowed(t *testing.T, actions ...string) map[string]bool {
+	t.Helper()
+
+	result := make(map[string]bool, len(actions))
+	for _, action := range actions {
+		allowed, _, err := CheckPermission(newTestContext(), fixture.tenantCode, fixture.userCode, fixture.objectCode, action, nil, time.Now())
+		if err != nil {
+			t.Fatalf("check %s: %v", action, err)
+		}
+		result[action] = allowed
+	}
+	return result
+}
+
+// liveRuleKeys returns the keys of the stored rules.
+func liveRuleKeys(t *testing.T) map[string]struct{} {
+	t.Helper()
+
+	rules, err := snapshotRules(newTestContext(), LiveRules)
+	if err != nil {
+		t.Fatalf("query live rules: %v", err)
+	}
+	keys := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		keys[rule.key()] = struct{}{}
+	}
+	return keys
+}
+
+func TestRollbackToSnapshot(t *testing.T) {
+	ctx := newTestContext()
+	fixture := newSnapshotFixture(t)
+
+	snapshot, err := CreateSnapshot(ctx, "before", "test")
+	if err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+
+	// Revoke the role, grant delete and add a policy for a user deleted afterwards
+	if err := RemoveRolesFromUser(ctx, fixture.tenantCode, fixture.userCode, []string{fixture.roleCode}); err != nil {
+		t.Fatalf("remove role: %v", err)
+	}
+	if err := AssignPoliciesToUser(ctx, fixture.userCode, []Policy{activePolicy(fixture.objectCode, "delete")}); err != nil {
+		t.Fatalf("assign policy: %v", err)
+	}
+	if got := fixture.allowed(t, "read", "write", "delete"); !reflect.DeepEqual(got, map[string]bool{"read": true, "write": false, "delete": true}) {
+		t.Fatalf("allowed before rollback = %v", got)
+	}
+
+	diff, err := DiffSnapshots(ctx, snapshot.Id, LiveRules)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	var userChange *EntityChange
+	for i := range diff.Users {
+		if diff.Users[i].Code == fixture.userCode {
+			userChange = &diff.Users[i]
+		}
+	}
+	if userChange == nil || len(userChange.Added) != 1 || len(userChange.Removed) != 1 {
+		t.Fatalf("diff of the user = %+v, want the delete policy added and the role removed", userChange)
+	}
+
+	rollback, err := RollbackToSnapshot(ctx, snapshot.Id)
+	if err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if got := fixture.allowed(t, "read", "write", "delete"); !reflect.DeepEqual(got, map[string]bool{"read": true, "write": true, "delete": false}) {
+		t.Fatalf("allowed after rollback = %v", got)
+	}
+	if len(rollback.Diff.Added) == 0 || len(rollback.Diff.Removed) == 0 {
+		t.Fatalf("rollback diff = %+v, want rules added and removed", rollback.Diff)
+	}
+
+	// The live rules now match the snapshot, up to rules of entities deleted since it was taken
+	if diff, err := DiffSnapshots(ctx, snapshot.Id, LiveRules); err != nil || len(diff.Added) != 0 {
+		t.Fatalf("diff after rollback = %+v, %v, want no rules added since the snapshot", diff, err)
+	}
+
+	// Rules of a user deleted since the snapshot are skipped
+	if err := database.DB.Model(&model.TblSubject{}).Where("code = ?", fixture.userCode).Update("deleted", model.Deleted).Error; err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+	t.Cleanup(func() {
+		database.DB.Model(&model.TblSubject{}).Where("code = ?", fixture.userCode).Update("deleted", model.NotDeleted)
+	})
+	if err := RemovePoliciesFromUser(ctx, fixture.userCode, []Policy{activePolicy(fixture.objectCode, "read")}); err != nil {
+		t.Fatalf("remove policy: %v", err)
+	}
+	rollback, err = RollbackToSnapshot(ctx, snapshot.Id)
+	if err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	skipped := 0
+	for _, rule := range rollback.Skipped {
+		if rule.Rule[0] == AddPrefix(fixture.userCode, EntityUser) {
+			skipped++
+		}
+	}
+	if skipped != 2 {
+		t.Fatalf("skipped %d rules of the deleted user, want its role and read policy: %v", skipped, rollback.Skipped)
+	}
+}
+
+func TestRollbackToSnapshotFailingPartway(t *testing.T) {
+	ctx := newTestContext()
+	fixture := newSnapshotFixture(t)
+
+	snapshot, err := CreateSnapshot(ctx, "before", "test")
+	if err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	if err := RemoveRolesFromUser(ctx, fixture.tenantCode, fixture.userCode, []string{fixture.roleCode}); err != nil {
+		t.Fatalf("remove role: %v", err)
+	}
+	if err := AssignPoliciesToUser(ctx, fixture.userCode, []Policy{activePolicy(fixture.objectCode, "delete")}); err != nil {
+		t.Fatalf("assign policy: %v", err)
+	}
+	before := liveRuleKeys(t)
+	allowedBefore := fixture.allowed(t, "read", "write", "delete")
+
+	// Rules are removed before they are added, so failing every insert fails the rollback partway
+	errInjected := errors.New("injected insert failure")
+	if err := database.DB.Callback().Create().Before("gorm:create").Register("test:fail_rule_insert", func(db *gorm.DB) {
+		if db.Statement.Table == model.TableNameTblCasbinRule {
+			db.AddError(errInjected)
+		}
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	_, err = RollbackToSnapshot(ctx, snapshot.Id)
+	if err := database.DB.Callback().Create().Remove("test:fail_rule_insert"); err != nil {
+		t.Fatalf("remove callback: %v", err)
+	}
+	if !errors.Is(err, errInjected) {
+		t.Fatalf("rollback error = %v, want %v", err, errInjected)
+	}
+
+	if after := liveRuleKeys(t); !reflect.DeepEqual(after, before) {
+		t.Fatalf("stored rules changed by a failed rollback")
+	}
+	if got := fixture.allowed(t, "read", "write", "delete"); !reflect.DeepEqual(got, allowedBefore) {
+		t.Fatalf("allowed after a failed rollback = %v, want %v", got, allowedBefore)
+	}
+
+	// A rule stored but not loaded fails the rollback before anything is written
+	stray := model.TblCasbinRule{Ptype: "p", V0: AddPrefix(fixture.userCode, EntityUser), V1: AddPrefix(fixture.objectCode, EntityObject), V2: "stray", V3: FormatWindow(time.Now(), time.Now().Add(time.Hour), ""), V5: EffectAllow}
+	if err := database.DB.Create(&stray).Error; err != nil {
+		t.Fatalf("store rule: %v", err)
+	}
+	before = liveRuleKeys(t)
+	if _, err := RollbackToSnapshot(ctx, snapshot.Id); err == nil {
+		t.Fatalf("rollback with a rule stored but not loaded succeeded, want an error")
+	}
+	if after := liveRuleKeys(t); !reflect.DeepEqual(after, before) {
+		t.Fatalf("stored rules changed by a rollback refused for a stale enforcer")
+	}
+
+	// After a reload the rollback goes through
+	if err := LoadPolicy(ctx); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, err := RollbackToSnapshot(ctx, snapshot.Id); err != nil {
+		t.Fatalf("rollback after reload: %v", err)
+	}
+	if got := fixture.allowed(t, "read", "write", "delete", "stray"); !reflect.DeepEqual(got, map[string]bool{"read": true, "write": true, "delete": false, "stray": false}) {
+		t.Fatalf("allowed after rollback = %v", got)
+	}
+}
@@ -0,0 +1,220 @@
+package casbin
+
+import (
+	"fmt"
+	"sync"
+
+	"ac/bootstrap/database"
+	"ac/bootstrap/logger"
+	"ac/model"
+
+	"github.com/casbin/casbin/v2/rbac"
+	"github.com/gin-gonic/gin"
+)
+
+// Matcher functions honoring the status of subjects and objects:
+// subjectEnabled(r.sub, p.sub, r.dom) and objectEnabled(r.obj, p.obj).
+const (
+	FunctionSubjectEnabled = "subjectEnabled"
+	FunctionObjectEnabled  = "objectEnabled"
+)
+
+// Deny reasons reported by checks when a disabled entity is what blocks access
+const (
+	DenyReasonUserDisabled   = "denied: user disabled"
+	DenyReasonRoleDisabled   = "denied: role disabled"
+	DenyReasonObjectDisabled = "denied: object disabled"
+)
+
+// disabledEntities holds the prefixed identifiers of disabled users, roles and objects.
+// Grants stay stored while an entity is disabled; enforcement skips them instead.
+var disabledEntities = struct {
+	sync.RWMutex
+	codes map[string]struct{}
+}{codes: make(map[string]struct{})}
+
+// loadDisabledEntities reads every disabled subject and object from the database.
+func loadDisabledEntities() error {
+	var subjects []model.TblSubject
+	if err := database.DB.Select("code", "type").
+		Where("status = ? AND deleted = ?", model.StatusDisabled.Int64(), model.NotDeleted).
+		Find(&subjects).Error; err != nil {
+		return fmt.Errorf("failed to query disabled subjects: %w", err)
+	}
+
+	var objects []model.TblObject
+	if err := database.DB.Select("code").
+		Where("status = ? AND deleted = ?", model.StatusDisabled.Int64(), model.NotDeleted).
+		Find(&objects).Error; err != nil {
+		return fmt.Errorf("failed to query disabled objects: %w", err)
+	}
+
+	codes := make(map[string]struct{}, len(subjects)+len(objects))
+	for _, subject := range subjects {
+		entityType := EntityUser
+		if subject.Type == model.SubjectTypeRole {
+			entityType = EntityRole
+		}
+		codes[AddPrefix(subject.Code, entityType)] = struct{}{}
+	}
+	for _, object := range objects {
+		codes[AddPrefix(object.Code, EntityObject)] = struct{}{}
+	}
+
+	disabledEntities.Lock()
+	disabledEntities.codes = codes
+	disabledEntities.Unlock()
+	return nil
+}
+
+// SetEntityStatus records the status of a user, role or object for enforcement
+// and tells the other replicas. Call it after the status change has been stored.
+func SetEntityStatus(ctx *gin.Context, code, entityType string, status model.StatusFlag) error {
+	if err := validateCode(code, entityType); err != nil {
+		logger.Errorf(ctx, "casbin: set entity status validation failed: entity_type=%s, code=%s, error=%v", entityType, code, err)
+		return err
+	}
+
+	entity := AddPrefix(code, entityType)
+	disabledEntities.Lock()
+	if status == model.StatusDisabled {
+		disabledEntities.codes[entity] = struct{}{}
+	} else {
+		delete(disabledEntities.codes, entity)
+	}
+	disabledEntities.Unlock()
+	invalidateDecisions()
+	notifyPeers(ctx)
+
+	logger.Infof(ctx, "casbin: entity status set: entity=%s, status=%s", entity, status)
+	return nil
+}
+
+// isDisabled reports whether a prefixed entity is disabled.
+func isDisabled(entity string) bool {
+	disabledEntities.RLock()
+	defer disabledEntities.RUnlock()
+	_, ok := disabledEntities.codes[entity]
+	return ok
+}
+
+// hasDisabled reports whether any entity is disabled, so matchers can skip path checks.
+func hasDisabled() bool {
+	disabledEntities.RLock()
+	defer disabledEntities.RUnlock()
+	return len(disabledEntities.codes) > 0
+}
+
+// subjectEnabled is the matcher function behind FunctionSubjectEnabled.
+// It holds when the request subject reaches the policy subject through enabled roles only.
+func subjectEnabled(args ...interface{}) (interface{}, error) {
+	if len(args) != 3 {
+		return false, fmt.Errorf("%s expects 3 arguments, got %d", FunctionSubjectEnabled, len(args))
+	}
+	subject, ok1 := args[0].(string)
+	target, ok2 := args[1].(string)
+	domain, ok3 := args[2].(string)
+	if !ok1 || !ok2 || !ok3 {
+		return false, fmt.Errorf("%s expects string arguments", FunctionSubjectEnabled)
+	}
+	if !hasDisabled() {
+		return true, nil
+	}
+	return reachableWhileEnabled(enforcer.Load().GetRoleManager(), subject, target, domain), nil
+}
+
+// objectEnabled is the matcher function behind FunctionObjectEnabled.
+// It holds when the request object reaches the policy object through enabled objects only.
+func objectEnabled(args ...interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		return false, fmt.Errorf("%s expects 2 arguments, got %d", FunctionObjectEnabled, len(args))
+	}
+	object, ok1 := args[0].(string)
+	target, ok2 := args[1].(string)
+	if !ok1 || !ok2 {
+		return false, fmt.Errorf("%s expects string arguments", FunctionObjectEnabled)
+	}
+	if !hasDisabled() {
+		return true, nil
+	}
+	return reachableWhileEnabled(enforcer.Load().GetNamedRoleManager(GroupingObjectGroup), object, target), nil
+}
+
+// reachableWhileEnabled reports whether target is reachable from name without passing
+// a disabled entity, both ends included. domain is only given for domain-aware role managers.
+func reachableWhileEnabled(roleManager rbac.RoleManager, name, target string, domain ...string) bool {
+	if isDisabled(name) {
+		return false
+	}
+	if name == target {
+		return true
+	}
+	if roleManager == nil {
+		return false
+	}
+
+	seen := map[string]struct{}{name: {}}
+	queue := []string{name}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		roles, err := roleManager.GetRoles(current, domain...)
+		if err != nil {
+			return false
+		}
+		for _, role := range roles {
+			if _, ok := seen[role]; ok {
+				continue
+			}
+			seen[role] = struct{}{}
+			if isDisabled(role) {
+				continue
+			}
+			if role == target {
+				return true
+			}
+			queue = append(queue, role)
+		}
+	}
+	return false
+}
+
+// firstDisabled returns the entity type of the first disabled entity in a chain, or an empty string.
+func firstDisabled(chain []string) string {
+	for _, node := range chain {
+		if isDisabled(node) {
+			return EntityTypeOf(node)
+		}
+	}
+	return ""
+}
+
+// disabledDenyReason names the disabled entity that blocked a denied request. A disabled user or
+// object always blocks; a disabled role or ancestor object only when a policy would otherwise allow.
+// Returns an empty string when status is not the cause.
+func disabledDenyReason(subject, domain, object string, rvals []interface{}) (string, error) {
+	if !hasDisabled() || !hasPolicies() {
+		return "", nil
+	}
+	if isDisabled(subject) {
+		return DenyReasonUserDisabled, nil
+	}
+	if isDisabled(object) {
+		return DenyReasonObjectDisabled, nil
+	}
+
+	allowed, explain, err := enforcer.Load().EnforceExWithMatcher(policyMatcher, rvals...)
+	if err != nil {
+		return "", err
+	}
+	if !allowed || len(explain) < 2 {
+		return "", nil
+	}
+
+	// The request subject and object are enabled, so a disabled role or object sits in between
+	if !reachableWhileEnabled(enforcer.Load().GetRoleManager(), subject, explain[0], domain) {
+		return DenyReasonRoleDisabled, nil
+	}
+	return DenyReasonObjectDisabled, nil
+}
@@ -0,0 +1,76 @@
+package casbin
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ac/bootstrap/logger"
+	"ac/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GrantTenantToRole allows a role the given actions on every object of its tenant, writing the
+// policies within tx. The policies target the tenant root, which all objects of the tenant reach
+// via g2. Call SyncChangedRules once tx has committed.
+func GrantTenantToRole(ctx *gin.Context, tx *gorm.DB, tenantCode, roleCode string, actions []string, beginTime, endTime time.Time) (*ChangedRules, error) {
+	if enforcer.Load() == nil {
+		logger.Errorf(ctx, "casbin: grant tenant to role failed: enforcer not initialized")
+		return nil, ErrEnforcerNotInitialized
+	}
+
+	if err := validateCode(tenantCode, EntityTenant); err != nil {
+		logger.Errorf(ctx, "casbin: grant tenant to role validation failed: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+
+	if err := validateCode(roleCode, EntityRole); err != nil {
+		logger.Errorf(ctx, "casbin: grant tenant to role validation failed: role_code=%s, error=%v", roleCode, err)
+		return nil, err
+	}
+
+	if len(actions) == 0 {
+		logger.Errorf(ctx, "casbin: grant tenant to role validation failed: empty actions")
+		return nil, ErrInvalidPolicyFields
+	}
+
+	if !endTime.After(beginTime) {
+		logger.Errorf(ctx, "casbin: grant tenant to role validation failed: begin=%s, end=%s", formatTime(beginTime), formatTime(endTime))
+		return nil, ErrInvalidTimeRange
+	}
+
+	roleWithPrefix := AddPrefix(roleCode, EntityRole)
+	tenantWithPrefix := AddPrefix(tenantCode, EntityTenant)
+	logger.Infof(ctx, "casbin: granting tenant to role: role=%s, domain=%s, actions=%v", roleWithPrefix, tenantWithPrefix, actions)
+
+	release, err := ensureTenantsLoaded(ctx, tenantCode)
+	if err != nil {
+		logger.Errorf(ctx, "casbin: grant tenant to role failed to load tenant policies: tenant_code=%s, error=%v", tenantCode, err)
+		return nil, err
+	}
+	defer release()
+
+	rules := make([]model.TblCasbinRule, 0, len(actions))
+	seen := make(map[string]struct{}, len(actions))
+	for _, action := range actions {
+		action = strings.TrimSpace(action)
+		if action == "" {
+			return nil, ErrInvalidPolicyFields
+		}
+		if _, duplicate := seen[action]; duplicate {
+			continue
+		}
+		seen[action] = struct{}{}
+		rules = append(rules, model.TblCasbinRule{Ptype: "p", V0: roleWithPrefix, V1: tenantWithPrefix, V2: action, V3: FormatWindow(beginTime, endTime, ""), V5: EffectAllow})
+	}
+
+	changed := &ChangedRules{}
+	if err := changed.add(ctx, tx, rules...); err != nil {
+		logger.Errorf(ctx, "casbin: failed to grant tenant to role: role=%s, domain=%s, error=%v", roleWithPrefix, tenantWithPrefix, err)
+		return nil, fmt.Errorf("failed to grant tenant (role=%s, domain=%s): %w", roleWithPrefix, tenantWithPrefix, err)
+	}
+	logger.Infof(ctx, "casbin: tenant granted to role successfully: role=%s, domain=%s, count=%d", roleWithPrefix, tenantWithPrefix, len(rules))
+	return changed, nil
+}
@@ -0,0 +1,173 @@
+package casbin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"ac/bootstrap/database"
+	"ac/bootstrap/logger"
+	"ac/model"
+
+	"github.com/casbin/casbin/v2/persist"
+	"gorm.io/gorm"
+)
+
+// policyVersionId is the id of the single tbl_policy_version row.
+const policyVersionId = 1
+
+// watcher broadcasts policy changes to the other replicas, which reload their policies when
+// told. It is nil when replicas are not kept in sync.
+var (
+	watcher   persist.Watcher
+	watcherMu sync.RWMutex
+)
+
+// SetWatcher makes w broadcast the policy changes of this replica and reload policies when
+// another replica changes them. Any persist.Watcher works, such as one backed by pub/sub;
+// pass nil to stop syncing. A previously set watcher is closed.
+func SetWatcher(w persist.Watcher) error {
+	if w != nil {
+		if err := w.SetUpdateCallback(func(version string) {
+			ctx := context.Background()
+			logger.Infof(ctx, "casbin: policy changed on another replica, reloading: version=%s", version)
+			if err := LoadPolicy(ctx); err != nil {
+				logger.Errorf(ctx, "casbin: reload after policy change failed: version=%s, error=%v", version, err)
+			}
+		}); err != nil {
+			return fmt.Errorf("failed to set watcher callback: %w", err)
+		}
+	}
+
+	watcherMu.Lock()
+	previous := watcher
+	watcher = w
+	watcherMu.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+	return nil
+}
+
+// notifyPeers tells the other replicas that the stored policy changed. Failures are only
+// logged: the change is already stored, and peers catch up on their next reload.
+func notifyPeers(ctx context.Context) {
+	watcherMu.RLock()
+	w := watcher
+	watcherMu.RUnlock()
+	if w == nil {
+		return
+	}
+
+	if err := w.Update(); err != nil {
+		logger.Warnf(ctx, "casbin: failed to notify peers of policy change: error=%v", err)
+	}
+}
+
+// DBWatcher syncs replicas through a version counter in tbl_policy_version. Every change
+// bumps the counter, and each replica polls it to learn about changes made elsewhere.
+type DBWatcher struct {
+	interval time.Duration
+
+	mu       sync.Mutex
+	version  int64 // Latest version this replica has caught up with
+	callback func(string)
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewDBWatcher starts polling the policy version every interval.
+func NewDBWatcher(interval time.Duration) (*DBWatcher, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("watcher interval must be positive")
+	}
+
+	version, err := readPolicyVersion(database.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &DBWatcher{interval: interval, version: version, stop: make(chan struct{})}
+	go w.poll()
+	return w, nil
+}
+
+// SetUpdateCallback sets the function called with the new version when another replica changes the policy.
+func (w *DBWatcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callback = callback
+	return nil
+}
+
+// Update bumps the policy version so the other replicas reload.
+func (w *DBWatcher) Update() error {
+	var version int64
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.TblPolicyVersion{}).Where("id = ?", policyVersionId).
+			Updates(map[string]any{"version": gorm.Expr("version + ?", 1), "updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		var err error
+		version, err = readPolicyVersion(tx)
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to bump policy version: %w", err)
+	}
+
+	w.mu.Lock()
+	// Only skip the reload for our own change; when another replica changed the policy
+	// in between, the next poll still has to pick that up
+	if version == w.version+1 {
+		w.version = version
+	}
+	w.mu.Unlock()
+	return nil
+}
+
+// Close stops polling.
+func (w *DBWatcher) Close() {
+	w.closeOnce.Do(func() { close(w.stop) })
+}
+
+// poll checks the policy version every interval until closed.
+func (w *DBWatcher) poll() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			version, err := readPolicyVersion(database.DB)
+			if err != nil {
+				logger.Warnf(context.Background(), "casbin: failed to poll policy version: error=%v", err)
+				continue
+			}
+
+			w.mu.Lock()
+			changed := version != w.version
+			w.version = version
+			callback := w.callback
+			w.mu.Unlock()
+
+			if changed && callback != nil {
+				callback(strconv.FormatInt(version, 10))
+			}
+		}
+	}
+}
+
+// readPolicyVersion returns the current policy version.
+func readPolicyVersion(db *gorm.DB) (int64, error) {
+	var row model.TblPolicyVersion
+	if err := db.Where("id = ?", policyVersionId).Take(&row).Error; err != nil {
+		return 0, fmt.Errorf("failed to read policy version: %w", err)
+	}
+	return row.Version, nil
+}
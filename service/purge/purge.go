@@ -0,0 +1,164 @@
+package purge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ac/bootstrap/config"
+	"ac/bootstrap/database"
+	"ac/bootstrap/logger"
+	"ac/model"
+	"ac/service/casbin"
+
+	"gorm.io/gorm"
+)
+
+// ErrRunning is returned when a purge is requested while another one is still in progress.
+var ErrRunning = errors.New("purge already running")
+
+// Result reports how many records a purge run removed, or would remove in dry-run mode.
+type Result struct {
+	DryRun          bool
+	Users           int64
+	Roles           int64
+	Objects         int64
+	Rules           int64
+	ArchivedRules   int64
+	ExpiredPolicies int64
+}
+
+var running sync.Mutex
+
+// Run hard-deletes users, roles and objects that have stayed soft-deleted longer than the
+// configured retention, together with every rule still referencing them, then removes
+// policies whose window ended longer than the configured policy retention ago.
+func Run(ctx context.Context, dryRun bool) (*Result, error) {
+	if !running.TryLock() {
+		return nil, ErrRunning
+	}
+	defer running.Unlock()
+
+	retention, err := time.ParseDuration(config.Config().Purge.Retention)
+	if err != nil {
+		return nil, fmt.Errorf("invalid purge retention: %w", err)
+	}
+	policyRetention, err := time.ParseDuration(config.Config().Purge.PolicyRetention)
+	if err != nil {
+		return nil, fmt.Errorf("invalid purge policy retention: %w", err)
+	}
+
+	now := time.Now()
+	deletedBefore := now.Add(-retention)
+	result := &Result{DryRun: dryRun}
+
+	var subjects []model.TblSubject
+	if err := database.DB.WithContext(ctx).Select("id", "type", "code").
+		Where("deleted = ? AND updated_at < ?", model.Deleted, deletedBefore).
+		Find(&subjects).Error; err != nil {
+		logger.Errorf(ctx, "purge: run: failed to query subjects, error=%v", err)
+		return nil, fmt.Errorf("query subjects: %w", err)
+	}
+
+	var objects []model.TblObject
+	if err := database.DB.WithContext(ctx).Select("id", "code").
+		Where("deleted = ? AND updated_at < ?", model.Deleted, deletedBefore).
+		Find(&objects).Error; err != nil {
+		logger.Errorf(ctx, "purge: run: failed to query objects, error=%v", err)
+		return nil, fmt.Errorf("query objects: %w", err)
+	}
+
+	subjectIds := make([]int64, 0, len(subjects))
+	objectIds := make([]int64, 0, len(objects))
+	entities := make([]string, 0, len(subjects)+len(objects))
+	for _, subject := range subjects {
+		subjectIds = append(subjectIds, subject.Id)
+		switch subject.Type {
+		case model.SubjectTypeUser:
+			result.Users++
+			entities = append(entities, casbin.AddPrefix(subject.Code, casbin.EntityUser))
+		case model.SubjectTypeRole:
+			result.Roles++
+			entities = append(entities, casbin.AddPrefix(subject.Code, casbin.EntityRole))
+		}
+	}
+	for _, object := range objects {
+		result.Objects++
+		objectIds = append(objectIds, object.Id)
+		entities = append(entities, casbin.AddPrefix(object.Code, casbin.EntityObject))
+	}
+
+	if err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		result.Rules, result.ArchivedRules, err = casbin.PurgeEntityRules(ctx, tx, entities, dryRun)
+		if err != nil || dryRun {
+			return err
+		}
+		if len(subjectIds) > 0 {
+			if err := tx.Where("id IN ? AND deleted = ?", subjectIds, model.Deleted).Delete(&model.TblSubject{}).Error; err != nil {
+				return fmt.Errorf("delete subjects: %w", err)
+			}
+		}
+		if len(objectIds) > 0 {
+			if err := tx.Where("id IN ? AND deleted = ?", objectIds, model.Deleted).Delete(&model.TblObject{}).Error; err != nil {
+				return fmt.Errorf("delete objects: %w", err)
+			}
+		}
+		return nil
+	}); err != nil {
+		logger.Errorf(ctx, "purge: run: failed to purge entities, error=%v", err)
+		return nil, err
+	}
+
+	if !dryRun {
+		if err := casbin.SyncPurgedEntities(ctx, entities); err != nil {
+			return nil, err
+		}
+	}
+
+	result.ExpiredPolicies, err = casbin.PurgeExpiredPolicies(ctx, now.Add(-policyRetention), dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof(
+		ctx,
+		"purge: run: succeeded, dry_run=%v, users=%d, roles=%d, objects=%d, rules=%d, archived_rules=%d, expired_policies=%d",
+		dryRun, result.Users, result.Roles, result.Objects, result.Rules, result.ArchivedRules, result.ExpiredPolicies,
+	)
+	return result, nil
+}
+
+// Start runs the purge every configured interval until ctx is done.
+// It does nothing unless the purge is enabled in the configuration.
+func Start(ctx context.Context) {
+	cfg := config.Config().Purge
+	if !cfg.Enabled {
+		return
+	}
+
+	interval, err := time.ParseDuration(cfg.Interval)
+	if err != nil {
+		logger.Errorf(ctx, "purge: start: invalid interval, interval=%s, error=%v", cfg.Interval, err)
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := Run(ctx, cfg.DryRun); err != nil {
+					logger.Errorf(ctx, "purge: scheduled run failed, error=%v", err)
+				}
+			}
+		}
+	}()
+	logger.Infof(ctx, "purge: started, interval=%s, dry_run=%v", interval, cfg.DryRun)
+}
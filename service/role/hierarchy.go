@@ -0,0 +1,68 @@
+package role
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ac/model"
+
+	"github.com/onnttf/kit/dal"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrParentNotFound = errors.New("parent role not found")
+	ErrHierarchyCycle = errors.New("role hierarchy cycle detected")
+)
+
+// VerifyParent checks that parentCode is a live role of the tenant and that making it the parent
+// of roleCode would not introduce a cycle. An empty roleCode is treated as a new role.
+func VerifyParent(ctx context.Context, db *gorm.DB, tenantCode, roleCode, parentCode string) error {
+	if parentCode == "" {
+		return nil
+	}
+	if parentCode == roleCode {
+		return ErrHierarchyCycle
+	}
+
+	roleRepo := dal.NewRepo[model.TblSubject]()
+	visited := make(map[string]struct{})
+	for current := parentCode; current != ""; {
+		if _, seen := visited[current]; seen {
+			return fmt.Errorf("existing hierarchy loops at %s: %w", current, ErrHierarchyCycle)
+		}
+		visited[current] = struct{}{}
+
+		node, err := roleRepo.QueryOne(ctx, db, func(db *gorm.DB) *gorm.DB {
+			return db.Select("code", "parent_code").Where("code = ? AND tenant_code = ? AND type = ? AND deleted = ?", current, tenantCode, model.SubjectTypeRole, model.NotDeleted)
+		})
+		if err != nil {
+			return fmt.Errorf("query role %s: %w", current, err)
+		}
+		if node == nil {
+			if current == parentCode {
+				return ErrParentNotFound
+			}
+			return nil
+		}
+		if roleCode != "" && node.ParentCode == roleCode {
+			return ErrHierarchyCycle
+		}
+		current = node.ParentCode
+	}
+
+	return nil
+}
+
+// HasChildren reports whether any live role has roleCode as its parent.
+func HasChildren(ctx context.Context, db *gorm.DB, roleCode string) (bool, error) {
+	count, err := dal.NewRepo[model.TblSubject]().Count(ctx, db, func(db *gorm.DB) *gorm.DB {
+		return db.Where("parent_code = ? AND type = ? AND deleted = ?", roleCode, model.SubjectTypeRole, model.NotDeleted)
+	})
+	if err != nil {
+		return false, fmt.Errorf("count child roles: %w", err)
+	}
+	return count > 0, nil
+}
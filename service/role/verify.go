@@ -14,7 +14,7 @@ import (
 	"gorm.io/gorm"
 )
 
-func BatchVerify(ctx context.Context, codes []string) (map[string]error, error) {
+func BatchVerify(ctx context.Context, tenantCode string, codes []string) (map[string]error, error) {
 	if len(codes) == 0 {
 		return nil, errors.New("empty codes")
 	}
@@ -23,7 +23,7 @@ func BatchVerify(ctx context.Context, codes []string) (map[string]error, error)
 
 	roleRepo := dal.NewRepo[model.TblSubject]()
 	roles, err := roleRepo.Query(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Select("code", "type", "deleted").Where("code IN ?", codes)
+		return db.Select("code", "type", "tenant_code", "deleted").Where("code IN ?", codes)
 	})
 	if err != nil {
 		return nil, fmt.Errorf("query roles: %w", err)
@@ -45,6 +45,10 @@ func BatchVerify(ctx context.Context, codes []string) (map[string]error, error)
 			result[code] = errors.New("not a role")
 			continue
 		}
+		if role.TenantCode != tenantCode {
+			result[code] = errors.New("role not in tenant")
+			continue
+		}
 		if role.Deleted == model.Deleted {
 			result[code] = errors.New("role deleted")
 			continue
@@ -0,0 +1,42 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ac/bootstrap/database"
+	"ac/model"
+
+	"github.com/onnttf/kit/dal"
+
+	"gorm.io/gorm"
+)
+
+func Verify(ctx context.Context, code string) error {
+	if code == "" {
+		return errors.New("empty code")
+	}
+
+	tenantRepo := dal.NewRepo[model.TblTenant]()
+	tenant, err := tenantRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
+		return db.Select("status", "deleted").Where("code = ?", code)
+	})
+	if err != nil {
+		return fmt.Errorf("query tenant: %w", err)
+	}
+
+	if tenant == nil {
+		return errors.New("tenant not found")
+	}
+
+	if tenant.Deleted == model.Deleted {
+		return errors.New("tenant deleted")
+	}
+
+	if tenant.Status != model.StatusEnabled.Int64() {
+		return errors.New("tenant disabled")
+	}
+
+	return nil
+}
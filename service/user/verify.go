@@ -13,14 +13,14 @@ import (
 	"gorm.io/gorm"
 )
 
-func Verify(ctx context.Context, code string) error {
+func Verify(ctx context.Context, tenantCode, code string) error {
 	if code == "" {
 		return errors.New("empty code")
 	}
 
 	userRepo := dal.NewRepo[model.TblSubject]()
 	user, err := userRepo.QueryOne(ctx, database.DB, func(db *gorm.DB) *gorm.DB {
-		return db.Select("type", "deleted").Where("code = ?", code)
+		return db.Select("type", "tenant_code", "deleted").Where("code = ?", code)
 	})
 	if err != nil {
 		return fmt.Errorf("query user: %w", err)
@@ -34,6 +34,10 @@ func Verify(ctx context.Context, code string) error {
 		return errors.New("not a user")
 	}
 
+	if user.TenantCode != tenantCode {
+		return errors.New("user not in tenant")
+	}
+
 	if user.Deleted == model.Deleted {
 		return errors.New("user deleted")
 	}